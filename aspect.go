@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+)
+
+const (
+	landscapeWidth, landscapeHeight = 640, 480 // The desktop profile; daily and versus fields are always this size
+	portraitWidth, portraitHeight   = 480, 800 // The profile for phones and narrow browser windows
+)
+
+// aspectMode is how the logical screen fits a window of a different shape.
+type aspectMode int
+
+const (
+	aspectFit    aspectMode = iota // Keep the profile's size and letterbox the rest
+	aspectExtend                   // Widen the screen, and the field, to the window's shape
+)
+
+var aspectModeNames = []string{"fit", "extend"}
+
+func parseAspectMode(name string) (aspectMode, error) {
+	i := slices.Index(aspectModeNames, name)
+	if i < 0 {
+		return 0, fmt.Errorf("unknown aspect mode %q (want fit or extend)", name)
+	}
+	return aspectMode(i), nil
+}
+
+// baseWidth is the profile's screen width, before any extending.
+var baseWidth = landscapeWidth
+
+// usePortrait switches to the portrait profile. It must be called before
+// the game is created.
+func usePortrait() {
+	screenWidth, screenHeight = portraitWidth, portraitHeight
+	baseWidth = portraitWidth
+}
+
+func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
+	// A run's field is fixed, so the screen only changes shape between runs
+	if g.aspect == aspectExtend && outsideHeight > 0 && g.screen != screenPlaying {
+		screenWidth = max(baseWidth, outsideWidth*screenHeight/outsideHeight)
+	}
+	return screenWidth, screenHeight
+}
+
+// fieldWidth is how wide the next run's playfield is: the wide-field width
+// if one is set and it is wider, otherwise the screen's.
+func (g *Game) fieldWidth() float64 {
+	return max(g.worldWidth, float64(screenWidth))
+}
+
+// spawnScale keeps asteroids as dense on an extended field as on the field
+// the profile would otherwise have, by spawning them in proportion to its
+// width.
+func (g *Game) spawnScale() float64 {
+	return g.fieldWidth() / max(g.worldWidth, float64(baseWidth))
+}
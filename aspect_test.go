@@ -0,0 +1,107 @@
+package main
+
+import (
+	"math"
+	"testing"
+
+	"example/hello/core"
+)
+
+// keepScreenSize restores the screen profile when the test ends.
+func keepScreenSize(t *testing.T) {
+	w, h, base := screenWidth, screenHeight, baseWidth
+	t.Cleanup(func() { screenWidth, screenHeight, baseWidth = w, h, base })
+}
+
+func TestLayout(t *testing.T) {
+	tests := []struct {
+		name         string
+		portrait     bool
+		aspect       aspectMode
+		playing      bool
+		outW, outH   int
+		wantW, wantH int
+	}{
+		{name: "fit ignores the window", aspect: aspectFit, outW: 2560, outH: 1080, wantW: 640, wantH: 480},
+		{name: "extend to ultra-wide", aspect: aspectExtend, outW: 2560, outH: 1080, wantW: 1137, wantH: 480},
+		{name: "extend to 16:9", aspect: aspectExtend, outW: 1920, outH: 1080, wantW: 853, wantH: 480},
+		{name: "extend never narrows", aspect: aspectExtend, outW: 1000, outH: 1000, wantW: 640, wantH: 480},
+		{name: "extend holds during a run", aspect: aspectExtend, playing: true, outW: 2560, outH: 1080, wantW: 640, wantH: 480},
+		{name: "portrait", portrait: true, aspect: aspectFit, outW: 1080, outH: 1920, wantW: 480, wantH: 800},
+		{name: "portrait extended on a landscape window", portrait: true, aspect: aspectExtend, outW: 1600, outH: 1000, wantW: 1280, wantH: 800},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keepScreenSize(t)
+			if tt.portrait {
+				usePortrait()
+			}
+			g := &Game{aspect: tt.aspect, screen: screenTitle}
+			if tt.playing {
+				g.screen = screenPlaying
+			}
+			if w, h := g.Layout(tt.outW, tt.outH); w != tt.wantW || h != tt.wantH {
+				t.Errorf("Layout(%d, %d) = %dx%d, want %dx%d", tt.outW, tt.outH, w, h, tt.wantW, tt.wantH)
+			}
+		})
+	}
+}
+
+func TestSpawnScale(t *testing.T) {
+	tests := []struct {
+		name       string
+		portrait   bool
+		screen     int
+		worldWidth float64 // Wide-field width; 0 for none
+		want       float64
+	}{
+		{name: "profile size", screen: 640, want: 1},
+		{name: "extended", screen: 1280, want: 2},
+		{name: "extended by half", screen: 960, want: 1.5},
+		{name: "wide field is its own baseline", screen: 640, worldWidth: wideWorldWidth, want: 1},
+		{name: "extended past the wide field", screen: 1920, worldWidth: wideWorldWidth, want: 1.5},
+		{name: "extended less than the wide field", screen: 960, worldWidth: wideWorldWidth, want: 1},
+		{name: "portrait", portrait: true, screen: 480, want: 1},
+		{name: "portrait extended", portrait: true, screen: 960, want: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keepScreenSize(t)
+			if tt.portrait {
+				usePortrait()
+			}
+			screenWidth = tt.screen
+			g := &Game{worldWidth: tt.worldWidth}
+			if got := g.spawnScale(); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("spawn scale is %g on a %g-wide field, want %g", got, g.fieldWidth(), tt.want)
+			}
+		})
+	}
+}
+
+func TestExtendedFieldKeepsDensity(t *testing.T) {
+	// Asteroids per pixel of width, averaged over a minute of play after the
+	// start grace on many seeds, stay the same when the field and spawn rate
+	// scale together
+	density := func(scale float64) float64 {
+		total := 0
+		for seed := int64(1); seed <= 20; seed++ {
+			w := core.NewWorld(core.Config{TPS: 60, Width: 640 * scale, Height: 480, SpawnScale: scale,
+				MaxAsteroids: core.DefaultMaxAsteroids, MaxBullets: core.DefaultMaxBullets}, seed)
+			w.Invulnerable = true
+			for w.Time < w.GraceUntil+3600 {
+				w.Step(core.FrameInput{})
+				if w.Time > w.GraceUntil {
+					total += len(w.Asteroids)
+				}
+			}
+		}
+		return float64(total) / (640 * scale)
+	}
+	base := density(1)
+	for _, scale := range []float64{1.5, 2, 3} {
+		if d := density(scale); math.Abs(d/base-1) > 0.1 {
+			t.Errorf("a field %gx as wide is %.0f%% as dense", scale, 100*d/base)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/png"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+//go:embed assets
+var embeddedAssets embed.FS
+
+var errAssetNotFound = errors.New("asset not found")
+
+// assetInfo describes a file the game knows how to use.
+type assetInfo struct {
+	name          string
+	desc          string
+	width, height int // Expected size; images may be any whole multiple of it
+}
+
+// knownAssets are the files an -assets directory can replace.
+var knownAssets = []assetInfo{
+	{name: "ship.png", desc: "the player's ship, body with the cockpit on top", width: 30, height: 35},
+}
+
+func lookupAsset(name string) (assetInfo, bool) {
+	for _, a := range knownAssets {
+		if a.name == name {
+			return a, true
+		}
+	}
+	return assetInfo{}, false
+}
+
+// printAssetHelp lists the files an -assets directory can replace.
+func printAssetHelp(w io.Writer) {
+	fmt.Fprintln(w, "Files read from an -assets directory, replacing the built-in ones:")
+	for _, a := range knownAssets {
+		fmt.Fprintf(w, "  %-12s %dx%d pixels, or a whole multiple: %s\n", a.name, a.width, a.height, a.desc)
+	}
+	fmt.Fprintln(w, "Files that are missing or fail to load fall back to the built-in ones.")
+}
+
+// assetLoader finds game files by name, such as "ship.png". It tries an
+// override directory first, then the files built into the binary, then a
+// directory where players can add files the game wasn't built with.
+type assetLoader struct {
+	override string // Set by -assets; its files win over the built-in ones
+	dir      string // Extra assets; empty for none
+}
+
+// newAssetLoader returns a loader for the -assets directory, if any, and
+// the mod directory.
+func (g *Game) newAssetLoader() *assetLoader {
+	return &assetLoader{override: g.assetsDir, dir: modAssetsDir()}
+}
+
+// modAssetsDir is where the game looks for assets it wasn't built with.
+func modAssetsDir() string {
+	return filepath.Join(dataDir(), "assets")
+}
+
+// assetSource is one place an asset can come from.
+type assetSource struct {
+	name string
+	read func(name string) ([]byte, error)
+}
+
+func dirSource(dir string) assetSource {
+	return assetSource{dir, func(name string) ([]byte, error) {
+		return os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+	}}
+}
+
+// sources lists where to look for assets, in order of preference.
+func (l *assetLoader) sources() []assetSource {
+	var srcs []assetSource
+	if l.override != "" {
+		srcs = append(srcs, dirSource(l.override))
+	}
+	srcs = append(srcs, assetSource{"embedded", func(name string) ([]byte, error) {
+		return embeddedAssets.ReadFile("assets/" + name)
+	}})
+	if l.dir != "" {
+		srcs = append(srcs, dirSource(l.dir))
+	}
+	return srcs
+}
+
+// spritePaths lists every file in the loader's directories that a sprite
+// could come from, whether or not it exists yet.
+func (l *assetLoader) spritePaths() []string {
+	var paths []string
+	for _, dir := range []string{l.override, l.dir} {
+		if dir == "" {
+			continue
+		}
+		for _, name := range spriteFiles {
+			paths = append(paths, filepath.Join(dir, name))
+		}
+	}
+	return paths
+}
+
+// image loads an image asset from the first source that has a usable copy.
+// A copy that can't be read, decoded or is the wrong size is logged and
+// skipped in favor of the next source.
+func (l *assetLoader) image(name string) (*ebiten.Image, error) {
+	for _, src := range l.sources() {
+		data, err := src.read(name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		var img image.Image
+		if err == nil {
+			img, err = decodeAsset(name, data)
+		}
+		if err != nil {
+			slog.Warn("asset rejected", "name", name, "from", src.name, "err", err)
+			continue
+		}
+		slog.Info("asset loaded", "name", name, "from", src.name)
+		return ebiten.NewImageFromImage(img), nil
+	}
+	err := fmt.Errorf("asset %s: %w", name, errAssetNotFound)
+	slog.Warn("asset missing", "name", name, "err", err)
+	return nil, err
+}
+
+// decodeAsset decodes an image and checks a known asset's size.
+func decodeAsset(name string, data []byte) (image.Image, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	info, ok := lookupAsset(name)
+	if !ok {
+		return img, nil
+	}
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	if w%info.width != 0 || h%info.height != 0 || w/info.width != h/info.height || w == 0 {
+		return nil, fmt.Errorf("%dx%d is not a whole multiple of %dx%d", w, h, info.width, info.height)
+	}
+	return img, nil
+}
+
+// sprites are the images drawn in place of plain shapes. Each is optional:
+// a nil sprite couldn't be loaded, and its shape is drawn instead.
+type sprites struct {
+	ship *ebiten.Image // The ship's body with the cockpit on top
+}
+
+// spriteFiles are the files loadSprites reads.
+var spriteFiles = []string{"ship.png"}
+
+// set stores the sprite loaded from the named file.
+func (s *sprites) set(name string, img *ebiten.Image) {
+	switch name {
+	case "ship.png":
+		s.ship = img
+	}
+}
+
+// loadSprites loads every sprite, calling loaded after each one. Sprites
+// that can't be found anywhere are left nil and reported in the error.
+func loadSprites(l *assetLoader, loaded func()) (sprites, error) {
+	var s sprites
+	var errs []error
+	for _, name := range spriteFiles {
+		img, err := l.image(name)
+		s.set(name, img)
+		errs = append(errs, err)
+		loaded()
+	}
+	return s, errors.Join(errs...)
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+
+	"example/hello/core"
+)
+
+const (
+	minGameSpeed   = 0.5 // Slow
+	maxGameSpeed   = 1.5 // Fast
+	minAssistSpeed = 0.7 // Low end of the assists screen's slider, which runs up to normal speed
+	gameSpeedStep  = 0.05
+)
+
+// gameSpeedPresets are the steps of the game speed option: slow, normal
+// and fast, named by gameSpeedNames.
+var (
+	gameSpeedPresets = []float64{minGameSpeed, 1, maxGameSpeed}
+	gameSpeedNames   = []string{"options.speed_slow", "options.speed_normal", "options.speed_fast"}
+)
+
+// gameSpeed reads the game speed setting, clamped to the allowed range.
+// Zero is an unset setting and means normal speed.
+func gameSpeed(v float64) float64 {
+	if v == 0 {
+		return 1
+	}
+	return math.Max(minGameSpeed, math.Min(v, maxGameSpeed))
+}
+
+// gameSpeedName names a game speed after its preset, or as a percentage
+// for one set finer on the assists screen.
+func gameSpeedName(v float64) string {
+	for i, p := range gameSpeedPresets {
+		if math.Abs(v-p) < gameSpeedStep/2 {
+			return tr(gameSpeedNames[i])
+		}
+	}
+	return fmt.Sprintf("%.0f%%", v*100)
+}
+
+// nextGameSpeed is the preset after v in direction dir, or v at either
+// end.
+func nextGameSpeed(v float64, dir int) float64 {
+	if dir > 0 {
+		for _, p := range gameSpeedPresets {
+			if p > v+gameSpeedStep/2 {
+				return p
+			}
+		}
+		return v
+	}
+	for i := len(gameSpeedPresets) - 1; i >= 0; i-- {
+		if p := gameSpeedPresets[i]; p < v-gameSpeedStep/2 {
+			return p
+		}
+	}
+	return v
+}
+
+// setGameSpeed stores the game speed on the profile and applies it to the
+// session.
+func (g *Game) setGameSpeed(v float64) {
+	g.profile.Settings.GameSpeed = gameSpeed(v)
+	g.settings.GameSpeed = g.profile.Settings.GameSpeed
+	g.saveErr = g.profile.save()
+}
+
+// assistItems are the rows of the assists screen.
+func (g *Game) assistItems() []menuItem {
+	speed := gameSpeed(g.settings.GameSpeed)
+	toggle := func(label string, on bool, field func(*Settings) *bool) menuItem {
+		return menuItem{label: label, value: onOff(on), adjust: func(int) { g.toggleSetting(field) }}
+	}
+	return []menuItem{
+		{
+			label: tr("assists.game_speed"), bar: true, value: fmt.Sprintf("%.0f%%", speed*100),
+			fill: math.Max(0, math.Min((speed-minAssistSpeed)/(1-minAssistSpeed), 1)),
+			adjust: func(dir int) {
+				v := math.Round((speed+float64(dir)*gameSpeedStep)/gameSpeedStep) * gameSpeedStep
+				g.setGameSpeed(math.Max(minAssistSpeed, math.Min(v, 1)))
+			},
+		},
+		toggle(tr("assists.small_hitbox"), g.settings.SmallHitbox, func(s *Settings) *bool { return &s.SmallHitbox }),
+		toggle(tr("assists.auto_fire"), g.settings.AutoFire, func(s *Settings) *bool { return &s.AutoFire }),
+	}
+}
+
+func (g *Game) updateAssists() {
+	items := g.assistItems()
+	in := g.readMenuInput(items)
+	if in.cancel {
+		g.screen = screenOptions
+		return
+	}
+	g.assistsMenu.handle(in, items)
+}
+
+func (g *Game) drawAssists(screen *ebiten.Image) {
+	cx := screenWidth/2 - 150
+	drawCentered(screen, tr("assists.title"), 60)
+	y := g.assistsMenu.draw(screen, g.assistItems(), menuLayout{x: cx, y: 120, width: 320, row: 24, valueX: 130})
+	ebitenutil.DebugPrintAt(screen, tr("assists.note"), cx, y+20)
+	ebitenutil.DebugPrintAt(screen, tr("options.help"), cx, y+40)
+
+	if g.saveErr != nil {
+		g.hud().bottomLeft(screen, trf("save_failed", g.saveErr), 0)
+	}
+}
+
+// assistList names the assists a run was played with, or is empty.
+func assistList(cfg core.Config) string {
+	var parts []string
+	if s := gameSpeed(cfg.TimeScale); s < 1 {
+		parts = append(parts, trf("assists.speed_part", int(math.Round(s*100))))
+	}
+	if cfg.SmallHitbox {
+		parts = append(parts, tr("assists.small_hitbox"))
+	}
+	if cfg.AutoFire {
+		parts = append(parts, tr("assists.auto_fire"))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// boardKey names the leaderboard a run's score goes on. Each set of
+// mutators has its own boards, and assisted runs keep a board of their own
+// beside each of those.
+func boardKey(cfg core.Config) string {
+	key := modeKey(cfg.Mode) + mutatorSuffix(cfg.Mutators)
+	if cfg.Assisted() {
+		return key + "-assisted"
+	}
+	return key
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// drawCount tallies a frame's batched drawing for the debug overlay.
+type drawCount struct {
+	calls  int // DrawTriangles calls the batches made
+	shapes int // Shapes they drew, each of which used to be a call of its own
+}
+
+// frameDraws is the frame being drawn's tally, reset at the start of Draw.
+var frameDraws drawCount
+
+// shapeBatch collects flat-colored shapes into one vertex and index buffer
+// and draws them with a single DrawTriangles call over whitePixel, so a
+// pass costs one draw call however many shapes it has. Shapes draw in the
+// order they were added. Sprites with their own images are drawn apart.
+//
+// A pass calls begin, adds its shapes and calls flush. The buffers are
+// kept between passes, so drawing allocates nothing once they have grown.
+type shapeBatch struct {
+	dst       *ebiten.Image
+	antiAlias bool
+	vs        []ebiten.Vertex
+	is        []uint16
+
+	pathVs []ebiten.Vertex // Scratch for addPath
+	pathIs []uint16
+
+	draw func(vs []ebiten.Vertex, is []uint16) // Takes each flush in place of drawing onto dst, if set
+}
+
+// begin starts a batch drawn onto dst.
+func (b *shapeBatch) begin(dst *ebiten.Image, antiAlias bool) {
+	b.dst, b.antiAlias = dst, antiAlias
+	b.vs, b.is = b.vs[:0], b.is[:0]
+}
+
+// addRect adds an axis-aligned rectangle.
+func (b *shapeBatch) addRect(x, y, width, height float64, clr color.NRGBA) {
+	b.addQuad([4][2]float64{{x, y}, {x + width, y}, {x, y + height}, {x + width, y + height}}, clr)
+}
+
+// addQuad adds a quadrilateral made of the triangles (0, 1, 2) and
+// (1, 2, 3) of its corners.
+func (b *shapeBatch) addQuad(corners [4][2]float64, clr color.NRGBA) {
+	b.reserve(4)
+	n := uint16(len(b.vs))
+	for _, c := range corners {
+		b.vs = append(b.vs, ebiten.Vertex{DstX: float32(c[0]), DstY: float32(c[1])})
+	}
+	b.paint(int(n), clr)
+	b.is = append(b.is, n, n+1, n+2, n+1, n+2, n+3)
+	frameDraws.shapes++
+}
+
+// addPath adds a path, filled or, with a positive stroke width, outlined.
+func (b *shapeBatch) addPath(path *vector.Path, stroke float32, clr color.NRGBA) {
+	if stroke > 0 {
+		b.pathVs, b.pathIs = path.AppendVerticesAndIndicesForStroke(b.pathVs[:0], b.pathIs[:0], &vector.StrokeOptions{Width: stroke})
+	} else {
+		b.pathVs, b.pathIs = path.AppendVerticesAndIndicesForFilling(b.pathVs[:0], b.pathIs[:0])
+	}
+	b.reserve(len(b.pathVs))
+	n := uint16(len(b.vs))
+	b.vs = append(b.vs, b.pathVs...)
+	b.paint(int(n), clr)
+	for _, i := range b.pathIs {
+		b.is = append(b.is, n+i)
+	}
+	frameDraws.shapes++
+}
+
+// paint colors the vertices from index from on and points them at
+// whitePixel.
+func (b *shapeBatch) paint(from int, clr color.NRGBA) {
+	r, g, bl, a := float32(clr.R)/255, float32(clr.G)/255, float32(clr.B)/255, float32(clr.A)/255
+	for i := from; i < len(b.vs); i++ {
+		v := &b.vs[i]
+		v.SrcX, v.SrcY = 1, 1
+		v.ColorR, v.ColorG, v.ColorB, v.ColorA = r, g, bl, a
+	}
+}
+
+// reserve makes room for n more vertices. Indices are 16 bits, so a batch
+// about to run out draws what it has and starts again.
+func (b *shapeBatch) reserve(n int) {
+	if len(b.vs)+n > math.MaxUint16 {
+		b.flush()
+	}
+}
+
+// flush draws the shapes added so far and empties the batch.
+func (b *shapeBatch) flush() {
+	if len(b.is) > 0 {
+		if b.draw != nil {
+			b.draw(b.vs, b.is)
+		} else {
+			b.dst.DrawTriangles(b.vs, b.is, whitePixel, &ebiten.DrawTrianglesOptions{AntiAlias: b.antiAlias})
+		}
+		frameDraws.calls++
+	}
+	b.vs, b.is = b.vs[:0], b.is[:0]
+}
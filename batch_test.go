@@ -0,0 +1,107 @@
+package main
+
+import (
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// flushRecord is what one flush of a batch would have drawn.
+type flushRecord struct {
+	vertices int
+	maxIndex int
+}
+
+// recordFlushes swaps the batch's drawing for a note of each flush.
+func recordFlushes(b *shapeBatch) *[]flushRecord {
+	var flushes []flushRecord
+	b.draw = func(vs []ebiten.Vertex, is []uint16) {
+		top := 0
+		for _, i := range is {
+			top = max(top, int(i))
+		}
+		flushes = append(flushes, flushRecord{len(vs), top})
+	}
+	return &flushes
+}
+
+func TestShapeBatchRollsOverBefore16BitIndices(t *testing.T) {
+	const rects = 20000 // 80,000 vertices, past what 16-bit indices reach
+	var b shapeBatch
+	flushes := recordFlushes(&b)
+	b.begin(nil, false)
+	for i := 0; i < rects; i++ {
+		b.addRect(float64(i%640), float64(i/640), 1, 1, color.NRGBA{255, 255, 255, 255})
+	}
+	b.flush()
+
+	if len(*flushes) != 2 {
+		t.Fatalf("%d rectangles took %d draw calls, want 2", rects, len(*flushes))
+	}
+	total := 0
+	for i, f := range *flushes {
+		if f.vertices > math.MaxUint16 || f.maxIndex >= f.vertices {
+			t.Errorf("flush %d has %d vertices and indices up to %d", i, f.vertices, f.maxIndex)
+		}
+		if f.vertices%4 != 0 {
+			t.Errorf("flush %d split a rectangle, with %d vertices", i, f.vertices)
+		}
+		total += f.vertices
+	}
+	if total != 4*rects {
+		t.Errorf("the flushes drew %d vertices, want %d", total, 4*rects)
+	}
+}
+
+func TestShapeBatchRollsOverPaths(t *testing.T) {
+	var b shapeBatch
+	flushes := recordFlushes(&b)
+	b.begin(nil, true)
+	var path vector.Path
+	path.MoveTo(0, 0)
+	for i := 1; i < 200; i++ {
+		path.LineTo(float32(i), float32(i%7))
+	}
+	for i := 0; i < 500; i++ {
+		b.addPath(&path, 2, color.NRGBA{255, 0, 0, 255})
+	}
+	b.flush()
+
+	if len(*flushes) < 2 {
+		t.Fatalf("500 strokes took %d draw calls; the test needs them to overflow one", len(*flushes))
+	}
+	for i, f := range *flushes {
+		if f.vertices > math.MaxUint16 || f.maxIndex >= f.vertices {
+			t.Errorf("flush %d has %d vertices and indices up to %d", i, f.vertices, f.maxIndex)
+		}
+	}
+}
+
+func TestEmptyShapeBatchDrawsNothing(t *testing.T) {
+	var b shapeBatch
+	flushes := recordFlushes(&b)
+	before := frameDraws.calls
+	b.begin(nil, false)
+	b.flush()
+	b.flush()
+	if len(*flushes) != 0 || frameDraws.calls != before {
+		t.Errorf("flushing an empty batch made %d draw calls", len(*flushes))
+	}
+}
+
+func BenchmarkShapeBatch(b *testing.B) {
+	var batch shapeBatch
+	batch.draw = func([]ebiten.Vertex, []uint16) {}
+	clr := color.NRGBA{255, 255, 255, 255}
+	b.ReportAllocs()
+	for range b.N {
+		batch.begin(nil, false)
+		for i := 0; i < 1000; i++ {
+			batch.addRect(float64(i), 0, 4, 10, clr)
+		}
+		batch.flush()
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// padAction is something a gamepad input can be bound to.
+type padAction int
+
+const (
+	padLeft padAction = iota
+	padRight
+	padUp
+	padDown
+	padFire
+	padPause
+	padContinue
+	padRestart
+	padMenu
+	padActionCount
+)
+
+// padActionNames key the bindings in saved layouts.
+var padActionNames = [padActionCount]string{"left", "right", "up", "down", "fire", "pause", "continue", "restart", "menu"}
+
+const padPressThreshold = 0.5 // Default axis or trigger travel that counts as a press
+
+// padBinding is one standard-layout input: a button, or one direction of an
+// axis.
+type padBinding struct {
+	Button ebiten.StandardGamepadButton `json:"button"`
+	Axis   ebiten.StandardGamepadAxis   `json:"axis"`
+	Dir    int                          `json:"dir,omitempty"` // -1 or 1 for an axis; 0 for a button
+}
+
+// padLayout is the bindings for one gamepad.
+type padLayout struct {
+	Name      string                `json:"name"` // Device name when saved, to tell entries apart
+	Bindings  map[string]padBinding `json:"bindings"`
+	Deadzone  float64               `json:"deadzone"`  // Axis travel treated as centered
+	Threshold float64               `json:"threshold"` // Axis or trigger travel that counts as pressed
+}
+
+func padButton(b ebiten.StandardGamepadButton) padBinding {
+	return padBinding{Button: b}
+}
+
+func padAxis(a ebiten.StandardGamepadAxis, dir int) padBinding {
+	return padBinding{Axis: a, Dir: dir}
+}
+
+// defaultPadLayout is the standard-layout mapping every gamepad starts with.
+func defaultPadLayout() padLayout {
+	return padLayout{
+		Bindings: map[string]padBinding{
+			"left":     padAxis(ebiten.StandardGamepadAxisLeftStickHorizontal, -1),
+			"right":    padAxis(ebiten.StandardGamepadAxisLeftStickHorizontal, 1),
+			"up":       padAxis(ebiten.StandardGamepadAxisLeftStickVertical, -1),
+			"down":     padAxis(ebiten.StandardGamepadAxisLeftStickVertical, 1),
+			"fire":     padButton(ebiten.StandardGamepadButtonRightBottom),
+			"pause":    padButton(ebiten.StandardGamepadButtonCenterRight),
+			"continue": padButton(ebiten.StandardGamepadButtonCenterRight),
+			"restart":  padButton(ebiten.StandardGamepadButtonCenterRight),
+			"menu":     padButton(ebiten.StandardGamepadButtonCenterLeft),
+		},
+		Deadzone:  stickDeadzone,
+		Threshold: padPressThreshold,
+	}
+}
+
+// padLayout returns the saved layout for a gamepad, or the defaults for one
+// that has none. Anything missing from a saved layout is taken from the
+// defaults.
+func (s *Settings) padLayout(id ebiten.GamepadID) padLayout {
+	def := defaultPadLayout()
+	l, ok := s.PadLayouts[ebiten.GamepadSDLID(id)]
+	if !ok {
+		return def
+	}
+	bindings := make(map[string]padBinding, padActionCount)
+	for _, name := range padActionNames {
+		b, ok := l.Bindings[name]
+		if !ok {
+			b = def.Bindings[name]
+		}
+		bindings[name] = b
+	}
+	l.Bindings = bindings
+	if l.Deadzone <= 0 || l.Deadzone >= 1 {
+		l.Deadzone = def.Deadzone
+	}
+	if l.Threshold <= 0 || l.Threshold > 1 {
+		l.Threshold = def.Threshold
+	}
+	return l
+}
+
+func (l padLayout) binding(a padAction) padBinding {
+	return l.Bindings[padActionNames[a]]
+}
+
+// raw is how far a binding is pushed, from 0 to 1, before any deadzone.
+func (b padBinding) raw(id ebiten.GamepadID) float64 {
+	if b.Dir == 0 {
+		return ebiten.StandardGamepadButtonValue(id, b.Button)
+	}
+	return math.Max(0, ebiten.StandardGamepadAxisValue(id, b.Axis)*float64(b.Dir))
+}
+
+// value is how far an action's binding is pushed past the deadzone,
+// rescaled to run from 0 to 1.
+func (l padLayout) value(id ebiten.GamepadID, a padAction) float64 {
+	v := l.binding(a).raw(id)
+	if v < l.Deadzone {
+		return 0
+	}
+	return math.Min(1, (v-l.Deadzone)/(1-l.Deadzone))
+}
+
+// padState is the bound gamepad actions as of this tick, across every
+// connected standard-layout gamepad.
+type padState struct {
+	held, prev   [padActionCount]bool
+	moveX, moveY float64
+	used         bool // Any button went down or any stick moved this tick
+}
+
+// update reads every gamepad through its layout. Movement comes from the
+// first pad that is being steered.
+func (p *padState) update(s *Settings) {
+	p.prev = p.held
+	p.held = [padActionCount]bool{}
+	p.moveX, p.moveY = 0, 0
+	p.used = false
+	for _, id := range ebiten.AppendGamepadIDs(nil) {
+		if !ebiten.IsStandardGamepadLayoutAvailable(id) {
+			continue
+		}
+		if len(inpututil.AppendJustPressedStandardGamepadButtons(id, nil)) > 0 {
+			p.used = true
+		}
+		l := s.padLayout(id)
+		for a := padAction(0); a < padActionCount; a++ {
+			if l.binding(a).raw(id) >= l.Threshold {
+				p.held[a] = true
+			}
+		}
+		if p.moveX == 0 && p.moveY == 0 {
+			p.moveX = l.value(id, padRight) - l.value(id, padLeft)
+			p.moveY = l.value(id, padDown) - l.value(id, padUp)
+		}
+		if p.moveX != 0 || p.moveY != 0 {
+			p.used = true
+		}
+	}
+}
+
+// justPressed reports whether an action's input went down this tick.
+func (p *padState) justPressed(a padAction) bool {
+	return p.held[a] && !p.prev[a]
+}
@@ -0,0 +1,337 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"example/hello/core"
+)
+
+const (
+	snapshotVersion       = 1               // Bump whenever the snapshot format changes
+	broadcastQueue        = 2               // Snapshots buffered per spectator before frames are dropped
+	broadcastWriteTimeout = 5 * time.Second // A spectator that can't take a frame this long is dropped
+)
+
+var errSnapshotVersion = errors.New("snapshot from an unsupported version")
+
+// snapshot is what a spectator needs to draw one tick of a run. Each one
+// is complete on its own, so a spectator can join at any point.
+type snapshot struct {
+	Version int `json:"v"`
+
+	Width   float32 `json:"w"`
+	Height  float32 `json:"h"`
+	Wrap    bool    `json:"wrap,omitempty"`
+	CameraX float32 `json:"cam,omitempty"`
+
+	Time      int  `json:"t"`
+	Score     int  `json:"score"`
+	Destroyed int  `json:"destroyed"`
+	Dodged    int  `json:"dodged"`
+	Weapon    int  `json:"weapon"`
+	Shielded  bool `json:"shield,omitempty"`
+	GameOver  bool `json:"over,omitempty"`
+
+	Player    [4]float32         `json:"p"` // x, y, width, height
+	Bullets   [][2]float32       `json:"b"` // x, y
+	Asteroids []snapshotAsteroid `json:"a"`
+}
+
+type snapshotAsteroid struct {
+	X     float32   `json:"x"`
+	Y     float32   `json:"y"`
+	Size  float32   `json:"s"`
+	Shape []float32 `json:"o"` // Outline offsets as x, y pairs
+}
+
+func newSnapshot(w *core.World, cameraX float64) snapshot {
+	s := snapshot{
+		Version:   snapshotVersion,
+		Width:     float32(w.Config.Width),
+		Height:    float32(w.Config.Height),
+		Wrap:      w.Config.Wrap,
+		CameraX:   float32(cameraX),
+		Time:      w.Time,
+		Score:     w.Score,
+		Destroyed: w.Destroyed,
+		Dodged:    w.Dodged,
+		Weapon:    w.WeaponLevel,
+		Shielded:  w.Shielded(),
+		GameOver:  w.GameOver,
+		Player:    [4]float32{float32(w.Player.X), float32(w.Player.Y), float32(w.Player.Width), float32(w.Player.Height)},
+		Bullets:   make([][2]float32, 0, len(w.Bullets)),
+		Asteroids: make([]snapshotAsteroid, 0, len(w.Asteroids)),
+	}
+	for _, b := range w.Bullets {
+		if b.Active {
+			s.Bullets = append(s.Bullets, [2]float32{float32(b.X), float32(b.Y)})
+		}
+	}
+	for _, a := range w.Asteroids {
+		if !a.Active {
+			continue
+		}
+		shape := make([]float32, 0, 2*len(a.Shape))
+		for _, p := range a.Shape {
+			shape = append(shape, float32(p.X), float32(p.Y))
+		}
+		s.Asteroids = append(s.Asteroids, snapshotAsteroid{X: float32(a.X), Y: float32(a.Y), Size: float32(a.Width), Shape: shape})
+	}
+	return s
+}
+
+// world rebuilds enough of a World from the snapshot to draw it. Previous
+// positions equal current ones; spectators see each tick as it arrives.
+func (s *snapshot) world() *core.World {
+	w := &core.World{
+		Config: core.Config{
+			TPS:    ebiten.TPS(),
+			Width:  float64(s.Width),
+			Height: float64(s.Height),
+			Wrap:   s.Wrap,
+		},
+		Time:        s.Time,
+		Score:       s.Score,
+		Destroyed:   s.Destroyed,
+		Dodged:      s.Dodged,
+		WeaponLevel: s.Weapon,
+		GameOver:    s.GameOver,
+	}
+	if s.Shielded {
+		w.ShieldUntil = s.Time + 1
+	}
+	p := s.Player
+	w.Player = core.Player{X: float64(p[0]), Y: float64(p[1]), Width: float64(p[2]), Height: float64(p[3])}
+	w.Player.PrevX, w.Player.PrevY = w.Player.X, w.Player.Y
+	for _, b := range s.Bullets {
+		x, y := float64(b[0]), float64(b[1])
+		w.Bullets = append(w.Bullets, core.Bullet{X: x, Y: y, PrevX: x, PrevY: y, Active: true})
+	}
+	for _, a := range s.Asteroids {
+		x, y, size := float64(a.X), float64(a.Y), float64(a.Size)
+		shape := make([]core.Point, len(a.Shape)/2)
+		for i := range shape {
+			shape[i] = core.Point{X: float64(a.Shape[2*i]), Y: float64(a.Shape[2*i+1])}
+		}
+		w.Asteroids = append(w.Asteroids, core.Asteroid{
+			X: x, Y: y, PrevX: x, PrevY: y,
+			Width: size, Height: size,
+			Active: true,
+			Shape:  shape,
+		})
+	}
+	return w
+}
+
+// broadcaster sends a snapshot of every tick to whoever connects. A
+// spectator that falls behind misses frames instead of slowing the game.
+type broadcaster struct {
+	mu      sync.Mutex
+	clients map[*broadcastClient]bool
+}
+
+type broadcastClient struct {
+	conn   net.Conn
+	frames chan []byte
+}
+
+// startBroadcast listens for spectators on addr. A failure to bind is
+// logged and otherwise ignored.
+func startBroadcast(addr string) *broadcaster {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		slog.Error("broadcast: can't listen", "addr", addr, "err", err)
+		return nil
+	}
+	slog.Info("broadcast: serving snapshots", "addr", ln.Addr())
+	b := &broadcaster{clients: make(map[*broadcastClient]bool)}
+	go b.accept(ln)
+	return b
+}
+
+func (b *broadcaster) accept(ln net.Listener) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			slog.Error("broadcast: accept failed", "err", err)
+			return
+		}
+		c := &broadcastClient{conn: conn, frames: make(chan []byte, broadcastQueue)}
+		b.mu.Lock()
+		b.clients[c] = true
+		b.mu.Unlock()
+		slog.Info("broadcast: spectator connected", "addr", conn.RemoteAddr())
+		go b.serve(c)
+	}
+}
+
+func (b *broadcaster) serve(c *broadcastClient) {
+	defer func() {
+		b.mu.Lock()
+		delete(b.clients, c)
+		b.mu.Unlock()
+		c.conn.Close()
+	}()
+	for frame := range c.frames {
+		c.conn.SetWriteDeadline(time.Now().Add(broadcastWriteTimeout))
+		if _, err := c.conn.Write(frame); err != nil {
+			slog.Info("broadcast: spectator dropped", "addr", c.conn.RemoteAddr(), "err", err)
+			return
+		}
+	}
+}
+
+// send queues a snapshot for every spectator that has room for it.
+func (b *broadcaster) send(s snapshot) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.clients) == 0 {
+		return
+	}
+	frame, err := json.Marshal(s)
+	if err != nil {
+		panic(err) // snapshot holds only plain data
+	}
+	frame = append(frame, '\n')
+	for c := range b.clients {
+		select {
+		case c.frames <- frame:
+		default:
+			// Too slow to keep up; it gets a later frame instead
+		}
+	}
+}
+
+// spectatorLink receives snapshots from a broadcasting game.
+type spectatorLink struct {
+	addr      string
+	latest    chan snapshot // Holds only the newest snapshot
+	done      chan error    // Receives why the link ended
+	connected bool          // A snapshot has arrived
+	ended     string        // Why the link ended, once it has
+
+	mu     sync.Mutex
+	conn   net.Conn
+	closed bool
+}
+
+// startSpectate connects to the broadcast at addr and shows it read-only.
+func (g *Game) startSpectate(addr string) {
+	l := &spectatorLink{
+		addr:   addr,
+		latest: make(chan snapshot, 1),
+		done:   make(chan error, 1),
+	}
+	go l.run()
+	g.spectator = l
+	g.screen = screenSpectate
+}
+
+func (l *spectatorLink) run() {
+	conn, err := net.DialTimeout("tcp", l.addr, lanDialTimeout)
+	if err != nil {
+		l.done <- err
+		return
+	}
+	l.mu.Lock()
+	l.conn = conn
+	closed := l.closed
+	l.mu.Unlock()
+	if closed {
+		conn.Close()
+		return
+	}
+	dec := json.NewDecoder(bufio.NewReader(conn))
+	for {
+		var s snapshot
+		if err := dec.Decode(&s); err != nil {
+			l.done <- err
+			return
+		}
+		if s.Version != snapshotVersion {
+			l.done <- errSnapshotVersion
+			return
+		}
+		// Replace whatever the game loop hasn't picked up yet
+		select {
+		case <-l.latest:
+		default:
+		}
+		l.latest <- s
+	}
+}
+
+// close hangs up, whether or not the connection was made yet.
+func (l *spectatorLink) close() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.closed = true
+	if l.conn != nil {
+		l.conn.Close()
+	}
+}
+
+func (g *Game) leaveSpectate() {
+	g.spectator.close()
+	g.spectator = nil
+	g.reset()
+	if g.profile != nil {
+		g.screen = screenTitle
+	} else {
+		g.openProfiles()
+	}
+}
+
+func (g *Game) updateSpectate() {
+	l := g.spectator
+	if g.input.justPressed(inputMenu) {
+		g.leaveSpectate()
+		return
+	}
+	select {
+	case s := <-l.latest:
+		l.connected = true
+		g.world = s.world()
+		g.camera.x, g.camera.prevX = float64(s.CameraX), float64(s.CameraX)
+	case err := <-l.done:
+		switch {
+		case err == errSnapshotVersion:
+			l.ended = tr("spectate.mismatch")
+		case l.connected:
+			l.ended = tr("spectate.ended")
+		default:
+			l.ended = trf("spectate.failed", err)
+		}
+	default:
+	}
+}
+
+func (g *Game) drawSpectate(screen *ebiten.Image) {
+	l := g.spectator
+	if l.connected {
+		g.drawWorld(screen, g.world, -g.snap(g.camera.x), 1)
+		hud := g.hud()
+		hud.topLeft(screen, trf("hud.score", g.world.Score), 0)
+		hud.topLeft(screen, trf("hud.weapon", g.world.WeaponLevel+1), 1)
+		hud.topRight(screen, tr("spectate.badge"), 0)
+		if g.world.GameOver {
+			drawCentered(screen, tr("gameover.title"), screenHeight/2)
+			drawCentered(screen, trf("gameover.stats", g.world.Destroyed, g.world.Dodged), screenHeight/2-20)
+		}
+	}
+	switch {
+	case l.ended != "":
+		drawCentered(screen, l.ended, screenHeight/2+40)
+	case !l.connected:
+		drawCentered(screen, trf("spectate.connecting", l.addr), screenHeight/2)
+	}
+	drawCentered(screen, tr("versus.leave"), screenHeight-30)
+}
@@ -0,0 +1,340 @@
+//go:build dev
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+
+	"example/hello/core"
+)
+
+const (
+	consoleScrollback = 100 // Output lines kept
+	consoleVisible    = 14  // Output lines shown
+	consoleLineHeight = 16
+)
+
+// console is the developer command line, toggled with the backquote key.
+// The game is paused while it is open.
+type console struct {
+	open   bool
+	input  string
+	output []string
+}
+
+// consoleCommand is something the console can run. Commands that change
+// the game mark the run as cheated.
+type consoleCommand struct {
+	usage string
+	cheat bool
+	run   func(g *Game, args []string) (string, error)
+}
+
+var consoleCommands = map[string]consoleCommand{}
+
+// registerCommand makes a command available in the console.
+func registerCommand(name, usage string, cheat bool, run func(g *Game, args []string) (string, error)) {
+	consoleCommands[name] = consoleCommand{usage: usage, cheat: cheat, run: run}
+}
+
+func init() {
+	registerCommand("help", "help", false, func(g *Game, args []string) (string, error) {
+		var usages []string
+		for _, c := range consoleCommands {
+			usages = append(usages, c.usage)
+		}
+		sort.Strings(usages)
+		return strings.Join(usages, "\n"), nil
+	})
+	registerCommand("spawn", "spawn asteroid X Y SIZE | spawn wall|v|cluster", true, func(g *Game, args []string) (string, error) {
+		formations := map[string]core.Formation{"wall": core.FormationWall, "v": core.FormationV, "cluster": core.FormationCluster}
+		if f, ok := formations[args0(args)]; ok && len(args) == 1 {
+			g.world.SpawnFormation(f)
+			return "spawned " + args[0], nil
+		}
+		if len(args) != 4 || args[0] != "asteroid" {
+			return "", errUsage
+		}
+		n, err := parseFloats(args[1:])
+		if err != nil {
+			return "", err
+		}
+		if n[2] <= 0 {
+			return "", errors.New("size must be positive")
+		}
+		g.world.AddAsteroid(n[0], n[2], g.world.Config.Tuning.AsteroidSpeed)
+		a := &g.world.Asteroids[len(g.world.Asteroids)-1]
+		a.Y, a.PrevY = n[1], n[1]
+		return "spawned", nil
+	})
+	registerCommand("give", "give powerup shield", true, func(g *Game, args []string) (string, error) {
+		if len(args) != 2 || args[0] != "powerup" {
+			return "", errUsage
+		}
+		if args[1] != "shield" {
+			return "", fmt.Errorf("unknown powerup %q", args[1])
+		}
+		g.world.ShieldUntil = g.world.Time + g.world.Ticks(g.world.Config.Tuning.ReviveShield)
+		return "shield up", nil
+	})
+	registerCommand("set", "set NAME VALUE (player_speed, bullet_speed, asteroid_speed, spawn_interval, dodge_score, destroy_score)", true, func(g *Game, args []string) (string, error) {
+		if len(args) != 2 {
+			return "", errUsage
+		}
+		v, err := strconv.ParseFloat(args[1], 64)
+		if err != nil || v <= 0 {
+			return "", fmt.Errorf("%q is not a positive number", args[1])
+		}
+		t := &g.world.Config.Tuning
+		switch args[0] {
+		case "player_speed":
+			t.PlayerSpeed = v
+		case "bullet_speed":
+			t.BulletSpeed = v
+		case "asteroid_speed":
+			t.AsteroidSpeed = v
+		case "spawn_interval":
+			t.SpawnInterval = v
+		case "dodge_score":
+			t.DodgeScore = int(v)
+		case "destroy_score":
+			t.DestroyScore = int(v)
+		default:
+			return "", fmt.Errorf("unknown value %q", args[0])
+		}
+		return fmt.Sprintf("%s = %g for this run", args[0], v), nil
+	})
+	registerCommand("god", "god on|off", true, func(g *Game, args []string) (string, error) {
+		on, err := parseOnOff(args)
+		if err != nil {
+			return "", err
+		}
+		g.world.Invulnerable = on
+		return "god " + args[0], nil
+	})
+	registerCommand("wave", "wave N", true, func(g *Game, args []string) (string, error) {
+		if g.world.Config.Mode != core.ModeStage {
+			return "", errors.New("waves only exist in stage mode")
+		}
+		if len(args) != 1 {
+			return "", errUsage
+		}
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 1 || n > core.StageWaves {
+			return "", fmt.Errorf("wave must be from 1 to %d", core.StageWaves)
+		}
+		g.world.Wave, g.world.WaveSpawned = n-1, 0
+		g.world.NextSpawn = g.world.Time
+		return fmt.Sprintf("jumped to wave %d", n), nil
+	})
+	registerCommand("seed", "seed N", true, func(g *Game, args []string) (string, error) {
+		if len(args) != 1 {
+			return "", errUsage
+		}
+		seed, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			return "", fmt.Errorf("%q is not a whole number", args[0])
+		}
+		g.world = core.NewWorld(g.world.Config, seed)
+		g.resetRun()
+		return fmt.Sprintf("restarted with seed %d", seed), nil
+	})
+}
+
+var errUsage = errors.New("wrong arguments")
+
+// args0 is the first argument, or empty if there are none.
+func args0(args []string) string {
+	if len(args) == 0 {
+		return ""
+	}
+	return args[0]
+}
+
+func parseFloats(args []string) ([]float64, error) {
+	n := make([]float64, len(args))
+	for i, a := range args {
+		v, err := strconv.ParseFloat(a, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", a)
+		}
+		n[i] = v
+	}
+	return n, nil
+}
+
+func parseOnOff(args []string) (bool, error) {
+	if len(args) == 1 {
+		switch args[0] {
+		case "on":
+			return true, nil
+		case "off":
+			return false, nil
+		}
+	}
+	return false, errUsage
+}
+
+// splitArgs splits a command line into words. Double quotes group words
+// and a backslash escapes the next character.
+func splitArgs(line string) ([]string, error) {
+	var args []string
+	var word strings.Builder
+	inWord, quoted, escaped := false, false, false
+	for _, r := range line {
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped, inWord = true, true
+		case r == '"':
+			quoted, inWord = !quoted, true
+		case r == ' ' && !quoted:
+			if inWord {
+				args = append(args, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quoted {
+		return nil, errors.New("unterminated quote")
+	}
+	if escaped {
+		return nil, errors.New("trailing backslash")
+	}
+	if inWord {
+		args = append(args, word.String())
+	}
+	return args, nil
+}
+
+func (c *console) print(text string) {
+	c.output = append(c.output, strings.Split(text, "\n")...)
+	if len(c.output) > consoleScrollback {
+		c.output = c.output[len(c.output)-consoleScrollback:]
+	}
+}
+
+// exec runs one command line.
+func (g *Game) exec(line string) {
+	c := &g.console
+	c.print("> " + line)
+	args, err := splitArgs(line)
+	if err != nil {
+		c.print("error: " + err.Error())
+		return
+	}
+	if len(args) == 0 {
+		return
+	}
+	cmd, ok := consoleCommands[args[0]]
+	if !ok {
+		c.print(fmt.Sprintf("unknown command %q; try help", args[0]))
+		return
+	}
+	out, err := cmd.run(g, args[1:])
+	switch {
+	case errors.Is(err, errUsage):
+		c.print("usage: " + cmd.usage)
+		return
+	case err != nil:
+		c.print("error: " + err.Error())
+		return
+	}
+	if cmd.cheat {
+		g.cheated = true
+		g.trace = nil // A cheated run can't be exported, so stop recording it
+	}
+	if out != "" {
+		c.print(out)
+	}
+}
+
+// complete extends the command name being typed as far as it is
+// unambiguous, listing the candidates when there are several.
+func (c *console) complete() {
+	if strings.Contains(c.input, " ") {
+		return
+	}
+	var matches []string
+	for name := range consoleCommands {
+		if strings.HasPrefix(name, c.input) {
+			matches = append(matches, name)
+		}
+	}
+	sort.Strings(matches)
+	switch len(matches) {
+	case 0:
+		return
+	case 1:
+		c.input = matches[0] + " "
+		return
+	}
+	prefix := matches[0]
+	for _, m := range matches[1:] {
+		for !strings.HasPrefix(m, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	c.input = prefix
+	c.print(strings.Join(matches, "  "))
+}
+
+// updateConsole handles the console's keys. It reports whether the console
+// has focus, in which case the rest of Update must not run.
+func (g *Game) updateConsole() bool {
+	c := &g.console
+	if g.input.keyPressed(ebiten.KeyBackquote) {
+		c.open = !c.open
+		return true
+	}
+	if !c.open {
+		return false
+	}
+	for _, r := range g.input.chars {
+		if r != '`' && r != '\t' {
+			c.input += string(r)
+		}
+	}
+	switch {
+	case g.input.keyPressed(ebiten.KeyBackspace) && len(c.input) > 0:
+		_, size := utf8.DecodeLastRuneInString(c.input)
+		c.input = c.input[:len(c.input)-size]
+	case g.input.keyPressed(ebiten.KeyTab):
+		c.complete()
+	case g.input.keyPressed(ebiten.KeyEnter):
+		g.exec(c.input)
+		c.input = ""
+	case g.input.keyPressed(ebiten.KeyEscape):
+		c.open = false
+	}
+	return true
+}
+
+func (g *Game) drawConsole(screen *ebiten.Image) {
+	c := &g.console
+	if !c.open {
+		return
+	}
+	height := float64((consoleVisible + 2) * consoleLineHeight)
+	ebitenutil.DrawRect(screen, 0, 0, float64(screenWidth), height, color.RGBA{0, 0, 0, 200})
+	lines := c.output[max(0, len(c.output)-consoleVisible):]
+	for i, line := range lines {
+		ebitenutil.DebugPrintAt(screen, line, 6, 4+i*consoleLineHeight)
+	}
+	ebitenutil.DebugPrintAt(screen, "> "+c.input+"_", 6, 4+consoleVisible*consoleLineHeight)
+}
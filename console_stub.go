@@ -0,0 +1,12 @@
+//go:build !dev
+
+package main
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+// console does nothing unless the game is built with -tags dev.
+type console struct{}
+
+func (g *Game) updateConsole() bool { return false }
+
+func (g *Game) drawConsole(screen *ebiten.Image) {}
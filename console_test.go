@@ -0,0 +1,120 @@
+//go:build dev
+
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		line    string
+		want    []string
+		wantErr string
+	}{
+		{line: "", want: nil},
+		{line: "   ", want: nil},
+		{line: "god on", want: []string{"god", "on"}},
+		{line: "  spawn   asteroid 50 100 40 ", want: []string{"spawn", "asteroid", "50", "100", "40"}},
+		{line: `say "hello there"`, want: []string{"say", "hello there"}},
+		{line: `say hel"lo th"ere`, want: []string{"say", "hello there"}},
+		{line: `say "" x`, want: []string{"say", "", "x"}},
+		{line: `say \"quoted\"`, want: []string{"say", `"quoted"`}},
+		{line: `say a\ b`, want: []string{"say", "a b"}},
+		{line: `say "a \" b"`, want: []string{"say", `a " b`}},
+		{line: `say \\`, want: []string{"say", `\`}},
+		{line: `say "open`, wantErr: "unterminated quote"},
+		{line: `say end\`, wantErr: "trailing backslash"},
+	}
+	for _, tt := range tests {
+		got, err := splitArgs(tt.line)
+		if tt.wantErr != "" {
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("splitArgs(%q) returned error %v, want %q", tt.line, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("splitArgs(%q): %v", tt.line, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitArgs(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestExecBadArguments(t *testing.T) {
+	tests := []struct {
+		line string
+		want string // The last line printed
+	}{
+		{"nonsense", `unknown command "nonsense"; try help`},
+		{`god "on`, "error: unterminated quote"},
+		{"god", "usage: god on|off"},
+		{"god maybe", "usage: god on|off"},
+		{"spawn asteroid 1 2", "usage: " + consoleCommands["spawn"].usage},
+		{"spawn asteroid 1 two 3", `error: "two" is not a number`},
+		{"spawn asteroid 1 2 0", "error: size must be positive"},
+		{"give powerup laser", `error: unknown powerup "laser"`},
+		{"set spawn_interval -1", `error: "-1" is not a positive number`},
+		{"set gravity 2", `error: unknown value "gravity"`},
+		{"wave 3", "error: waves only exist in stage mode"},
+		{"seed 1.5", `error: "1.5" is not a whole number`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			g, _ := newTestGame(t)
+			hash := g.world.Hash()
+			g.exec(tt.line)
+			out := g.console.output
+			if got := out[len(out)-1]; got != tt.want {
+				t.Errorf("printed %q, want %q", got, tt.want)
+			}
+			if g.cheated || g.world.Hash() != hash {
+				t.Error("a failed command changed the run")
+			}
+		})
+	}
+}
+
+func TestExecMarksCheats(t *testing.T) {
+	g, _ := newTestGame(t)
+	g.exec("help")
+	if g.cheated {
+		t.Fatal("help marked the run as cheated")
+	}
+	g.exec(`set  "spawn_interval"  0.25`)
+	if !g.cheated || g.trace != nil {
+		t.Error("set didn't mark the run as cheated and stop recording it")
+	}
+	if got := g.world.Config.Tuning.SpawnInterval; got != 0.25 {
+		t.Errorf("spawn interval is %g, want 0.25", got)
+	}
+}
+
+func TestCompleteCommand(t *testing.T) {
+	tests := []struct{ input, want string }{
+		{"go", "god "},
+		{"s", "s"}, // seed, set and spawn
+		{"se", "se"},
+		{"sp", "spawn "},
+		{"xyz", "xyz"},
+		{"god o", "god o"}, // Only names complete
+	}
+	for _, tt := range tests {
+		c := console{input: tt.input}
+		c.complete()
+		if c.input != tt.want {
+			t.Errorf("completing %q gave %q, want %q", tt.input, c.input, tt.want)
+		}
+	}
+
+	c := console{input: "se"}
+	c.complete()
+	if len(c.output) != 1 || !strings.Contains(c.output[0], "seed") || !strings.Contains(c.output[0], "set") {
+		t.Errorf("ambiguous completion listed %q, want seed and set", c.output)
+	}
+}
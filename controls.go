@@ -0,0 +1,237 @@
+package main
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+const (
+	maxPadDeadzone    = 0.5
+	minPadThreshold   = 0.1
+	maxPadThreshold   = 0.9
+	padSettingStep    = 0.05
+	captureAxisFactor = 0.5 // An axis must move this much past rest to be captured
+)
+
+// captureAxes are the axes the controls screen listens to when binding.
+var captureAxes = []ebiten.StandardGamepadAxis{
+	ebiten.StandardGamepadAxisLeftStickHorizontal,
+	ebiten.StandardGamepadAxisLeftStickVertical,
+	ebiten.StandardGamepadAxisRightStickHorizontal,
+	ebiten.StandardGamepadAxisRightStickVertical,
+}
+
+// controlsMenu is the state of the gamepad controls screen.
+type controlsMenu struct {
+	list      menuList  // One row per action, then the deadzone, threshold and reset rows
+	pad       int       // Which connected standard-layout gamepad is being edited
+	capturing bool      // Waiting for the next input to bind to the action under the cursor
+	rest      []float64 // Axis values when capturing began, by captureAxes index
+}
+
+// standardPads lists the connected gamepads with a standard layout.
+func standardPads() []ebiten.GamepadID {
+	var pads []ebiten.GamepadID
+	for _, id := range ebiten.AppendGamepadIDs(nil) {
+		if ebiten.IsStandardGamepadLayoutAvailable(id) {
+			pads = append(pads, id)
+		}
+	}
+	return pads
+}
+
+// editedPad is the gamepad the controls screen is editing, if any.
+func (g *Game) editedPad() (ebiten.GamepadID, bool) {
+	pads := standardPads()
+	if len(pads) == 0 {
+		return 0, false
+	}
+	return pads[g.controlsMenu.pad%len(pads)], true
+}
+
+func (g *Game) updateControls() {
+	m := &g.controlsMenu
+	id, ok := g.editedPad()
+	if m.capturing {
+		switch {
+		case g.input.keyPressed(ebiten.KeyEscape) || !ok:
+			m.capturing = false
+		default:
+			if b, ok := m.capture(id, g.input.buttons); ok {
+				l := g.settings.padLayout(id)
+				l.Bindings[padActionNames[m.list.cursor]] = b
+				g.setPadLayout(id, l)
+				m.capturing = false
+			}
+		}
+		return
+	}
+
+	var items []menuItem
+	if ok {
+		items = g.controlItems(id)
+	}
+	in := g.readMenuInput(items)
+	switch {
+	case in.cancel:
+		g.screen = screenOptions
+	case !ok:
+		// Nothing to edit until a gamepad is connected
+	case g.input.keyPressed(ebiten.KeyTab) || g.input.padPressed(ebiten.StandardGamepadButtonFrontTopRight):
+		m.pad++ // RB moves on to the next gamepad, as Tab does
+	default:
+		m.list.handle(in, items)
+	}
+}
+
+// controlItems are the rows of the controls screen for one gamepad.
+func (g *Game) controlItems(id ebiten.GamepadID) []menuItem {
+	m := &g.controlsMenu
+	l := g.settings.padLayout(id)
+	items := make([]menuItem, 0, int(padActionCount)+3)
+	for a := range padActionCount {
+		value := l.binding(a).label()
+		if m.capturing && int(a) == m.list.cursor {
+			value = "..."
+		}
+		items = append(items, menuItem{label: tr("controls.action." + padActionNames[a]), value: value, activate: func() {
+			m.capturing = true
+			m.rest = m.rest[:0]
+			for _, a := range captureAxes {
+				m.rest = append(m.rest, ebiten.StandardGamepadAxisValue(id, a))
+			}
+		}})
+	}
+	return append(items,
+		menuItem{label: tr("controls.deadzone"), value: fmt.Sprintf("%.2f", l.Deadzone), adjust: func(dir int) {
+			l.Deadzone = snapPadSetting(l.Deadzone+float64(dir)*padSettingStep, padSettingStep, maxPadDeadzone)
+			g.setPadLayout(id, l)
+		}},
+		menuItem{label: tr("controls.threshold"), value: fmt.Sprintf("%.2f", l.Threshold), adjust: func(dir int) {
+			l.Threshold = snapPadSetting(l.Threshold+float64(dir)*padSettingStep, minPadThreshold, maxPadThreshold)
+			g.setPadLayout(id, l)
+		}},
+		menuItem{label: tr("controls.reset"), activate: func() { g.resetPadLayout(id) }},
+	)
+}
+
+// snapPadSetting rounds v to the slider's steps and keeps it in range.
+func snapPadSetting(v, lo, hi float64) float64 {
+	v = math.Round(v/padSettingStep) * padSettingStep
+	return math.Max(lo, math.Min(v, hi))
+}
+
+// capture returns the first of this tick's button presses on a gamepad,
+// or an axis pushed on it since capturing began. Axes count once they move
+// well away from where they rested, so a stick already held or a resting
+// trigger isn't bound.
+func (m *controlsMenu) capture(id ebiten.GamepadID, pressed []padPress) (padBinding, bool) {
+	for _, p := range pressed {
+		if p.id == id {
+			return padButton(p.button), true
+		}
+	}
+	for i, a := range captureAxes {
+		d := ebiten.StandardGamepadAxisValue(id, a) - m.rest[i]
+		if math.Abs(d) < captureAxisFactor {
+			continue
+		}
+		dir := 1
+		if d < 0 {
+			dir = -1
+		}
+		return padAxis(a, dir), true
+	}
+	return padBinding{}, false
+}
+
+// setPadLayout stores a gamepad's layout on the profile and saves it.
+func (g *Game) setPadLayout(id ebiten.GamepadID, l padLayout) {
+	l.Name = ebiten.GamepadName(id)
+	if g.profile.Settings.PadLayouts == nil {
+		g.profile.Settings.PadLayouts = make(map[string]padLayout)
+	}
+	g.profile.Settings.PadLayouts[ebiten.GamepadSDLID(id)] = l
+	g.settings.PadLayouts = g.profile.Settings.PadLayouts
+	g.saveErr = g.profile.save()
+}
+
+// resetPadLayout forgets a gamepad's layout so it goes back to the defaults.
+func (g *Game) resetPadLayout(id ebiten.GamepadID) {
+	delete(g.profile.Settings.PadLayouts, ebiten.GamepadSDLID(id))
+	g.settings.PadLayouts = g.profile.Settings.PadLayouts
+	g.saveErr = g.profile.save()
+}
+
+func (g *Game) drawControls(screen *ebiten.Image) {
+	m := &g.controlsMenu
+	cx := screenWidth/2 - 150
+	drawCentered(screen, tr("controls.title"), 40)
+
+	id, ok := g.editedPad()
+	if !ok {
+		drawCentered(screen, tr("controls.no_pad"), screenHeight/2)
+		drawCentered(screen, tr("controls.back"), screenHeight-30)
+		return
+	}
+	ebitenutil.DebugPrintAt(screen, trf("controls.device", ebiten.GamepadName(id)), cx, 70)
+
+	y := m.list.draw(screen, g.controlItems(id), menuLayout{x: cx, y: 100, width: 320, row: 20, valueX: 160})
+
+	if m.capturing {
+		drawCentered(screen, trf("controls.capture", tr("controls.action."+padActionNames[m.list.cursor])), y+20)
+	} else {
+		ebitenutil.DebugPrintAt(screen, tr("controls.help"), cx, y+20)
+	}
+	if g.saveErr != nil {
+		g.hud().bottomLeft(screen, trf("save_failed", g.saveErr), 0)
+	}
+}
+
+var padButtonLabels = map[ebiten.StandardGamepadButton]string{
+	ebiten.StandardGamepadButtonRightBottom:      "A",
+	ebiten.StandardGamepadButtonRightRight:       "B",
+	ebiten.StandardGamepadButtonRightLeft:        "X",
+	ebiten.StandardGamepadButtonRightTop:         "Y",
+	ebiten.StandardGamepadButtonFrontTopLeft:     "LB",
+	ebiten.StandardGamepadButtonFrontTopRight:    "RB",
+	ebiten.StandardGamepadButtonFrontBottomLeft:  "LT",
+	ebiten.StandardGamepadButtonFrontBottomRight: "RT",
+	ebiten.StandardGamepadButtonCenterLeft:       "Back",
+	ebiten.StandardGamepadButtonCenterRight:      "Start",
+	ebiten.StandardGamepadButtonCenterCenter:     "Home",
+	ebiten.StandardGamepadButtonLeftStick:        "LS click",
+	ebiten.StandardGamepadButtonRightStick:       "RS click",
+	ebiten.StandardGamepadButtonLeftTop:          "D-pad up",
+	ebiten.StandardGamepadButtonLeftBottom:       "D-pad down",
+	ebiten.StandardGamepadButtonLeftLeft:         "D-pad left",
+	ebiten.StandardGamepadButtonLeftRight:        "D-pad right",
+}
+
+var padAxisLabels = map[ebiten.StandardGamepadAxis][2]string{ // Negative, positive
+	ebiten.StandardGamepadAxisLeftStickHorizontal:  {"LS left", "LS right"},
+	ebiten.StandardGamepadAxisLeftStickVertical:    {"LS up", "LS down"},
+	ebiten.StandardGamepadAxisRightStickHorizontal: {"RS left", "RS right"},
+	ebiten.StandardGamepadAxisRightStickVertical:   {"RS up", "RS down"},
+}
+
+// label names a binding the way it is printed on a standard gamepad.
+func (b padBinding) label() string {
+	if b.Dir == 0 {
+		if s, ok := padButtonLabels[b.Button]; ok {
+			return s
+		}
+		return fmt.Sprintf("button %d", b.Button)
+	}
+	names, ok := padAxisLabels[b.Axis]
+	if !ok {
+		return fmt.Sprintf("axis %d", b.Axis)
+	}
+	if b.Dir < 0 {
+		return names[0]
+	}
+	return names[1]
+}
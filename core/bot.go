@@ -0,0 +1,142 @@
+package core
+
+import "math"
+
+// BotSkill sets how well a computer-controlled ship plays.
+type BotSkill struct {
+	Name      string  // Shown in menus
+	Reaction  float64 // Seconds between seeing the world and acting on it
+	AimError  float64 // Largest aiming mistake, in radians
+	Foresight float64 // Seconds ahead the bot looks for asteroids about to hit it
+}
+
+var BotSkills = []BotSkill{
+	{Name: "easy", Reaction: 0.3, AimError: 0.3, Foresight: 0.25},
+	{Name: "normal", Reaction: 0.15, AimError: 0.12, Foresight: 0.5},
+	{Name: "hard", Reaction: 0.03, AimError: 0.03, Foresight: 0.9},
+}
+
+// BotInput decides what a bot flying w's ship does this tick. It depends
+// only on the world and the skill, so the same state always gives the same
+// input. Reaction time is up to the caller, which should apply the input
+// skill.Reaction seconds after the state it was computed from.
+func BotInput(w *World, skill BotSkill) FrameInput {
+	var in FrameInput
+	if w.GameOver {
+		return in
+	}
+	p := &w.Player
+	px, py := p.X+p.Width/2, p.Y+p.Height/2
+
+	// Step sideways away from the closest asteroid that will reach the
+	// ship's row within the foresight window
+	var threat *Asteroid
+	threatAt := math.Inf(1)
+	for i := range w.Asteroids {
+		a := &w.Asteroids[i]
+		if !a.Active || a.Y > p.Y+p.Height {
+			continue
+		}
+		reach := (p.Y - (a.Y + a.Height)) / a.Speed // Seconds until it is level with the ship
+		if reach > skill.Foresight {
+			continue
+		}
+		if math.Abs(w.dx(a.X+a.Width/2, px)) > (a.Width+p.Width)/2+w.Config.Tuning.ThreatMargin {
+			continue
+		}
+		if reach < threatAt {
+			threat, threatAt = a, reach
+		}
+	}
+	if threat != nil {
+		in.MoveX = -1
+		if w.dx(threat.X+threat.Width/2, px) > 0 {
+			in.MoveX = 1
+		}
+		// Turn back rather than pin ourselves against a wall
+		if !w.Config.Wrap && (in.MoveX < 0 && p.X < p.Width || in.MoveX > 0 && p.X > w.Config.Width-2*p.Width) {
+			in.MoveX = -in.MoveX
+		}
+	} else {
+		// Drift back toward the middle, where there is room to dodge either way
+		if mid := w.Config.Width / 2; math.Abs(px-mid) > p.Width {
+			in.MoveX = math.Copysign(0.5, mid-px)
+		}
+	}
+
+	// Lead the nearest asteroid above the ship and fire at where it will be
+	bulletSpeed := w.Stats().BulletSpeed
+	var aimX, aimY float64
+	best := math.Inf(1)
+	for i := range w.Asteroids {
+		a := &w.Asteroids[i]
+		if !a.Active || a.Y+a.Height < 0 || a.Y > p.Y {
+			continue
+		}
+		ax, ay := a.X+a.Width/2, a.Y+a.Height/2
+		t, ok := intercept(w.dx(ax, px), ay-py, a.Speed, bulletSpeed)
+		if !ok || t >= best {
+			continue
+		}
+		best = t
+		aimX, aimY = w.dx(ax, px), ay+a.Speed*t-py
+	}
+	if !math.IsInf(best, 1) {
+		angle := math.Atan2(aimY, aimX) + skill.AimError*botNoise(w.Time)
+		in.Aiming = true
+		in.AimX, in.AimY = math.Cos(angle), math.Sin(angle)
+	}
+	return in
+}
+
+// dx is the signed horizontal offset from x2 to x1, the short way round
+// when wrapping.
+func (w *World) dx(x1, x2 float64) float64 {
+	d := x1 - x2
+	if w.Config.Wrap {
+		if d > w.Config.Width/2 {
+			d -= w.Config.Width
+		} else if d < -w.Config.Width/2 {
+			d += w.Config.Width
+		}
+	}
+	return d
+}
+
+// intercept returns when a bullet fired now at speed meets a target at
+// offset (dx, dy) that falls at fall pixels per second.
+func intercept(dx, dy, fall, speed float64) (float64, bool) {
+	// |(dx, dy + fall*t)| = speed*t, as a quadratic in t
+	a := fall*fall - speed*speed
+	b := 2 * dy * fall
+	c := dx*dx + dy*dy
+	if a == 0 {
+		// Bullet and target equally fast: only a head-on meeting works
+		if b >= 0 {
+			return 0, false
+		}
+		return -c / b, true
+	}
+	disc := b*b - 4*a*c
+	if disc < 0 {
+		return 0, false
+	}
+	t1 := (-b - math.Sqrt(disc)) / (2 * a)
+	t2 := (-b + math.Sqrt(disc)) / (2 * a)
+	if t1 > t2 {
+		t1, t2 = t2, t1
+	}
+	if t1 >= 0 {
+		return t1, true
+	}
+	return t2, t2 >= 0
+}
+
+// botNoise is a repeatable pseudo-random value in [-1, 1] for a tick.
+func botNoise(tick int) float64 {
+	x := uint32(tick)*2654435761 + 0x9e3779b9
+	x ^= x >> 15
+	x *= 0x85ebca6b
+	x ^= x >> 13
+	return float64(x)/math.MaxUint32*2 - 1
+}
@@ -0,0 +1,54 @@
+package core
+
+import "testing"
+
+// botRun flies one run with the bot's input delayed by its reaction time,
+// for up to limit seconds, and returns the score.
+func botRun(skill BotSkill, seed int64, limit float64) int {
+	w := NewWorld(testConfig(), seed)
+	pending := make([]FrameInput, w.Ticks(skill.Reaction)) // Decided but not yet acted on
+	for !w.GameOver && w.Time < w.Ticks(limit) {
+		pending = append(pending, BotInput(w, skill))
+		w.Step(pending[0])
+		pending = pending[1:]
+	}
+	return w.Score
+}
+
+func TestBotSkillLadder(t *testing.T) {
+	const games = 100
+	totals := make([]int, len(BotSkills))
+	for i, s := range BotSkills {
+		for seed := int64(1); seed <= games; seed++ {
+			totals[i] += botRun(s, seed, 90)
+		}
+		t.Logf("%s: %.1f points a game", s.Name, float64(totals[i])/games)
+	}
+	// Each step up the ladder scores more, and the top clearly more than
+	// the bottom
+	for i := 1; i < len(totals); i++ {
+		if totals[i] <= totals[i-1] {
+			t.Errorf("%s scored %d over %d games, no more than %s's %d",
+				BotSkills[i].Name, totals[i], games, BotSkills[i-1].Name, totals[i-1])
+		}
+	}
+	easy, hard := totals[0], totals[len(totals)-1]
+	if float64(hard) < 1.2*float64(easy) {
+		t.Errorf("hard scored %d over %d games, not clearly more than easy's %d", hard, games, easy)
+	}
+}
+
+func TestBotInputIsPure(t *testing.T) {
+	w := NewWorld(testConfig(), 4)
+	for w.Time < 600 && !w.GameOver {
+		before := w.Hash()
+		in := BotInput(w, BotSkills[1])
+		if w.Hash() != before {
+			t.Fatal("BotInput changed the world")
+		}
+		if again := BotInput(w, BotSkills[1]); again != in {
+			t.Fatalf("BotInput gave %+v, then %+v for the same state", in, again)
+		}
+		w.Step(in)
+	}
+}
@@ -0,0 +1,228 @@
+package core
+
+import (
+	"math"
+	"slices"
+)
+
+// BroadPhase picks how collision checks find the asteroids near a box.
+// Every strategy finds exactly the same collisions, in the same order; they
+// differ only in speed.
+type BroadPhase int
+
+const (
+	BroadPhaseNone     BroadPhase = iota // Check every asteroid
+	BroadPhaseGrid                       // Bucket asteroids into uniform cells
+	BroadPhaseQuadtree                   // Subdivide space where asteroids cluster
+)
+
+const (
+	gridCellSize     = 64 // Pixels per side of a grid cell
+	quadtreeMaxItems = 8  // Items a quadtree node holds before splitting
+	quadtreeMaxDepth = 6
+)
+
+// broadPhase narrows collision candidates down to nearby asteroids.
+type broadPhase interface {
+	// build indexes the active asteroids.
+	build(asteroids []Asteroid)
+	// query appends to dst the indexes of asteroids that may overlap the
+	// box, in ascending order and without duplicates.
+	query(dst []int, x, y, w, h float64) []int
+}
+
+func newBroadPhase(kind BroadPhase) broadPhase {
+	switch kind {
+	case BroadPhaseGrid:
+		return &uniformGrid{cells: make(map[[2]int][]int)}
+	case BroadPhaseQuadtree:
+		return &quadtreePhase{}
+	default:
+		return &allAsteroids{}
+	}
+}
+
+// allAsteroids is the naive strategy: everything is a candidate.
+type allAsteroids struct {
+	n int
+}
+
+func (p *allAsteroids) build(asteroids []Asteroid) {
+	p.n = len(asteroids)
+}
+
+func (p *allAsteroids) query(dst []int, x, y, w, h float64) []int {
+	for i := 0; i < p.n; i++ {
+		dst = append(dst, i)
+	}
+	return dst
+}
+
+// uniformGrid buckets asteroids by the cells their boxes cover.
+type uniformGrid struct {
+	cells map[[2]int][]int
+}
+
+func (g *uniformGrid) build(asteroids []Asteroid) {
+	for k, v := range g.cells {
+		g.cells[k] = v[:0]
+	}
+	for i, a := range asteroids {
+		if !a.Active {
+			continue
+		}
+		x0, y0, x1, y1 := gridSpan(a.X, a.Y, a.Width, a.Height)
+		for cx := x0; cx <= x1; cx++ {
+			for cy := y0; cy <= y1; cy++ {
+				k := [2]int{cx, cy}
+				g.cells[k] = append(g.cells[k], i)
+			}
+		}
+	}
+}
+
+func (g *uniformGrid) query(dst []int, x, y, w, h float64) []int {
+	start := len(dst)
+	x0, y0, x1, y1 := gridSpan(x, y, w, h)
+	for cx := x0; cx <= x1; cx++ {
+		for cy := y0; cy <= y1; cy++ {
+			dst = append(dst, g.cells[[2]int{cx, cy}]...)
+		}
+	}
+	return sortUnique(dst, start)
+}
+
+// gridSpan returns the range of cells a box covers.
+func gridSpan(x, y, w, h float64) (x0, y0, x1, y1 int) {
+	return int(math.Floor(x / gridCellSize)), int(math.Floor(y / gridCellSize)),
+		int(math.Floor((x + w) / gridCellSize)), int(math.Floor((y + h) / gridCellSize))
+}
+
+// quadtreePhase rebuilds a Quadtree around the asteroids every tick.
+type quadtreePhase struct {
+	tree *Quadtree
+}
+
+func (p *quadtreePhase) build(asteroids []Asteroid) {
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, a := range asteroids {
+		if a.Active {
+			minX, minY = math.Min(minX, a.X), math.Min(minY, a.Y)
+			maxX, maxY = math.Max(maxX, a.X+a.Width), math.Max(maxY, a.Y+a.Height)
+		}
+	}
+	if math.IsInf(minX, 1) {
+		p.tree = nil
+		return
+	}
+	p.tree = NewQuadtree(minX, minY, maxX-minX, maxY-minY)
+	for i, a := range asteroids {
+		if a.Active {
+			p.tree.Insert(i, a.X, a.Y, a.Width, a.Height)
+		}
+	}
+}
+
+func (p *quadtreePhase) query(dst []int, x, y, w, h float64) []int {
+	if p.tree == nil {
+		return dst
+	}
+	start := len(dst)
+	dst = p.tree.Query(dst, x, y, w, h)
+	return sortUnique(dst, start)
+}
+
+// Quadtree indexes boxes by recursively splitting its area into quarters.
+// Boxes that straddle a split stay in the node that holds them whole.
+type Quadtree struct {
+	x, y, w, h float64
+	depth      int
+	items      []quadItem
+	children   *[4]Quadtree
+}
+
+type quadItem struct {
+	id         int
+	x, y, w, h float64
+}
+
+// NewQuadtree returns an empty tree covering the given area. Boxes outside
+// it can still be inserted; they stay at the root.
+func NewQuadtree(x, y, w, h float64) *Quadtree {
+	return &Quadtree{x: x, y: y, w: w, h: h}
+}
+
+// Insert adds a box with the given id.
+func (q *Quadtree) Insert(id int, x, y, w, h float64) {
+	item := quadItem{id, x, y, w, h}
+	for {
+		if q.children == nil {
+			q.items = append(q.items, item)
+			if len(q.items) > quadtreeMaxItems && q.depth < quadtreeMaxDepth {
+				q.split()
+			}
+			return
+		}
+		child := q.childFor(item)
+		if child == nil {
+			q.items = append(q.items, item)
+			return
+		}
+		q = child
+	}
+}
+
+// Query appends to dst the ids of boxes that overlap the given box.
+func (q *Quadtree) Query(dst []int, x, y, w, h float64) []int {
+	for _, it := range q.items {
+		if isColliding(x, y, w, h, it.x, it.y, it.w, it.h) {
+			dst = append(dst, it.id)
+		}
+	}
+	if q.children != nil {
+		for i := range q.children {
+			c := &q.children[i]
+			if isColliding(x, y, w, h, c.x, c.y, c.w, c.h) {
+				dst = c.Query(dst, x, y, w, h)
+			}
+		}
+	}
+	return dst
+}
+
+func (q *Quadtree) split() {
+	hw, hh := q.w/2, q.h/2
+	q.children = &[4]Quadtree{
+		{x: q.x, y: q.y, w: hw, h: hh, depth: q.depth + 1},
+		{x: q.x + hw, y: q.y, w: hw, h: hh, depth: q.depth + 1},
+		{x: q.x, y: q.y + hh, w: hw, h: hh, depth: q.depth + 1},
+		{x: q.x + hw, y: q.y + hh, w: hw, h: hh, depth: q.depth + 1},
+	}
+	items := q.items
+	q.items = nil
+	for _, it := range items {
+		if child := q.childFor(it); child != nil {
+			child.items = append(child.items, it)
+		} else {
+			q.items = append(q.items, it)
+		}
+	}
+}
+
+// childFor returns the child that wholly contains an item, if any.
+func (q *Quadtree) childFor(it quadItem) *Quadtree {
+	for i := range q.children {
+		c := &q.children[i]
+		if it.x >= c.x && it.y >= c.y && it.x+it.w <= c.x+c.w && it.y+it.h <= c.y+c.h {
+			return c
+		}
+	}
+	return nil
+}
+
+// sortUnique sorts dst[start:] and drops repeats from it.
+func sortUnique(dst []int, start int) []int {
+	slices.Sort(dst[start:])
+	return append(dst[:start], slices.Compact(dst[start:])...)
+}
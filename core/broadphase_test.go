@@ -0,0 +1,131 @@
+package core
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+var broadPhases = []struct {
+	name string
+	kind BroadPhase
+}{
+	{"naive", BroadPhaseNone},
+	{"grid", BroadPhaseGrid},
+	{"quadtree", BroadPhaseQuadtree},
+}
+
+// layout returns n asteroids spread over a 640x480 field, or bunched into
+// a few tight clusters, with some inactive ones mixed in.
+func layout(r *rand.Rand, n int, clustered bool) []Asteroid {
+	var centers [][2]float64
+	for i := 0; i < 4; i++ {
+		centers = append(centers, [2]float64{r.Float64() * 640, r.Float64() * 480})
+	}
+	asteroids := make([]Asteroid, n)
+	for i := range asteroids {
+		size := 10 + r.Float64()*50
+		x, y := r.Float64()*700-30, r.Float64()*540-30
+		if clustered {
+			c := centers[r.Intn(len(centers))]
+			x, y = c[0]+r.NormFloat64()*25, c[1]+r.NormFloat64()*25
+		}
+		asteroids[i] = Asteroid{X: x, Y: y, Width: size, Height: size, Active: r.Intn(10) != 0}
+	}
+	return asteroids
+}
+
+func TestBroadPhasesFindEveryOverlap(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, clustered := range []bool{false, true} {
+		for round := 0; round < 50; round++ {
+			asteroids := layout(r, 1+r.Intn(200), clustered)
+			for _, bp := range broadPhases {
+				p := newBroadPhase(bp.kind)
+				p.build(asteroids)
+				for q := 0; q < 50; q++ {
+					x, y := r.Float64()*700-30, r.Float64()*540-30
+					w, h := 1+r.Float64()*60, 1+r.Float64()*60
+					got := p.query(nil, x, y, w, h)
+					if !slices.IsSorted(got) || len(slices.Compact(slices.Clone(got))) != len(got) {
+						t.Fatalf("%s: candidates %v aren't ascending and unique", bp.name, got)
+					}
+					for i, a := range asteroids {
+						if a.Active && isColliding(x, y, w, h, a.X, a.Y, a.Width, a.Height) && !slices.Contains(got, i) {
+							t.Fatalf("%s (clustered %v): asteroid %d at (%g, %g) overlaps box (%g, %g, %g, %g) but wasn't a candidate",
+								bp.name, clustered, i, a.X, a.Y, x, y, w, h)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestBroadPhasesPlayIdentically(t *testing.T) {
+	// hash ignores which strategy the world was told to use
+	hash := func(w *World) uint64 {
+		kind := w.Config.BroadPhase
+		w.Config.BroadPhase = BroadPhaseNone
+		defer func() { w.Config.BroadPhase = kind }()
+		return w.Hash()
+	}
+	for seed := int64(1); seed <= 5; seed++ {
+		worlds := make([]*World, len(broadPhases))
+		for i, bp := range broadPhases {
+			cfg := testConfig()
+			cfg.BroadPhase = bp.kind
+			cfg.SpawnScale = 4 // A crowded field has plenty to collide
+			worlds[i] = NewWorld(cfg, seed)
+			worlds[i].Invulnerable = true
+		}
+		naive := worlds[0]
+		for naive.Time < 1800 {
+			for _, w := range worlds {
+				w.Step(FrameInput{MoveX: float64(w.Time/120%2*2 - 1), FirePressed: w.Time%10 == 0})
+			}
+			for i, w := range worlds[1:] {
+				if hash(w) != hash(naive) {
+					t.Fatalf("seed %d: %s diverged from the naive loop at tick %d", seed, broadPhases[i+1].name, w.Time)
+				}
+			}
+		}
+		if naive.Destroyed == 0 {
+			t.Fatalf("seed %d: nothing was destroyed, so the test proves nothing", seed)
+		}
+	}
+}
+
+func BenchmarkBroadPhase(b *testing.B) {
+	for _, clustered := range []bool{false, true} {
+		dist := "uniform"
+		if clustered {
+			dist = "clustered"
+		}
+		r := rand.New(rand.NewSource(1))
+		asteroids := layout(r, DefaultMaxAsteroids, clustered)
+		var boxes [][4]float64
+		for i := 0; i < DefaultMaxBullets; i++ {
+			boxes = append(boxes, [4]float64{r.Float64() * 640, r.Float64() * 480, BulletWidth, BulletHeight})
+		}
+		for _, bp := range broadPhases {
+			b.Run(fmt.Sprintf("%s/%s", dist, bp.name), func(b *testing.B) {
+				p := newBroadPhase(bp.kind)
+				var dst []int
+				for i := 0; i < b.N; i++ {
+					// One tick: index the field, then find what each bullet
+					// might hit and check it exactly
+					p.build(asteroids)
+					for _, box := range boxes {
+						dst = p.query(dst[:0], box[0], box[1], box[2], box[3])
+						for _, j := range dst {
+							a := &asteroids[j]
+							isColliding(box[0], box[1], box[2], box[3], a.X, a.Y, a.Width, a.Height)
+						}
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package core
+
+import "fmt"
+
+// CurveKey is one keyframe of the endless difficulty curve.
+type CurveKey struct {
+	Time          float64   `json:"time"`                  // Seconds into the run
+	SpawnInterval float64   `json:"spawnInterval"`         // Seconds between spawns
+	SpeedScale    float64   `json:"speedScale"`            // Multiplier on asteroidSpeed
+	SizeWeights   []float64 `json:"sizeWeights,omitempty"` // Replaces the asteroidSizes weights, class by class; empty keeps them
+	Formations    float64   `json:"formations"`            // Chance a spawn is a formation instead, from 0 to 1
+}
+
+// Curve is how endless mode gets harder: keyframes in time order, with
+// values interpolated linearly between them and the last one held
+// forever. Before the first, the first holds.
+type Curve []CurveKey
+
+// Difficulty is the curve's values at one moment.
+type Difficulty struct {
+	SpawnInterval float64
+	SpeedScale    float64
+	SizeWeights   []float64 // One per asteroidSizes class
+	Formations    float64
+}
+
+// At is the curve's values secs into a run, with sizes supplying the
+// weights of keyframes that don't set their own.
+func (c Curve) At(secs float64, sizes SizeTable) Difficulty {
+	i := 0
+	for i < len(c)-1 && c[i+1].Time <= secs {
+		i++
+	}
+	a, b, f := c[i], c[i], 0.0
+	if i < len(c)-1 && secs > a.Time {
+		b = c[i+1]
+		f = (secs - a.Time) / (b.Time - a.Time)
+	}
+	lerp := func(x, y float64) float64 { return x + (y-x)*f }
+	d := Difficulty{
+		SpawnInterval: lerp(a.SpawnInterval, b.SpawnInterval),
+		SpeedScale:    lerp(a.SpeedScale, b.SpeedScale),
+		SizeWeights:   make([]float64, len(sizes)),
+		Formations:    lerp(a.Formations, b.Formations),
+	}
+	for j := range sizes {
+		d.SizeWeights[j] = lerp(a.sizeWeight(j, sizes), b.sizeWeight(j, sizes))
+	}
+	return d
+}
+
+// sizeWeight is the keyframe's weight for size class i.
+func (k CurveKey) sizeWeight(i int, sizes SizeTable) float64 {
+	if len(k.SizeWeights) == 0 {
+		return sizes[i].Weight
+	}
+	return k.SizeWeights[i]
+}
+
+// validate checks the keyframes are in order and every value is usable
+// with the given size table.
+func (c Curve) validate(sizes SizeTable) []error {
+	if len(c) == 0 {
+		return []error{fmt.Errorf("endlessCurve needs at least one keyframe")}
+	}
+	var errs []error
+	for i, k := range c {
+		if i == 0 && k.Time < 0 {
+			errs = append(errs, fmt.Errorf("endlessCurve[0].time can't be negative, got %g", k.Time))
+		}
+		if i > 0 && k.Time <= c[i-1].Time {
+			errs = append(errs, fmt.Errorf("endlessCurve[%d].time must be after the keyframe before it (%g), got %g", i, c[i-1].Time, k.Time))
+		}
+		if k.SpawnInterval <= 0 {
+			errs = append(errs, fmt.Errorf("endlessCurve[%d].spawnInterval must be positive, got %g", i, k.SpawnInterval))
+		}
+		if k.SpeedScale <= 0 {
+			errs = append(errs, fmt.Errorf("endlessCurve[%d].speedScale must be positive, got %g", i, k.SpeedScale))
+		}
+		if k.Formations < 0 || k.Formations > 1 {
+			errs = append(errs, fmt.Errorf("endlessCurve[%d].formations must be from 0 to 1, got %g", i, k.Formations))
+		}
+		if len(k.SizeWeights) == 0 {
+			continue
+		}
+		if len(k.SizeWeights) != len(sizes) {
+			errs = append(errs, fmt.Errorf("endlessCurve[%d].sizeWeights needs one weight per asteroidSizes class (%d), got %d", i, len(sizes), len(k.SizeWeights)))
+			continue
+		}
+		total := 0.0
+		for _, w := range k.SizeWeights {
+			if w < 0 {
+				errs = append(errs, fmt.Errorf("endlessCurve[%d].sizeWeights can't be negative, got %g", i, w))
+			}
+			total += max(w, 0)
+		}
+		if total == 0 {
+			errs = append(errs, fmt.Errorf("endlessCurve[%d].sizeWeights needs a positive weight", i))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,76 @@
+package core
+
+// Edge is a side of the field.
+type Edge int
+
+const (
+	EdgeTop Edge = iota
+	EdgeBottom
+	EdgeLeft
+	EdgeRight
+	edgeCount
+)
+
+// spawnPoint picks where a lone asteroid width across enters the field,
+// and through which edge. Only twin-stick runs use any edge but the top.
+func (w *World) spawnPoint(width float64) (x, y float64, from Edge) {
+	if w.Config.Mode != ModeTwinStick {
+		return w.spawnX(width), -width, EdgeTop
+	}
+	r := w.rand()
+	from = Edge(r.Intn(int(edgeCount)))
+	switch from {
+	case EdgeBottom:
+		return float64(r.Intn(int(w.Config.Width) - int(width))), w.Config.Height, from
+	case EdgeLeft:
+		return -width, float64(r.Intn(int(w.Config.Height) - int(width))), from
+	case EdgeRight:
+		return w.Config.Width, float64(r.Intn(int(w.Config.Height) - int(width))), from
+	}
+	return w.spawnX(width), -width, from
+}
+
+// enterFrom places an asteroid at (x, y) heading across the field from an
+// edge at its usual speed.
+func (a *Asteroid) enterFrom(from Edge, x, y float64) {
+	speed := a.Speed
+	a.X, a.Y, a.PrevX, a.PrevY = x, y, x, y
+	a.From = from
+	switch from {
+	case EdgeBottom:
+		a.Speed = -speed
+	case EdgeLeft:
+		a.Speed, a.VX = 0, speed
+	case EdgeRight:
+		a.Speed, a.VX = 0, -speed
+	}
+}
+
+// entered reports whether an asteroid has come into the field through the
+// edge it spawned beyond.
+func (w *World) entered(a *Asteroid) bool {
+	switch a.From {
+	case EdgeBottom:
+		return a.Y <= w.Config.Height
+	case EdgeLeft:
+		return a.X+a.Width >= 0
+	case EdgeRight:
+		return a.X <= w.Config.Width
+	}
+	return a.Y+a.Height >= 0
+}
+
+// exited reports whether an asteroid has crossed the field and left through
+// the edge opposite the one it came in by. Asteroids travel straight, so
+// that is the only way out.
+func (w *World) exited(a *Asteroid) bool {
+	switch a.From {
+	case EdgeBottom:
+		return a.Y+a.Height < 0
+	case EdgeLeft:
+		return a.X > w.Config.Width
+	case EdgeRight:
+		return a.X+a.Width < 0
+	}
+	return w.leftBottom(a.Y)
+}
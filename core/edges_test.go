@@ -0,0 +1,74 @@
+package core
+
+import (
+	"math"
+	"testing"
+)
+
+func TestExitBoundaries(t *testing.T) {
+	const size = 40
+	// Just past the boundary, by the smallest step there is
+	past := func(v, dir float64) float64 { return math.Nextafter(v, v+dir) }
+	w := quietWorld(testConfig())
+	width, height := w.Config.Width, w.Config.Height
+	tests := []struct {
+		name   string
+		from   Edge
+		x, y   float64
+		exited bool
+	}{
+		{"top entry, top edge on the bottom", EdgeTop, 100, height, false},
+		{"top entry, just below", EdgeTop, 100, past(height, 1), true},
+		{"top entry, half out", EdgeTop, 100, height - size/2, false},
+		{"bottom entry, bottom edge on the top", EdgeBottom, 100, -size, false},
+		{"bottom entry, just above", EdgeBottom, 100, past(-size, -1), true},
+		{"left entry, left edge on the right", EdgeLeft, width, 100, false},
+		{"left entry, just beyond", EdgeLeft, past(width, 1), 100, true},
+		{"right entry, right edge on the left", EdgeRight, -size, 100, false},
+		{"right entry, just beyond", EdgeRight, past(-size, -1), 100, true},
+	}
+	for _, tt := range tests {
+		a := Asteroid{X: tt.x, Y: tt.y, Width: size, Height: size, From: tt.from, Active: true}
+		if got := w.exited(&a); got != tt.exited {
+			t.Errorf("%s: exited is %v, want %v", tt.name, got, tt.exited)
+		}
+	}
+}
+
+func TestBulletBoundaries(t *testing.T) {
+	w := quietWorld(testConfig())
+	height := w.Config.Height
+	tests := []struct {
+		name string
+		y    float64
+		left bool
+	}{
+		{"bottom edge on the top", -BulletHeight, false},
+		{"just above the top", math.Nextafter(-BulletHeight, -100), true},
+		{"half out of the top", -BulletHeight / 2, false},
+		{"top edge on the bottom", height, false},
+		{"just below the bottom", math.Nextafter(height, height+100), true},
+	}
+	for _, tt := range tests {
+		if got := w.leftField(100, tt.y, BulletWidth, BulletHeight); got != tt.left {
+			t.Errorf("%s: left the field is %v, want %v", tt.name, got, tt.left)
+		}
+	}
+}
+
+func TestAsteroidLeavesOnceWhollyOut(t *testing.T) {
+	w := quietWorld(testConfig())
+	w.Player.X = 0
+	// One pixel a tick, starting with its top edge a tick above the bottom
+	w.AddAsteroid(300, 40, float64(w.Config.TPS))
+	a := &w.Asteroids[0]
+	a.Y = w.Config.Height - 1
+	w.Step(FrameInput{})
+	if len(w.Asteroids) != 1 || w.Asteroids[0].Y != w.Config.Height {
+		t.Fatal("an asteroid with its top edge on the bottom was removed")
+	}
+	w.Step(FrameInput{})
+	if len(w.Asteroids) != 0 {
+		t.Error("an asteroid wholly below the field wasn't removed")
+	}
+}
@@ -0,0 +1,199 @@
+package core
+
+import "math"
+
+const (
+	FormationSize       = 30   // Pixels across each asteroid in a wall or V
+	FormationGapScale   = 1.5  // Wall gaps are at least this many player widths
+	FormationBudget     = 3.0  // Seconds of normal spawning a formation replaces
+	FormationChance     = 0.05 // Chance a spawn is a formation at level 0
+	FormationChanceStep = 0.03 // Added chance per level
+	FormationChanceMax  = 0.3
+	FormationLevelTime  = 30.0 // Modes without a pace of their own: seconds per level
+	HardcoreLevelTime   = 15.0 // Hardcore mode: seconds per level
+	HardcoreSpeedup     = 0.15 // Hardcore mode: added spawn rate per level
+	HardcoreSpeedupMax  = 3.0  // Hardcore mode: spawns never come more than this many times faster
+	ClusterSpeedScale   = 0.5  // Cluster asteroids fall this much slower than normal ones
+)
+
+// Formation is a group of asteroids spawned together.
+type Formation int
+
+const (
+	FormationWall    Formation = iota // A row across the field with one or two gaps
+	FormationV                        // A V whose point falls at the player
+	FormationCluster                  // A slow, loose clump
+	formationCount
+)
+
+// SpawnFormation drops a formation above the playfield. Pieces that would
+// start in the player's safe zone or exceed the asteroid cap are skipped,
+// which only makes the formation easier. A shared field only skips those
+// over the cap.
+func (w *World) SpawnFormation(f Formation) {
+	switch f {
+	case FormationWall:
+		w.spawnWall()
+	case FormationV:
+		w.spawnV()
+	case FormationCluster:
+		w.spawnCluster()
+	}
+}
+
+// level is how far the run has progressed, for scaling formation odds.
+func (w *World) level() int {
+	switch w.Config.Mode {
+	case ModeStage:
+		return w.Wave
+	case ModeHardcore:
+		return w.Time / w.Ticks(HardcoreLevelTime)
+	}
+	return w.Time / w.Ticks(FormationLevelTime)
+}
+
+// maybeSpawnFormation rolls for a formation in place of a normal spawn and
+// reports whether it spawned one. A formation holds off normal spawning
+// for FormationBudget.
+func (w *World) maybeSpawnFormation() bool {
+	if w.Config.Mode == ModeTwinStick {
+		return false // Formations all come from the top
+	}
+	r := w.rand()
+	chance := math.Min(FormationChance+FormationChanceStep*float64(w.level()), FormationChanceMax)
+	if d, ok := w.Difficulty(); ok {
+		chance = d.Formations
+	}
+	if r.Float64() >= chance {
+		return false
+	}
+	f := Formation(r.Intn(int(formationCount)))
+	budget := FormationBudget
+	// A scaled-up field spawns more often. A wall spans all of it, so walls
+	// keep to the same number per second. The others only cover a patch,
+	// so they come as often per spawn as ever and hold off normal spawning
+	// for less time, keeping the field as dense
+	if s := w.Config.SpawnScale; s > 1 {
+		if f == FormationWall && r.Float64() >= 1/s {
+			return false
+		}
+		if f != FormationWall {
+			budget /= s
+		}
+	}
+	w.SpawnFormation(f)
+	w.NextSpawn = w.Time + w.Ticks(budget)
+	return true
+}
+
+func (w *World) addFormationPiece(x, y, size, speed float64) {
+	if w.Config.SharedField {
+		// Roll the piece whether or not it fits, as spawnAsteroid does
+		a := w.newAsteroid(x, size)
+		if len(w.Asteroids) < w.Config.MaxAsteroids {
+			a.Y, a.PrevY = y, y
+			a.Speed = speed
+			w.Asteroids = append(w.Asteroids, a)
+		}
+		return
+	}
+	if len(w.Asteroids) >= w.Config.MaxAsteroids || w.inSpawnSafeZone(x, y, size, size) {
+		return
+	}
+	a := w.newAsteroid(x, size)
+	a.Y, a.PrevY = y, y
+	a.Speed = speed
+	w.Asteroids = append(w.Asteroids, a)
+}
+
+// formationTarget is where formations aim: the ship's center and top, or
+// on a shared field, where every ship starts.
+func (w *World) formationTarget() (x, y float64) {
+	p := &w.Player
+	if w.Config.SharedField {
+		return w.Config.Width / 2, w.Config.Height - 10 - p.Height
+	}
+	return p.X + p.Width/2, p.Y
+}
+
+// WallGaps returns where the gaps of a wall about to spawn would be, as
+// left edges, each gapWidth wide. There are one or two, and the first is
+// always close enough for the player to reach before the wall does; on a
+// shared field, a player who stayed where the ships start.
+func (w *World) WallGaps() (gaps []float64, gapWidth float64) {
+	r := w.rand()
+	p := &w.Player
+	gapWidth = FormationGapScale * p.Width
+	span := w.Config.Width - gapWidth
+
+	// Time until the wall reaches the ship, and how far the ship can move
+	// in it, keeping half a ship width of slack
+	px, py := w.formationTarget()
+	arrive := (py + FormationSize) / w.asteroidSpeed()
+	reach := w.Stats().PlayerSpeed*arrive - p.Width/2
+
+	// The first gap is somewhere the ship can get to; center it on the
+	// ship's center offset by up to reach
+	lo := math.Max(0, px-reach-gapWidth/2)
+	hi := math.Min(span, px+reach-gapWidth/2)
+	if hi < lo {
+		lo, hi = hi, lo
+	}
+	gaps = append(gaps, lo+r.Float64()*(hi-lo))
+	if r.Intn(2) == 0 {
+		gaps = append(gaps, r.Float64()*span)
+	}
+	return gaps, gapWidth
+}
+
+func (w *World) spawnWall() {
+	gaps, gapWidth := w.WallGaps()
+	speed := w.asteroidSpeed()
+	for x := 0.0; x+FormationSize <= w.Config.Width; x += FormationSize {
+		blocked := false
+		for _, g := range gaps {
+			if x < g+gapWidth && x+FormationSize > g {
+				blocked = true
+			}
+		}
+		if !blocked {
+			w.addFormationPiece(x, -FormationSize, FormationSize, speed)
+		}
+	}
+}
+
+// spawnV drops a V with its point over the player's current position and
+// its arms trailing up and out. Arm pieces are spaced so a ship that steps
+// off the point's line fits between the point and the first arm piece.
+func (w *World) spawnV() {
+	r := w.rand()
+	p := &w.Player
+	speed := w.asteroidSpeed()
+	step := FormationSize + FormationGapScale*p.Width
+	tx, _ := w.formationTarget()
+	cx := tx - FormationSize/2
+	arms := 2 + r.Intn(2)
+	w.addFormationPiece(cx, -FormationSize, FormationSize, speed)
+	for i := 1; i <= arms; i++ {
+		y := -FormationSize - float64(i)*step
+		for _, x := range []float64{cx - float64(i)*step, cx + float64(i)*step} {
+			if x >= 0 && x+FormationSize <= w.Config.Width {
+				w.addFormationPiece(x, y, FormationSize, speed)
+			}
+		}
+	}
+}
+
+// spawnCluster drops a loose clump of slow asteroids somewhere random.
+func (w *World) spawnCluster() {
+	r := w.rand()
+	const spread = 150
+	cx := r.Float64() * (w.Config.Width - spread)
+	n := 4 + r.Intn(3)
+	for i := 0; i < n; i++ {
+		size := w.asteroidSize()
+		x := cx + r.Float64()*(spread-size)
+		y := -size - r.Float64()*spread
+		w.addFormationPiece(x, y, size, w.asteroidSpeed()*ClusterSpeedScale)
+	}
+}
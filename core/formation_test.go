@@ -0,0 +1,93 @@
+package core
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+// openings returns the stretches of the row at y that no asteroid covers,
+// as left and right edges.
+func openings(w *World, y float64) [][2]float64 {
+	var edges [][2]float64
+	for _, a := range w.Asteroids {
+		if a.Y <= y && y < a.Y+a.Height {
+			edges = append(edges, [2]float64{a.X, a.X + a.Width})
+		}
+	}
+	slices.SortFunc(edges, func(a, b [2]float64) int { return int(a[0] - b[0]) })
+	var open [][2]float64
+	left := 0.0
+	for _, e := range edges {
+		if e[0] > left {
+			open = append(open, [2]float64{left, e[0]})
+		}
+		left = math.Max(left, e[1])
+	}
+	if left < w.Config.Width {
+		open = append(open, [2]float64{left, w.Config.Width})
+	}
+	return open
+}
+
+func TestWallAlwaysHasAReachableGap(t *testing.T) {
+	for _, width := range []float64{640, 960} {
+		for _, ship := range Ships {
+			for _, start := range []float64{0, 0.3, 0.5, 1} {
+				for seed := int64(1); seed <= 50; seed++ {
+					cfg := testConfig()
+					cfg.Width, cfg.Ship = width, ship
+					w := NewWorld(cfg, seed)
+					w.HoldSpawns, w.GraceUntil = true, 0
+					p := &w.Player
+					p.X = start * (width - p.Width)
+					w.SpawnFormation(FormationWall)
+
+					// Head for the nearest opening wide enough, as a player would
+					gapWidth := FormationGapScale * p.Width
+					target := math.Inf(1)
+					for _, o := range openings(w, -FormationSize/2) {
+						if o[1]-o[0] < gapWidth-1e-9 {
+							continue
+						}
+						mid := (o[0] + o[1]) / 2
+						if math.Abs(mid-p.X-p.Width/2) < math.Abs(target-p.X-p.Width/2) {
+							target = mid
+						}
+					}
+					if math.IsInf(target, 1) {
+						t.Fatalf("width %g, %s, seed %d: no gap %g wide in %v", width, ship.Name, seed, gapWidth, openings(w, -FormationSize/2))
+					}
+					perTick := w.Stats().PlayerSpeed / float64(cfg.TPS)
+					steer := func(w *World) FrameInput {
+						d := target - (w.Player.X + w.Player.Width/2)
+						return FrameInput{MoveX: math.Max(-1, math.Min(1, d/perTick))}
+					}
+					stepUntil(w, 600, steer, noAsteroids)
+					if w.GameOver {
+						t.Fatalf("width %g, %s from %g, seed %d: the ship couldn't reach the gap at %g in time", width, ship.Name, start, seed, target)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestWallGapsStayOnTheField(t *testing.T) {
+	w := quietWorld(testConfig())
+	for i := 0; i < 1000; i++ {
+		w.Player.X = float64(i%7) / 6 * (w.Config.Width - w.Player.Width)
+		gaps, gapWidth := w.WallGaps()
+		if len(gaps) < 1 || len(gaps) > 2 {
+			t.Fatalf("%d gaps, want one or two", len(gaps))
+		}
+		if gapWidth < FormationGapScale*w.Player.Width {
+			t.Fatalf("gaps are %g wide, narrower than %g player widths", gapWidth, FormationGapScale)
+		}
+		for _, g := range gaps {
+			if g < 0 || g+gapWidth > w.Config.Width {
+				t.Fatalf("gap at %g, %g wide, runs off a field %g wide", g, gapWidth, w.Config.Width)
+			}
+		}
+	}
+}
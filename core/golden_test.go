@@ -0,0 +1,94 @@
+package core
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata with the current results")
+
+const (
+	goldenTicks = 6000 // 100 seconds at 60 TPS
+	goldenEvery = 600  // Ticks between checkpoints
+)
+
+// goldenScenarios are scripted players whose runs are pinned down in
+// testdata/golden. A change that alters any of them changes gameplay:
+// regenerate them with go test -run Golden -update, and bump TuningVersion.
+var goldenScenarios = []struct {
+	name   string
+	seed   int64
+	script func(w *World) FrameInput
+}{
+	{"idle", 1, still},
+	{"shooter", 2, shooter},
+	{"wall-hugger", 3, func(w *World) FrameInput { return FrameInput{MoveX: -1} }},
+}
+
+// shooter keeps under the lowest asteroid above the ship and fires at
+// every chance.
+func shooter(w *World) FrameInput {
+	p := &w.Player
+	center := p.X + p.Width/2
+	target, lowest := center, math.Inf(-1)
+	for _, a := range w.Asteroids {
+		if a.Active && a.Y+a.Height < p.Y && a.Y > lowest {
+			target, lowest = a.X+a.Width/2, a.Y
+		}
+	}
+	return FrameInput{MoveX: math.Max(-1, math.Min(1, (target-center)/10)), FirePressed: true, FireHeld: true}
+}
+
+// goldenRun plays a scenario and lists the world's hash, with the score
+// and RNG draws to make diffs readable, every goldenEvery ticks. The ship
+// can't be hit, so every run lasts the same time.
+func goldenRun(seed int64, script func(w *World) FrameInput) string {
+	w := NewWorld(testConfig(), seed)
+	w.Invulnerable = true
+	var b strings.Builder
+	fmt.Fprintln(&b, "# tick score destroyed dodged draws hash")
+	for w.Time < goldenTicks {
+		w.Step(script(w))
+		if w.Time%goldenEvery == 0 {
+			fmt.Fprintf(&b, "%d %d %d %d %d %016x\n", w.Time, w.Score, w.Destroyed, w.Dodged, w.RNG.Draws, w.Hash())
+		}
+	}
+	return b.String()
+}
+
+func TestGolden(t *testing.T) {
+	for _, s := range goldenScenarios {
+		t.Run(s.name, func(t *testing.T) {
+			got := goldenRun(s.seed, s.script)
+			path := filepath.Join("testdata", "golden", s.name+".txt")
+			if *update {
+				if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("%v; run go test -run Golden -update to create it", err)
+			}
+			if got != string(want) {
+				t.Errorf("the %s run no longer matches %s. If the change is meant to alter gameplay, run go test -run Golden -update and bump TuningVersion.\ngot:\n%s\nwant:\n%s", s.name, path, got, want)
+			}
+		})
+	}
+}
+
+func TestGoldenRunsRepeat(t *testing.T) {
+	// The goldens only mean something if a run is the same every time
+	if goldenRun(2, shooter) != goldenRun(2, shooter) {
+		t.Error("the same seed and script gave different runs")
+	}
+}
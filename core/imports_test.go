@@ -0,0 +1,35 @@
+package core
+
+import (
+	"go/build"
+	"strings"
+	"testing"
+)
+
+// TestNoEbiten keeps the simulation free of the engine, so headless tools
+// and servers can use it: neither core nor anything it imports from this
+// module may import Ebiten.
+func TestNoEbiten(t *testing.T) {
+	seen := map[string]bool{}
+	var walk func(path, dir string, from []string)
+	walk = func(path, dir string, from []string) {
+		if seen[path] {
+			return
+		}
+		seen[path] = true
+		pkg, err := build.Import(path, dir, 0)
+		if err != nil {
+			t.Fatalf("importing %s: %v", path, err)
+		}
+		chain := append(from, path)
+		for _, imp := range pkg.Imports {
+			switch {
+			case strings.HasPrefix(imp, "github.com/hajimehoshi/ebiten"):
+				t.Errorf("%s imports %s", strings.Join(chain, " -> "), imp)
+			case strings.HasPrefix(imp, "example/hello/"):
+				walk(imp, dir, chain)
+			}
+		}
+	}
+	walk("example/hello/core", ".", nil)
+}
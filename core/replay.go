@@ -0,0 +1,95 @@
+package core
+
+import "fmt"
+
+// MaxReplayInputs is how many ticks a recording keeps: an hour at 60 TPS.
+const MaxReplayInputs = 60 * 60 * 60
+
+// Replay is everything needed to play a run again: its Config, its seed,
+// and the input for every tick. A run that continued after a hit lists the
+// Time of each continue in Revives.
+type Replay struct {
+	Config  Config       `json:"config"`
+	Seed    int64        `json:"seed"`
+	Inputs  []FrameInput `json:"inputs"`
+	Revives []int        `json:"revives,omitempty"`
+
+	Limit     int  `json:"-"` // Inputs kept while recording; 0 for no limit
+	Truncated bool `json:"-"` // Recording went past Limit, so the trace can't be played
+}
+
+// NewReplay starts recording a run begun with NewWorld(cfg, seed), keeping
+// up to MaxReplayInputs ticks.
+func NewReplay(cfg Config, seed int64) *Replay {
+	return &Replay{Config: cfg, Seed: seed, Limit: MaxReplayInputs}
+}
+
+// Record notes the input about to be passed to w.Step. Once the trace
+// holds Limit inputs it stops growing and is marked Truncated.
+func (r *Replay) Record(w *World, in FrameInput) {
+	if w.GameOver || r.Truncated {
+		return
+	}
+	if r.Limit > 0 && len(r.Inputs) >= r.Limit {
+		r.Truncated, r.Inputs = true, nil // The inputs so far are no use without the rest
+		return
+	}
+	r.Inputs = append(r.Inputs, in)
+}
+
+// RecordRevive notes that w is about to be revived.
+func (r *Replay) RecordRevive(w *World) {
+	r.Revives = append(r.Revives, w.Time)
+}
+
+// Next returns the input for w's next tick, reviving w first if the run
+// continued at this point. ok is false once the trace has no more ticks,
+// or the run is over for good.
+func (r *Replay) Next(w *World) (in FrameInput, ok bool) {
+	if w.GameOver {
+		if !r.revivesAt(w.Time) {
+			return FrameInput{}, false
+		}
+		w.Revive()
+	}
+	if w.Time >= len(r.Inputs) {
+		return FrameInput{}, false
+	}
+	return r.Inputs[w.Time], true
+}
+
+func (r *Replay) revivesAt(t int) bool {
+	for _, at := range r.Revives {
+		if at == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Play runs the whole replay without drawing anything and returns the
+// world it ends with. The trace has to cover the run exactly: one that
+// stops while the ship is still flying, or goes on after the run ended,
+// is an error rather than a result.
+func (r *Replay) Play() (*World, error) {
+	w := NewWorld(r.Config, r.Seed)
+	for {
+		in, ok := r.Next(w)
+		if !ok {
+			break
+		}
+		w.Step(in)
+	}
+	switch {
+	case !w.GameOver:
+		return nil, fmt.Errorf("trace ends at tick %d with the run still going; it is probably truncated", w.Time)
+	case w.Time < len(r.Inputs):
+		return nil, fmt.Errorf("run ended at tick %d but the trace goes on to tick %d", w.Time, len(r.Inputs))
+	}
+	for _, at := range r.Revives {
+		if at > w.Time {
+			return nil, fmt.Errorf("trace continues the run at tick %d, after it ended at tick %d", at, w.Time)
+		}
+	}
+	return w, nil
+}
@@ -0,0 +1,77 @@
+package core
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// recordReplay weaves and shoots from seed until the run ends, continuing
+// once after the first hit, and returns the final world and its replay.
+func recordReplay(t *testing.T, seed int64) (*World, *Replay) {
+	t.Helper()
+	cfg := testConfig()
+	w := NewWorld(cfg, seed)
+	r := NewReplay(cfg, seed)
+	for w.Time < 60*600 {
+		if w.GameOver {
+			if len(r.Revives) > 0 {
+				return w, r
+			}
+			r.RecordRevive(w)
+			w.Revive()
+		}
+		in := FrameInput{MoveX: float64(w.Time/90%2*2 - 1), FirePressed: w.Time%15 == 0}
+		r.Record(w, in)
+		w.Step(in)
+	}
+	t.Fatalf("seed %d: the run was still going after ten minutes", seed)
+	return nil, nil
+}
+
+func TestReplayPlaysTheRunAgain(t *testing.T) {
+	// Seeds past 2^53 don't survive a trip through a float64
+	for _, seed := range []int64{1, 2, 1<<53 + 1, -(1<<62 + 3)} {
+		w, r := recordReplay(t, seed)
+		data, err := json.Marshal(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var loaded Replay
+		if err := json.Unmarshal(data, &loaded); err != nil {
+			t.Fatal(err)
+		}
+		got, err := loaded.Play()
+		if err != nil {
+			t.Fatalf("seed %d: %v", seed, err)
+		}
+		if got.Time != w.Time || got.Score != w.Score || got.Hash() != w.Hash() {
+			t.Errorf("seed %d: replay ended at tick %d on %d points, want tick %d on %d", seed, got.Time, got.Score, w.Time, w.Score)
+		}
+	}
+}
+
+func TestReplayNeedsTheWholeTrace(t *testing.T) {
+	_, r := recordReplay(t, 3)
+	tests := []struct {
+		name   string
+		change func(r *Replay)
+		want   string // Part of the error
+	}{
+		{"truncated", func(r *Replay) { r.Inputs = r.Inputs[:len(r.Inputs)/2] }, "truncated"},
+		{"extended", func(r *Replay) { r.Inputs = append(r.Inputs, make([]FrameInput, 60)...) }, "goes on"},
+		{"continued too late", func(r *Replay) { r.Revives = append(r.Revives, len(r.Inputs)+10) }, "after it ended"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bad := *r
+			bad.Inputs = append([]FrameInput(nil), r.Inputs...)
+			bad.Revives = append([]int(nil), r.Revives...)
+			tt.change(&bad)
+			_, err := bad.Play()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got error %v, want one saying %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package core
+
+import "math/rand"
+
+// RNG is a seeded random source that remembers how many values it has
+// produced, so its exact position survives being saved and loaded.
+type RNG struct {
+	Origin int64  `json:"seed"`  // Seed the sequence started from
+	Draws  uint64 `json:"draws"` // Values taken so far
+
+	src rand.Source64
+}
+
+// source returns the underlying generator, replaying it up to Draws the
+// first time it is needed.
+func (r *RNG) source() rand.Source64 {
+	if r.src == nil {
+		r.src = rand.NewSource(r.Origin).(rand.Source64)
+		for i := uint64(0); i < r.Draws; i++ {
+			r.src.Uint64()
+		}
+	}
+	return r.src
+}
+
+func (r *RNG) Int63() int64 {
+	v := r.source().Int63()
+	r.Draws++
+	return v
+}
+
+func (r *RNG) Uint64() uint64 {
+	v := r.source().Uint64()
+	r.Draws++
+	return v
+}
+
+// Seed restarts the sequence from seed.
+func (r *RNG) Seed(seed int64) {
+	*r = RNG{Origin: seed}
+}
@@ -0,0 +1,71 @@
+package core
+
+import (
+	"encoding/json"
+	"math/rand"
+	"testing"
+)
+
+func TestRNGRestoresDrawCount(t *testing.T) {
+	orig := &RNG{Origin: 99}
+	r := rand.New(orig)
+	for i := 0; i < 1234; i++ {
+		r.Intn(100)
+		r.Float64()
+	}
+
+	data, err := json.Marshal(orig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var restored RNG
+	if err := json.Unmarshal(data, &restored); err != nil {
+		t.Fatal(err)
+	}
+	if restored.Draws != orig.Draws {
+		t.Fatalf("restored %d draws, want %d", restored.Draws, orig.Draws)
+	}
+	r2 := rand.New(&restored)
+	for i := 0; i < 1000; i++ {
+		if a, b := r.Int63(), r2.Int63(); a != b {
+			t.Fatalf("draw %d after restoring: got %d, want %d", i, b, a)
+		}
+	}
+	if restored.Draws != orig.Draws {
+		t.Errorf("restored RNG is at %d draws, the original at %d", restored.Draws, orig.Draws)
+	}
+}
+
+func TestRNGSeedRestarts(t *testing.T) {
+	var a, b RNG
+	a.Seed(5)
+	first := a.Uint64()
+	a.Int63()
+	a.Seed(5)
+	b.Seed(5)
+	if a.Draws != 0 || a.Uint64() != first || b.Uint64() != first {
+		t.Error("seeding again didn't restart the sequence")
+	}
+}
+
+func TestWorldRoundTripsThroughJSON(t *testing.T) {
+	w := NewWorld(testConfig(), 3)
+	for w.Time < 1200 && !w.GameOver {
+		w.Step(BotInput(w, BotSkills[2]))
+	}
+	data, err := json.Marshal(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var w2 World
+	if err := json.Unmarshal(data, &w2); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 1200 && !w.GameOver; i++ {
+		w.Step(BotInput(w, BotSkills[2]))
+		w2.Step(BotInput(&w2, BotSkills[2]))
+		if w.Hash() != w2.Hash() {
+			t.Fatalf("decoded world diverged %d ticks later", i+1)
+		}
+	}
+}
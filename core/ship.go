@@ -0,0 +1,19 @@
+package core
+
+// Ship is how a ship handles. Each one pays for its strengths somewhere
+// else, so none is simply best.
+type Ship struct {
+	Name     string  `json:"name"`
+	Speed    float64 `json:"speed"`    // Multiplier over Tuning.PlayerSpeed
+	FireRate float64 `json:"fireRate"` // Multiplier on how often held fire and stick fire shoot
+	Size     float64 `json:"size"`     // Hitbox width and height in pixels
+}
+
+var Ships = []Ship{
+	{Name: "arrow", Speed: 1.25, FireRate: 0.75, Size: 24}, // Quick and small, but slow to fire
+	{Name: "falcon", Speed: 1, FireRate: 1, Size: 30},      // The all-rounder
+	{Name: "bastion", Speed: 0.8, FireRate: 1.4, Size: 36}, // Fires fast, but big and sluggish
+}
+
+// DefaultShip is flown when the config doesn't pick one.
+var DefaultShip = Ships[1]
@@ -0,0 +1,85 @@
+package core
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// SizeClass is one row of the asteroid size table: asteroids from Min to
+// Max pixels across, picked in proportion to Weight.
+type SizeClass struct {
+	Min    int     `json:"min"`
+	Max    int     `json:"max"`
+	Weight float64 `json:"weight"`
+}
+
+// SizeTable is how often asteroids of each size appear.
+type SizeTable []SizeClass
+
+// Pick rolls a class by weight, then a width within it.
+func (st SizeTable) Pick(r *rand.Rand) float64 {
+	return st.pick(r, func(i int) float64 { return st[i].Weight })
+}
+
+// PickWeighted is Pick with weights, one per class, in place of the
+// table's own.
+func (st SizeTable) PickWeighted(r *rand.Rand, weights []float64) float64 {
+	return st.pick(r, func(i int) float64 { return weights[i] })
+}
+
+func (st SizeTable) pick(r *rand.Rand, weight func(int) float64) float64 {
+	c := st[pickWeighted(r, len(st), weight)]
+	return float64(c.Min + r.Intn(c.Max-c.Min+1))
+}
+
+// pickWeighted returns an index from 0 to n-1, each chosen in proportion to
+// its weight. At least one weight must be positive.
+func pickWeighted(r *rand.Rand, n int, weight func(int) float64) int {
+	total := 0.0
+	for i := 0; i < n; i++ {
+		total += weight(i)
+	}
+	x := r.Float64() * total
+	last := 0
+	for i := 0; i < n; i++ {
+		w := weight(i)
+		if w <= 0 {
+			continue
+		}
+		if x < w {
+			return i
+		}
+		x -= w
+		last = i
+	}
+	return last // Rounding left x just past the end
+}
+
+// validate checks every class fits a playfield whose smaller side is limit
+// pixels, and that some class can be picked.
+func (st SizeTable) validate(limit int) []error {
+	if len(st) == 0 {
+		return []error{fmt.Errorf("asteroidSizes needs at least one class")}
+	}
+	var errs []error
+	total := 0.0
+	for i, c := range st {
+		if c.Min < 1 {
+			errs = append(errs, fmt.Errorf("asteroidSizes[%d].min must be at least 1, got %d", i, c.Min))
+		}
+		if c.Max < c.Min {
+			errs = append(errs, fmt.Errorf("asteroidSizes[%d].max must be at least its min (%d), got %d", i, c.Min, c.Max))
+		}
+		if c.Max > limit {
+			errs = append(errs, fmt.Errorf("asteroidSizes[%d].max must fit the playfield (at most %d), got %d", i, limit, c.Max))
+		}
+		if c.Weight < 0 {
+			errs = append(errs, fmt.Errorf("asteroidSizes[%d].weight can't be negative, got %g", i, c.Weight))
+		}
+		total += max(c.Weight, 0)
+	}
+	if total == 0 {
+		errs = append(errs, fmt.Errorf("asteroidSizes needs a class with a positive weight"))
+	}
+	return errs
+}
@@ -0,0 +1,95 @@
+package core
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// classShares picks n sizes and returns the fraction that fell in each
+// class of st. It fails the test on a size no class covers.
+func classShares(t *testing.T, st SizeTable, n int, pick func(*rand.Rand) float64) []float64 {
+	t.Helper()
+	r := rand.New(rand.NewSource(1))
+	counts := make([]int, len(st))
+	for i := 0; i < n; i++ {
+		size := pick(r)
+		class := -1
+		for j, c := range st {
+			if size >= float64(c.Min) && size <= float64(c.Max) && size == math.Trunc(size) {
+				class = j
+				break
+			}
+		}
+		if class < 0 {
+			t.Fatalf("picked a size of %g, outside every class", size)
+		}
+		counts[class]++
+	}
+	shares := make([]float64, len(st))
+	for j, c := range counts {
+		shares[j] = float64(c) / float64(n)
+	}
+	return shares
+}
+
+func TestSizeDistributionMatchesWeights(t *testing.T) {
+	const n = 100000
+	tables := map[string]SizeTable{
+		"default": DefaultTuning.AsteroidSizes,
+		"mostly medium": {
+			{Min: 10, Max: 14, Weight: 1},
+			{Min: 20, Max: 35, Weight: 7},
+			{Min: 40, Max: 60, Weight: 2},
+		},
+		"a class turned off": {
+			{Min: 10, Max: 14, Weight: 0},
+			{Min: 20, Max: 35, Weight: 0.5},
+			{Min: 40, Max: 60, Weight: 1.5},
+		},
+	}
+	for name, st := range tables {
+		total := 0.0
+		for _, c := range st {
+			total += c.Weight
+		}
+		shares := classShares(t, st, n, st.Pick)
+		for j, c := range st {
+			want := c.Weight / total
+			if math.Abs(shares[j]-want) > 0.01 {
+				t.Errorf("%s: %.1f%% of sizes are %d-%d, want %.1f%%", name, 100*shares[j], c.Min, c.Max, 100*want)
+			}
+		}
+	}
+}
+
+func TestPickWeightedOverridesTheTable(t *testing.T) {
+	st := SizeTable{
+		{Min: 10, Max: 14, Weight: 1},
+		{Min: 20, Max: 35, Weight: 1},
+		{Min: 40, Max: 60, Weight: 1},
+	}
+	weights := []float64{0, 3, 1}
+	shares := classShares(t, st, 100000, func(r *rand.Rand) float64 { return st.PickWeighted(r, weights) })
+	want := []float64{0, 0.75, 0.25}
+	for j := range st {
+		if math.Abs(shares[j]-want[j]) > 0.01 {
+			t.Errorf("class %d got %.1f%% of sizes, want %.1f%%", j, 100*shares[j], 100*want[j])
+		}
+	}
+}
+
+func TestSizesCoverEachClass(t *testing.T) {
+	// Every width of a class turns up, the bounds included
+	st := SizeTable{{Min: 20, Max: 29, Weight: 1}}
+	r := rand.New(rand.NewSource(1))
+	seen := map[float64]bool{}
+	for i := 0; i < 1000; i++ {
+		seen[st.Pick(r)] = true
+	}
+	for size := 20; size <= 29; size++ {
+		if !seen[float64(size)] {
+			t.Errorf("a %d-wide asteroid never came up", size)
+		}
+	}
+}
@@ -0,0 +1,265 @@
+package core
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSpawnsAvoidSafeZone(t *testing.T) {
+	zone := DefaultTuning.SpawnSafeZone
+	tests := []struct {
+		name string
+		mode Mode
+		wrap bool
+		x, y float64 // The ship
+	}{
+		{"top middle", ModeEndless, false, 305, 0},
+		{"top left corner", ModeEndless, false, 0, 0},
+		{"top right corner", ModeEndless, false, 610, 0},
+		{"straddling the wrap seam", ModeEndless, true, 625, 0},
+		{"twin-stick left edge", ModeTwinStick, false, 0, 225},
+		{"twin-stick bottom right", ModeTwinStick, false, 610, 450},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.Mode, cfg.Wrap = tt.mode, tt.wrap
+			w := quietWorld(cfg)
+			p := &w.Player
+			p.X, p.Y = tt.x, tt.y
+			spawned := 0
+			for i := 0; i < 2000; i++ {
+				w.Asteroids = w.Asteroids[:0]
+				w.spawnAsteroid()
+				if len(w.Asteroids) == 0 {
+					continue // Gave up after SpawnRetries
+				}
+				spawned++
+				a := w.Asteroids[0]
+				for _, px := range w.PlayerCopies(p.X) {
+					if isColliding(px-zone, p.Y-zone, p.Width+2*zone, p.Height+2*zone, a.X, a.Y, a.Width, a.Height) {
+						t.Fatalf("asteroid spawned at (%g, %g), %g across, inside the safe zone of a ship at (%g, %g)", a.X, a.Y, a.Width, px, p.Y)
+					}
+				}
+			}
+			// Retrying finds somewhere safe nearly every time
+			if spawned < 1800 {
+				t.Errorf("only %d of 2000 spawns happened", spawned)
+			}
+		})
+	}
+}
+
+func TestSpawnsGiveUpWhenNowhereIsSafe(t *testing.T) {
+	cfg := testConfig()
+	cfg.Tuning = DefaultTuning
+	cfg.Tuning.SpawnSafeZone = 1000 // Covers the whole field
+	w := quietWorld(cfg)
+	w.Player.Y = 0
+	for i := 0; i < 100; i++ {
+		w.spawnAsteroid()
+	}
+	if len(w.Asteroids) != 0 {
+		t.Errorf("%d asteroids spawned with the whole field in the safe zone", len(w.Asteroids))
+	}
+}
+
+// rockKey is an asteroid's place and make, which match between copies of
+// a shared field even where their IDs don't.
+type rockKey struct {
+	x, y, width, speed, vx float64
+}
+
+func rocks(w *World) map[rockKey]bool {
+	m := make(map[rockKey]bool)
+	for _, a := range w.Asteroids {
+		m[rockKey{a.X, a.Y, a.Width, a.Speed, a.VX}] = true
+	}
+	return m
+}
+
+func TestSharedFieldIgnoresTheShip(t *testing.T) {
+	// One ship hugs the top left corner, where fair spawning, the safe
+	// zone and formations would all steer around it. The other sits still
+	// and shoots, so its field has asteroids missing
+	hugger := func(w *World) FrameInput { return FrameInput{MoveX: -1, MoveY: -1} }
+	shooter := func(w *World) FrameInput { return FrameInput{FirePressed: w.Time%10 == 0} }
+
+	diverged := func(shared bool) (tick int, ok bool) {
+		cfg := testConfig()
+		cfg.SpawnPolicy = SpawnFair
+		cfg.SharedField = shared
+		a, b := NewWorld(cfg, 11), NewWorld(cfg, 11)
+		a.Invulnerable, b.Invulnerable = true, true
+		for a.Time < 6000 {
+			a.Step(hugger(a))
+			b.Step(shooter(b))
+			all := rocks(a)
+			for k := range rocks(b) {
+				if !all[k] {
+					return a.Time, true
+				}
+			}
+		}
+		return 0, false
+	}
+
+	if tick, ok := diverged(true); ok {
+		t.Errorf("shared fields diverged at tick %d", tick)
+	}
+	// Make sure the ships really would have changed an unshared field
+	if _, ok := diverged(false); !ok {
+		t.Error("unshared fields never diverged, so the test proves nothing")
+	}
+}
+
+func TestFairSpawnsAvoidAPinnedShip(t *testing.T) {
+	const samples, width = 20000, 40
+	// underShip is the share of spawns that would fall on the ship's column
+	underShip := func(policy SpawnPolicy, x float64) float64 {
+		cfg := testConfig()
+		cfg.SpawnPolicy = policy
+		w := quietWorld(cfg)
+		p := &w.Player
+		p.X = x
+		hits := 0
+		for i := 0; i < samples; i++ {
+			if sx := w.spawnX(width); sx < p.X+p.Width && sx+width > p.X {
+				hits++
+			}
+		}
+		return float64(hits) / samples
+	}
+	for _, x := range []float64{0, 15, 640 - 30} {
+		uniform, fair := underShip(SpawnUniform, x), underShip(SpawnFair, x)
+		// Most spawns that would land on the ship move, but not all of
+		// them: a pinned ship still has to dodge
+		if want := (1 - FairBias) * uniform; fair < want*0.8 || fair > want*1.2 {
+			t.Errorf("ship at %g: %.2f%% of fair spawns fall on it, want about %.2f%% (uniform %.2f%%)",
+				x, 100*fair, 100*want, 100*uniform)
+		}
+	}
+}
+
+func TestFairSpawnsLeaveAFreeShipAlone(t *testing.T) {
+	xs := func(policy SpawnPolicy) []float64 {
+		cfg := testConfig()
+		cfg.SpawnPolicy = policy
+		w := quietWorld(cfg)
+		w.Player.X = 300
+		var xs []float64
+		for i := 0; i < 1000; i++ {
+			xs = append(xs, w.spawnX(40))
+		}
+		return xs
+	}
+	if !slices.Equal(xs(SpawnFair), xs(SpawnUniform)) {
+		t.Error("fair spawning moved asteroids away from a ship in the open")
+	}
+}
+
+func TestFairSpawnsSpreadOut(t *testing.T) {
+	// Spawns moved off a pinned ship spread over the rest of the field
+	// rather than piling up beside it
+	cfg := testConfig()
+	cfg.SpawnPolicy = SpawnFair
+	w := quietWorld(cfg)
+	w.Player.X = 0
+	const buckets, samples = 8, 40000
+	var counts [buckets]int
+	n := w.Config.Width - 40
+	for i := 0; i < samples; i++ {
+		counts[int(w.spawnX(40)/n*buckets)]++
+	}
+	// Leave out the ship's bucket and the one beside it
+	lo, hi := counts[2], counts[2]
+	for _, c := range counts[2:] {
+		lo, hi = min(lo, c), max(hi, c)
+	}
+	if float64(hi)/float64(lo) > 1.15 {
+		t.Errorf("spawns away from the ship are uneven: %v", counts)
+	}
+}
+
+// cadenceWorld is an endless world whose spawns are all lone asteroids,
+// starting with or without the opening grace period.
+func cadenceWorld(seed int64, grace bool) *World {
+	cfg := testConfig()
+	cfg.Tuning = DefaultTuning
+	cfg.Tuning.EndlessCurve = slices.Clone(DefaultTuning.EndlessCurve)
+	for i := range cfg.Tuning.EndlessCurve {
+		cfg.Tuning.EndlessCurve[i].Formations = 0
+	}
+	if !grace {
+		cfg.Tuning.StartGrace = 0
+	}
+	return NewWorld(cfg, seed)
+}
+
+func TestSpawnCadence(t *testing.T) {
+	tests := []struct {
+		name   string
+		grace  bool
+		spawns []int // Ticks on which asteroids spawn
+	}{
+		// One spawnInterval apart, the first a full interval in
+		{"no grace", false, []int{60, 120, 180, 240}},
+		{"after the grace period", true, []int{180, 240, 300, 360}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := cadenceWorld(1, tt.grace)
+			w.Invulnerable = true
+			var got []int
+			for w.Time < tt.spawns[len(tt.spawns)-1] {
+				before := w.NextID
+				w.Step(FrameInput{})
+				if w.NextID != before {
+					got = append(got, w.Time)
+				}
+				if w.Time < tt.spawns[0] && w.RNG.Draws != 0 {
+					t.Fatalf("%d RNG draws by tick %d, before any spawn", w.RNG.Draws, w.Time)
+				}
+			}
+			if !slices.Equal(got, tt.spawns) {
+				t.Errorf("asteroids spawned on ticks %v, want %v", got, tt.spawns)
+			}
+		})
+	}
+}
+
+func TestFirstSpawnComesFromTheSeed(t *testing.T) {
+	first := func(seed int64) Asteroid {
+		w := cadenceWorld(seed, false)
+		for w.Time < 59 {
+			w.Step(FrameInput{})
+		}
+		if len(w.Asteroids) != 0 {
+			t.Fatalf("seed %d: an asteroid spawned before tick 60", seed)
+		}
+		w.Step(FrameInput{})
+		if len(w.Asteroids) != 1 {
+			t.Fatalf("seed %d: %d asteroids at tick 60, want 1", seed, len(w.Asteroids))
+		}
+		return w.Asteroids[0]
+	}
+	sizes := DefaultTuning.AsteroidSizes
+	lo, hi := float64(sizes[0].Min), float64(sizes[len(sizes)-1].Max)
+	seen := make(map[[2]float64]bool)
+	for seed := int64(1); seed <= 20; seed++ {
+		a := first(seed)
+		if a.Width < lo || a.Width > hi || a.Height != a.Width {
+			t.Errorf("seed %d: asteroid is %gx%g, want a square from %g to %g", seed, a.Width, a.Height, lo, hi)
+		}
+		if a.X < 0 || a.X+a.Width > 640 {
+			t.Errorf("seed %d: asteroid at x %g, %g wide, is off the field", seed, a.X, a.Width)
+		}
+		if again := first(seed); again.X != a.X || again.Width != a.Width {
+			t.Errorf("seed %d: first asteroid was %g wide at %g, then %g wide at %g", seed, a.Width, a.X, again.Width, again.X)
+		}
+		seen[[2]float64{a.X, a.Width}] = true
+	}
+	if len(seen) < 15 {
+		t.Errorf("20 seeds gave only %d different first asteroids", len(seen))
+	}
+}
@@ -0,0 +1,47 @@
+package core
+
+// SpawnPolicy picks where lone asteroids spawn across the top of the field.
+// Formations place themselves and ignore it.
+type SpawnPolicy int
+
+const (
+	SpawnUniform SpawnPolicy = iota // Anywhere, with equal chance
+	SpawnFair                       // Mostly away from a ship pinned against a wall; uniform on a shared field
+)
+
+const (
+	CornerReach = 1.0  // Ship widths from a wall within which the ship counts as pinned
+	FairBias    = 0.75 // Chance a spawn is moved out of a pinned ship's column
+)
+
+// spawnX picks the left edge of a lone asteroid width wide.
+func (w *World) spawnX(width float64) float64 {
+	r := w.rand()
+	n := int(w.Config.Width) - int(width)
+	if w.Config.SpawnPolicy != SpawnFair || w.Config.SharedField || !w.cornered() || r.Float64() >= FairBias {
+		return float64(r.Intn(n))
+	}
+
+	// Pick uniformly among the positions that miss the ship's column,
+	// widened by half a ship either side
+	p := &w.Player
+	lo := max(0, int(p.X-p.Width/2-width)+1)
+	hi := min(n-1, int(p.X+p.Width*1.5))
+	if hi < lo || hi-lo+1 >= n {
+		return float64(r.Intn(n))
+	}
+	x := r.Intn(n - (hi - lo + 1))
+	if x >= lo {
+		x += hi - lo + 1
+	}
+	return float64(x)
+}
+
+// cornered reports whether the ship is within CornerReach of a side wall,
+// with little room to get out from under something falling on it. A
+// wrapping field has no walls.
+func (w *World) cornered() bool {
+	p := &w.Player
+	reach := CornerReach * p.Width
+	return !w.Config.Wrap && (p.X < reach || p.X+p.Width > w.Config.Width-reach)
+}
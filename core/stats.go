@@ -0,0 +1,53 @@
+package core
+
+import "math"
+
+const (
+	MinFireTicks  = 1 // No combination of modifiers may fire more than once a tick
+	MaxSpeedScale = 3 // Effective player speed is capped at this multiple of Tuning.PlayerSpeed
+)
+
+// Stats are the player's effective numbers for one tick, with every
+// modifier applied. Step reads these rather than doing its own math.
+type Stats struct {
+	PlayerSpeed    float64  `json:"playerSpeed"`    // Pixels per second
+	BulletSpeed    float64  `json:"bulletSpeed"`    // Pixels per second
+	Muzzles        []Muzzle `json:"muzzles"`        // Where each bullet of a shot leaves the ship
+	AutoFireTicks  int      `json:"autoFireTicks"`  // Ticks between shots while fire is held; 0 means press-to-fire only
+	AimedFireTicks int      `json:"aimedFireTicks"` // Ticks between aimed shots when there is no auto-fire
+}
+
+// ResolveStats works out the effective stats from the base tuning, then the
+// player's option scales, then the ship, then the weapon upgrade. Anything
+// new that changes these belongs here, in that order, so stacking is in one
+// place. The result is clamped: cooldowns never drop below MinFireTicks and
+// speed never passes MaxSpeedScale.
+func ResolveStats(cfg Config, weaponLevel int) Stats {
+	t := cfg.Tuning
+	ticks := func(seconds float64) int {
+		return int(math.Round(seconds * float64(cfg.TPS) / cfg.timeScale()))
+	}
+	weapon := weaponLevels[max(0, min(weaponLevel, len(weaponLevels)-1))]
+
+	s := Stats{
+		PlayerSpeed: t.PlayerSpeed * cfg.PlayerSpeedScale * cfg.Ship.Speed,
+		BulletSpeed: t.BulletSpeed * cfg.BulletSpeedScale,
+		Muzzles:     weapon.muzzles,
+	}
+	s.AimedFireTicks = ticks(t.StickFireDelay / cfg.Ship.FireRate)
+	if weapon.autoFireDelay > 0 {
+		s.AutoFireTicks = ticks(weapon.autoFireDelay / cfg.Ship.FireRate)
+	}
+
+	s.PlayerSpeed = math.Min(s.PlayerSpeed, t.PlayerSpeed*MaxSpeedScale)
+	s.AimedFireTicks = max(s.AimedFireTicks, MinFireTicks)
+	if weapon.autoFireDelay > 0 {
+		s.AutoFireTicks = max(s.AutoFireTicks, MinFireTicks)
+	}
+	return s
+}
+
+// Stats are the player's effective stats right now.
+func (w *World) Stats() Stats {
+	return ResolveStats(w.Config, w.WeaponLevel)
+}
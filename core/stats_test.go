@@ -0,0 +1,74 @@
+package core
+
+import "testing"
+
+func TestResolveStats(t *testing.T) {
+	arrow, falcon, bastion := Ships[0], Ships[1], Ships[2]
+	tests := []struct {
+		name    string
+		cfg     func(*Config)
+		weapon  int
+		speed   float64
+		bullet  float64
+		muzzles int
+		auto    int
+		aimed   int
+	}{
+		{name: "base", weapon: 0, speed: 300, bullet: 420, muzzles: 1, auto: 0, aimed: 15},
+		{name: "twin guns still press to fire", weapon: 1, speed: 300, bullet: 420, muzzles: 2, auto: 0, aimed: 15},
+		{name: "auto-fire", weapon: 2, speed: 300, bullet: 420, muzzles: 2, auto: 12, aimed: 15},
+		{
+			name: "quick ship fires slower", cfg: func(c *Config) { c.Ship = arrow },
+			weapon: 2, speed: 375, bullet: 420, muzzles: 2, auto: 16, aimed: 20,
+		},
+		{
+			name: "slow ship fires faster", cfg: func(c *Config) { c.Ship = bastion },
+			weapon: 3, speed: 240, bullet: 420, muzzles: 3, auto: 6, aimed: 11,
+		},
+		{
+			name: "speed options stack with the ship", cfg: func(c *Config) { c.Ship, c.PlayerSpeedScale, c.BulletSpeedScale = arrow, 2, 1.5 },
+			weapon: 0, speed: 750, bullet: 630, muzzles: 1, auto: 0, aimed: 20,
+		},
+		{
+			name: "speed is capped", cfg: func(c *Config) { c.Ship, c.PlayerSpeedScale = arrow, 3 },
+			weapon: 0, speed: 300 * MaxSpeedScale, bullet: 420, muzzles: 1, auto: 0, aimed: 20,
+		},
+		{
+			name: "half speed takes twice the ticks", cfg: func(c *Config) { c.TimeScale = 0.5 },
+			weapon: 2, speed: 300, bullet: 420, muzzles: 2, auto: 24, aimed: 30,
+		},
+		{
+			name: "low tick rate never reaches a zero cooldown", cfg: func(c *Config) { c.TPS, c.Ship = 5, bastion },
+			weapon: 3, speed: 240, bullet: 420, muzzles: 3, auto: MinFireTicks, aimed: 1,
+		},
+		{
+			name: "absurd fire rate never reaches a zero cooldown", cfg: func(c *Config) { c.Ship = Ship{Speed: 1, FireRate: 100, Size: 30} },
+			weapon: 3, speed: 300, bullet: 420, muzzles: 3, auto: MinFireTicks, aimed: MinFireTicks,
+		},
+		{
+			name: "levels below the first use the first", cfg: func(c *Config) { c.Ship = falcon },
+			weapon: -1, speed: 300, bullet: 420, muzzles: 1, auto: 0, aimed: 15,
+		},
+		{
+			name:   "levels past the last use the last",
+			weapon: 99, speed: 300, bullet: 420, muzzles: 3, auto: 8, aimed: 15,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			if tt.cfg != nil {
+				tt.cfg(&cfg)
+			}
+			// NewWorld fills in the defaults
+			s := ResolveStats(NewWorld(cfg, 1).Config, tt.weapon)
+			if s.PlayerSpeed != tt.speed || s.BulletSpeed != tt.bullet || len(s.Muzzles) != tt.muzzles ||
+				s.AutoFireTicks != tt.auto || s.AimedFireTicks != tt.aimed {
+				t.Errorf("got speed %g, bullets %g, %d muzzles, auto-fire every %d, aimed every %d\n"+
+					"want speed %g, bullets %g, %d muzzles, auto-fire every %d, aimed every %d",
+					s.PlayerSpeed, s.BulletSpeed, len(s.Muzzles), s.AutoFireTicks, s.AimedFireTicks,
+					tt.speed, tt.bullet, tt.muzzles, tt.auto, tt.aimed)
+			}
+		})
+	}
+}
@@ -0,0 +1,554 @@
+package core
+
+import "math"
+
+// FrameInput is what the player asked for during one tick.
+type FrameInput struct {
+	MoveX float64 `json:"moveX,omitempty"` // Desired direction, each axis from -1 to 1
+	MoveY float64 `json:"moveY,omitempty"`
+
+	FirePressed bool `json:"firePressed,omitempty"` // Fire was pressed this tick: shoot straight up
+	FireHeld    bool `json:"fireHeld,omitempty"`    // Fire is held: keep shooting up if the weapon auto-fires
+
+	Aiming bool    `json:"aiming,omitempty"` // Shoot along (AimX, AimY) whenever the gun is ready
+	AimX   float64 `json:"aimX,omitempty"`   // Unit direction
+	AimY   float64 `json:"aimY,omitempty"`
+}
+
+type EventKind int
+
+const (
+	EventDodged       EventKind = iota // A threatening asteroid passed the player
+	EventDestroyed                     // A bullet destroyed an asteroid
+	EventWeaponUp                      // The weapon reached Level
+	EventPlayerHit                     // An asteroid hit the player and the run is over
+	EventWaveStarted                   // Stage mode: wave Level, counted from 1, is on its way
+	EventStageCleared                  // Stage mode: the last wave is gone and the run is won
+	EventHit                           // A bullet dealt Amount damage at (X, Y) with weapon Level
+	EventFired                         // A bullet left the muzzle at (X, Y)
+)
+
+// Event reports something that happened during a Step.
+type Event struct {
+	Kind  EventKind
+	Level int // Weapon level for EventWeaponUp and EventHit or wave for EventWaveStarted, counted from 1
+
+	X, Y   float64 // EventHit: where the bullet struck; EventFired: the muzzle
+	Amount int     // EventHit: damage dealt
+}
+
+// Step advances the world by one tick. The returned events are only valid
+// until the next call. Once the game is over, Step does nothing.
+//
+// Each tick runs in a fixed order of phases: input, spawn, move, bullet
+// hits, player hits, exits, cleanup. Hits are resolved before exits, so an
+// asteroid leaving the field on the tick a bullet strikes it counts as a
+// kill, not a dodge. Each asteroid is consumed at most once per tick.
+func (w *World) Step(in FrameInput) []Event {
+	w.events = w.events[:0]
+	if w.GameOver {
+		return nil
+	}
+	w.Time++
+	w.TicksSinceKill++
+	w.storePreviousPositions()
+
+	w.applyInput(in)
+	w.spawn()
+	w.move()
+	w.resolveBulletHits()
+	w.resolvePlayerHits()
+	w.scoreExits()
+	w.cleanUpObjects()
+	w.checkCleared()
+	return w.events
+}
+
+// applyInput moves the ship and fires its gun.
+func (w *World) applyInput(in FrameInput) {
+	dt := w.Config.timeScale() / float64(w.Config.TPS)
+	p := &w.Player
+
+	stats := w.Stats()
+	if w.Config.Inertia {
+		w.steer(in, stats.PlayerSpeed, dt)
+	} else {
+		p.VX, p.VY = in.MoveX*stats.PlayerSpeed, in.MoveY*stats.PlayerSpeed
+	}
+	p.X += p.VX * dt
+	p.Y += p.VY * dt
+	// Keep the ship inside the playfield. Hitting an edge stops it
+	if w.Config.Wrap {
+		p.X = w.WrapX(p.X)
+	} else if x := math.Max(0, math.Min(p.X, w.Config.Width-p.Width)); x != p.X {
+		p.X, p.VX = x, 0
+	}
+	if y := math.Max(0, math.Min(p.Y, w.Config.Height-p.Height)); y != p.Y {
+		p.Y, p.VY = y, 0
+	}
+
+	// A press while the gun is cooling down waits for it, briefly, and
+	// any shot uses it up
+	if in.FirePressed {
+		w.FireQueuedUntil = w.Time + w.Ticks(FireBuffer)
+	}
+	queued := w.Time <= w.FireQueuedUntil
+	ready := w.Time >= w.FireReadyAt
+
+	// Twin-stick aims every shot, so there the front end holds the aim
+	// for auto-fire instead
+	autoFire := w.Config.AutoFire && w.Config.Mode != ModeTwinStick
+	switch {
+	case ready && (queued || stats.AutoFireTicks > 0 && (in.FireHeld || autoFire)):
+		w.shoot(stats, 0, -1)
+		w.FireQueuedUntil = 0
+	case autoFire && ready:
+		// Press-to-fire weapons auto-fire at the aimed rate
+		w.shoot(stats, 0, -1)
+		w.FireReadyAt = w.Time + stats.AimedFireTicks
+	}
+	if in.Aiming && w.Time >= w.FireReadyAt {
+		w.shoot(stats, in.AimX, in.AimY)
+		if stats.AutoFireTicks == 0 {
+			w.FireReadyAt = w.Time + stats.AimedFireTicks
+		}
+	}
+}
+
+// steer eases the ship's velocity toward the steering for inertial
+// movement: up to speed at the tuning's acceleration while steered, and
+// down to a stop at its friction once let go.
+func (w *World) steer(in FrameInput, speed, dt float64) {
+	p := &w.Player
+	tx, ty := in.MoveX*speed, in.MoveY*speed
+	rate := w.Config.Tuning.PlayerAccel
+	if tx == 0 && ty == 0 {
+		rate = w.Config.Tuning.PlayerFriction
+	}
+	dx, dy := tx-p.VX, ty-p.VY
+	if d := math.Hypot(dx, dy); d > rate*dt {
+		dx, dy = dx/d*rate*dt, dy/d*rate*dt
+	}
+	p.VX += dx
+	p.VY += dy
+	// Never faster than the ship can go, even as its speed changes
+	if v := math.Hypot(p.VX, p.VY); v > speed {
+		p.VX, p.VY = p.VX/v*speed, p.VY/v*speed
+	}
+}
+
+// spawn adds asteroids when they are due, skipping the spawn when the
+// field is full.
+func (w *World) spawn() {
+	if w.HoldSpawns || w.InGrace() || w.Time < w.NextSpawn {
+		return
+	}
+	switch w.Config.Mode {
+	case ModeStage:
+		w.spawnWaveAsteroid()
+	default:
+		w.NextSpawn += w.Ticks(w.spawnInterval())
+		if w.roomToSpawn() && !w.maybeSpawnFormation() {
+			w.spawnAsteroid()
+		}
+	}
+}
+
+// move advances bullets and asteroids. Bullets that leave the field are
+// dropped; asteroids that leave it are left for scoreExits.
+func (w *World) move() {
+	dt := w.Config.timeScale() / float64(w.Config.TPS)
+	for i := range w.Bullets {
+		b := &w.Bullets[i]
+		if !b.Active {
+			continue
+		}
+		b.X += b.VX * dt
+		b.Y += b.VY * dt
+		if w.Config.Wrap {
+			b.X = w.WrapX(b.X)
+		}
+		if w.leftField(b.X, b.Y, BulletWidth, BulletHeight) {
+			b.Active = false
+		}
+	}
+
+	for i := range w.Asteroids {
+		a := &w.Asteroids[i]
+		if a.Active {
+			a.X += a.VX * dt
+			a.Y += a.Speed * dt
+			if w.entered(a) && w.threatens(a) {
+				a.Threatened = true
+			}
+		}
+	}
+}
+
+// resolveBulletHits damages every asteroid a bullet touches, destroying
+// the ones it takes the last hit point from. The bullet is used up.
+func (w *World) resolveBulletHits() {
+	if w.broad == nil {
+		w.broad = newBroadPhase(w.Config.BroadPhase)
+	}
+	w.broad.build(w.Asteroids)
+	for i := range w.Bullets {
+		b := &w.Bullets[i]
+		if !b.Active {
+			continue
+		}
+		w.candidates = w.broad.query(w.candidates[:0], b.X, b.Y, BulletWidth, BulletHeight)
+		for _, j := range w.candidates {
+			a := &w.Asteroids[j]
+			if !a.Active {
+				continue
+			}
+			if px, py, ok := penetration(b.X, b.Y, BulletWidth, BulletHeight, a.X, a.Y, a.Width, a.Height); ok {
+				b.Active = false
+				damage := hitDamage(b, a, px, py)
+				a.HP -= damage
+				w.emit(Event{Kind: EventHit, Level: w.WeaponLevel + 1, X: b.X + BulletWidth/2, Y: b.Y, Amount: damage})
+				if a.HP > 0 {
+					continue
+				}
+				a.Active = false
+				w.addPoints(float64(w.Config.Tuning.DestroyScore))
+				w.Destroyed++
+				w.TicksSinceKill = 0
+				w.emit(Event{Kind: EventDestroyed})
+				w.addWeaponKill()
+			}
+		}
+	}
+}
+
+// resolvePlayerHits ends the run if an asteroid still in play touches the
+// ship. Asteroids destroyed this tick are already gone.
+func (w *World) resolvePlayerHits() {
+	if w.Invulnerable || w.Shielded() || w.InGrace() {
+		return
+	}
+	p := &w.Player
+	for _, px := range w.PlayerCopies(p.X) {
+		hx, hy, hw, hh := w.playerHitbox(px)
+		w.candidates = w.broad.query(w.candidates[:0], hx, hy, hw, hh)
+		for _, i := range w.candidates {
+			a := &w.Asteroids[i]
+			if a.Active && isColliding(hx, hy, hw, hh, a.X, a.Y, a.Width, a.Height) {
+				if !w.GameOver {
+					killer := *a
+					w.Killer = &killer
+				}
+				w.GameOver = true
+			}
+		}
+	}
+	if w.GameOver {
+		w.emit(Event{Kind: EventPlayerHit})
+	}
+}
+
+// playerHitbox is the part of the ship at x that asteroids can hit: all
+// of it, or its middle with the SmallHitbox assist.
+func (w *World) playerHitbox(x float64) (hx, hy, hw, hh float64) {
+	p := &w.Player
+	if !w.Config.SmallHitbox {
+		return x, p.Y, p.Width, p.Height
+	}
+	hw, hh = p.Width*HitboxAssistScale, p.Height*HitboxAssistScale
+	return x + (p.Width-hw)/2, p.Y + (p.Height-hh)/2, hw, hh
+}
+
+// scoreExits retires asteroids that crossed the field and left by the far
+// edge. Only ones that threatened the ship score as dodged, so camping in
+// a far corner earns nothing.
+func (w *World) scoreExits() {
+	for i := range w.Asteroids {
+		a := &w.Asteroids[i]
+		if !a.Active || !w.exited(a) {
+			continue
+		}
+		a.Active = false
+		if a.Threatened {
+			if !w.Config.NoDodgeScore {
+				w.addDodgeScore()
+			}
+			w.Dodged++
+			w.emit(Event{Kind: EventDodged})
+		}
+	}
+}
+
+// checkCleared ends a stage once its last wave has come and gone.
+func (w *World) checkCleared() {
+	if w.Config.Mode == ModeStage && !w.GameOver && w.Wave == StageWaves && len(w.Asteroids) == 0 {
+		w.GameOver = true
+		w.Cleared = true
+		w.emit(Event{Kind: EventStageCleared})
+	}
+}
+
+func (w *World) emit(e Event) {
+	w.events = append(w.events, e)
+}
+
+// leftField reports whether a rect is wholly outside the field. Entities
+// are only removed once nothing of them can still be seen.
+func (w *World) leftField(x, y, width, height float64) bool {
+	return y+height < 0 || w.leftBottom(y) || x+width < 0 || x > w.Config.Width
+}
+
+// leftBottom reports whether something whose top edge is at y is wholly
+// below the field.
+func (w *World) leftBottom(y float64) bool {
+	return y > w.Config.Height
+}
+
+// storePreviousPositions snapshots positions before a tick moves anything.
+func (w *World) storePreviousPositions() {
+	w.Player.PrevX, w.Player.PrevY = w.Player.X, w.Player.Y
+	for i := range w.Bullets {
+		w.Bullets[i].PrevX, w.Bullets[i].PrevY = w.Bullets[i].X, w.Bullets[i].Y
+	}
+	for i := range w.Asteroids {
+		w.Asteroids[i].PrevX, w.Asteroids[i].PrevY = w.Asteroids[i].X, w.Asteroids[i].Y
+	}
+}
+
+// roomToSpawn reports whether a spawn is due to be rolled. A full field
+// skips it, except a shared field, which rolls every spawn so its RNG
+// keeps step with the other copies however many asteroids each has shot;
+// what doesn't fit is dropped once rolled.
+func (w *World) roomToSpawn() bool {
+	return w.Config.SharedField || len(w.Asteroids) < w.Config.MaxAsteroids
+}
+
+// spawnAsteroid adds an asteroid above the playfield, retrying a few times
+// if it would start inside the safety zone around the player. A shared
+// field has no safety zone, since it has no one ship to keep it around.
+func (w *World) spawnAsteroid() {
+	width := w.asteroidSize()
+	x, y, from := w.spawnPoint(width)
+	for try := 1; !w.Config.SharedField && w.inSpawnSafeZone(x, y, width, width); try++ {
+		if try == SpawnRetries {
+			return
+		}
+		x, y, from = w.spawnPoint(width)
+	}
+	a := w.newAsteroid(x, width)
+	a.enterFrom(from, x, y)
+	if len(w.Asteroids) < w.Config.MaxAsteroids {
+		w.Asteroids = append(w.Asteroids, a)
+	}
+}
+
+// spawnWaveAsteroid spawns the next asteroid of the current wave, moving
+// on to the next wave after a break once this one is complete. A practice
+// run has no next wave.
+func (w *World) spawnWaveAsteroid() {
+	if w.Wave == StageWaves {
+		return
+	}
+	w.NextSpawn += w.Ticks(w.spawnInterval() / (1 + WaveSpeedup*float64(w.Wave)))
+	if w.roomToSpawn() && !w.maybeSpawnFormation() {
+		w.spawnAsteroid()
+	}
+	w.WaveSpawned++
+	if w.WaveSpawned < w.waveSize() {
+		return
+	}
+	w.Wave++
+	w.WaveSpawned = 0
+	if w.Config.PracticeWave > 0 {
+		w.Wave = StageWaves
+	}
+	if w.Wave < StageWaves {
+		w.NextSpawn = w.Time + w.Ticks(WaveBreak)
+		w.emit(Event{Kind: EventWaveStarted, Level: w.Wave + 1})
+	}
+}
+
+// newAsteroid returns an asteroid just above the top of the playfield.
+func (w *World) newAsteroid(x, width float64) Asteroid {
+	return Asteroid{
+		ID:     w.newID(),
+		X:      x,
+		Y:      -width,
+		PrevX:  x,
+		PrevY:  -width,
+		Width:  width,
+		Height: width,
+		Speed:  w.asteroidSpeed(),
+		Active: true,
+		HP:     asteroidHP(width),
+		Shape:  w.asteroidShape(width / 2),
+	}
+}
+
+// asteroidHP is how many hit points an asteroid of the given width starts
+// with: one for a small asteroid, or enough that only a dead-center hit on
+// a large one destroys it outright.
+func asteroidHP(width float64) int {
+	if width < CenterHitMinSize {
+		return 1
+	}
+	return MaxHitDamage
+}
+
+// asteroidShape builds a jagged circle of the given radius.
+func (w *World) asteroidShape(radius float64) []Point {
+	shape := make([]Point, AsteroidPoints)
+	for i := range shape {
+		angle := 2 * math.Pi * float64(i) / AsteroidPoints
+		r := radius * (1 - AsteroidJaggedness*w.rand().Float64())
+		shape[i] = Point{X: r * math.Cos(angle), Y: r * math.Sin(angle)}
+	}
+	return shape
+}
+
+func (w *World) shoot(stats Stats, dx, dy float64) {
+	speed := stats.BulletSpeed
+	for _, m := range stats.Muzzles {
+		mx, my := w.muzzlePoint(m, dx, dy)
+		w.emit(Event{Kind: EventFired, X: mx, Y: my})
+		x, y := mx-BulletWidth/2, my
+		if w.Config.Wrap {
+			x = w.WrapX(x)
+		}
+		if len(w.Bullets) >= w.Config.MaxBullets {
+			// Bullets are kept oldest first
+			w.Bullets = append(w.Bullets[:0], w.Bullets[1:]...)
+		}
+		w.Bullets = append(w.Bullets, Bullet{
+			ID:     w.newID(),
+			X:      x,
+			Y:      y,
+			PrevX:  x,
+			PrevY:  y,
+			VX:     dx * speed,
+			VY:     dy * speed,
+			Active: true,
+		})
+	}
+	w.FireReadyAt = w.Time + stats.AutoFireTicks
+}
+
+// muzzlePoint resolves a muzzle against the ship as it is now, firing
+// toward (dx, dy): from the middle of the edge facing that way, sideways
+// along the edge and back into the ship.
+func (w *World) muzzlePoint(m Muzzle, dx, dy float64) (x, y float64) {
+	p := &w.Player
+	x = p.X + p.Width/2 + dx*p.Width/2
+	y = p.Y + p.Height/2 + dy*p.Height/2
+	across, back := m.Across*p.Width, m.Back*p.Height
+	return x - dy*across - dx*back, y + dx*across - dy*back
+}
+
+// addDodgeScore awards a dodge at the current multiplier.
+func (w *World) addDodgeScore() {
+	w.addPoints(float64(w.Config.Tuning.DodgeScore) * w.ScoreMultiplier())
+}
+
+// addPoints scores p points scaled by the config's ScoreScale, carrying
+// any fraction of a point over to the next award.
+func (w *World) addPoints(p float64) {
+	w.ScoreFraction += p * w.Config.scoreScale()
+	whole := math.Floor(w.ScoreFraction)
+	w.Score += int(whole)
+	w.ScoreFraction -= whole
+}
+
+// addWeaponKill counts a kill toward the next weapon level.
+func (w *World) addWeaponKill() {
+	if w.Config.NoWeaponUps || w.WeaponLevel == len(weaponLevels)-1 {
+		return
+	}
+	w.KillsTowardNext++
+	if w.KillsTowardNext >= weaponLevels[w.WeaponLevel].killsToNext {
+		w.WeaponLevel++
+		w.KillsTowardNext = 0
+		w.emit(Event{Kind: EventWeaponUp, Level: w.WeaponLevel + 1})
+	}
+}
+
+// threatens reports whether an asteroid is close enough to the player
+// across its path to count toward a dodge: horizontally for one falling or
+// rising, vertically for one crossing sideways.
+func (w *World) threatens(a *Asteroid) bool {
+	p := &w.Player
+	if a.From == EdgeLeft || a.From == EdgeRight {
+		dy := math.Abs((a.Y + a.Height/2) - (p.Y + p.Height/2))
+		return dy <= (a.Height+p.Height)/2+w.Config.Tuning.ThreatMargin
+	}
+	dx := math.Abs((a.X + a.Width/2) - (p.X + p.Width/2))
+	if w.Config.Wrap {
+		dx = math.Min(dx, w.Config.Width-dx)
+	}
+	return dx <= (a.Width+p.Width)/2+w.Config.Tuning.ThreatMargin
+}
+
+// inSpawnSafeZone reports whether a box overlaps the area around the player
+// where nothing may spawn.
+func (w *World) inSpawnSafeZone(x, y, bw, bh float64) bool {
+	p := &w.Player
+	zone := w.Config.Tuning.SpawnSafeZone
+	for _, px := range w.PlayerCopies(p.X) {
+		if isColliding(px-zone, p.Y-zone, p.Width+2*zone, p.Height+2*zone, x, y, bw, bh) {
+			return true
+		}
+	}
+	return false
+}
+
+func isColliding(x1, y1, w1, h1, x2, y2, w2, h2 float64) bool {
+	return x1 < x2+w2 && x1+w1 > x2 && y1 < y2+h2 && y1+h1 > y2
+}
+
+// penetration is how far two rects reach into each other along each
+// axis: how far either would have to move that way to stop touching. ok
+// is false, as with isColliding, if they don't touch.
+func penetration(x1, y1, w1, h1, x2, y2, w2, h2 float64) (px, py float64, ok bool) {
+	px = min(x1+w1-x2, x2+w2-x1)
+	py = min(y1+h1-y2, y2+h2-y1)
+	return px, py, px > 0 && py > 0
+}
+
+// hitDamage is the damage a bullet deals an asteroid it reached px and
+// py into. It is measured across the bullet's path, since how far along
+// the path it got is down to where the tick fell. A bullet through the
+// middle of a large asteroid deals MaxHitDamage.
+func hitDamage(b *Bullet, a *Asteroid, px, py float64) int {
+	if a.Width < CenterHitMinSize {
+		return 1
+	}
+	depth, most := px, (a.Width+BulletWidth)/2
+	if math.Abs(b.VX) > math.Abs(b.VY) {
+		depth, most = py, (a.Height+BulletHeight)/2
+	}
+	return 1 + int(math.Round(min(depth/most, 1)*(MaxHitDamage-1)))
+}
+
+// cleanUpObjects compacts the live bullets and asteroids in place, so a
+// long run reuses the same backing arrays. The dropped tail is zeroed so it
+// doesn't hold on to asteroid shapes.
+func (w *World) cleanUpObjects() {
+	// Clean bullets
+	activeBullets := w.Bullets[:0]
+	for _, b := range w.Bullets {
+		if b.Active {
+			activeBullets = append(activeBullets, b)
+		}
+	}
+	clear(w.Bullets[len(activeBullets):])
+	w.Bullets = activeBullets
+
+	// Clean asteroids
+	activeAsteroids := w.Asteroids[:0]
+	for _, a := range w.Asteroids {
+		if a.Active {
+			activeAsteroids = append(activeAsteroids, a)
+		}
+	}
+	clear(w.Asteroids[len(activeAsteroids):])
+	w.Asteroids = activeAsteroids
+}
@@ -0,0 +1,497 @@
+package core
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func testConfig() Config {
+	return Config{
+		TPS:          60,
+		Width:        640,
+		Height:       480,
+		MaxAsteroids: DefaultMaxAsteroids,
+		MaxBullets:   DefaultMaxBullets,
+	}
+}
+
+// quietWorld is a world with no spawns and no grace period, for placing
+// asteroids by hand.
+func quietWorld(cfg Config) *World {
+	w := NewWorld(cfg, 1)
+	w.HoldSpawns = true
+	w.GraceUntil = 0
+	return w
+}
+
+// stepUntil steps w with the input script gives for each tick until done
+// reports true, the run ends, or limit ticks pass.
+func stepUntil(w *World, limit int, script func(w *World) FrameInput, done func(w *World) bool) {
+	for i := 0; i < limit && !w.GameOver && !done(w); i++ {
+		w.Step(script(w))
+	}
+}
+
+func still(*World) FrameInput { return FrameInput{} }
+
+func noAsteroids(w *World) bool { return len(w.Asteroids) == 0 }
+
+func TestDodgeNeedsAThreat(t *testing.T) {
+	ship := DefaultShip.Size
+	margin := DefaultTuning.ThreatMargin
+	tests := []struct {
+		name    string
+		cfg     func(*Config)
+		playerX float64
+		x, size float64 // The asteroid
+		y       float64 // Where it starts, if not just above the field
+		script  func(w *World) FrameInput
+		dodged  bool
+	}{
+		{
+			name:    "passes just beside the ship",
+			playerX: 300, x: 300 + ship + 1, size: 30,
+			script: still, dodged: true,
+		},
+		{
+			name:    "passes at the edge of the margin",
+			playerX: 300, x: 300 + ship + margin - 1, size: 30,
+			script: still, dodged: true,
+		},
+		{
+			name:    "passes just beyond the margin",
+			playerX: 300, x: 300 + ship + margin + 1, size: 30,
+			script: still, dodged: false,
+		},
+		{
+			name:    "falls on the far side of the field",
+			playerX: 0, x: 600, size: 30,
+			script: still, dodged: false,
+		},
+		{
+			// Threatening once while on screen is enough
+			name:    "ship was near it and moved away",
+			playerX: 260, x: 300, size: 30,
+			script: func(w *World) FrameInput {
+				if w.Time < 10 {
+					return FrameInput{}
+				}
+				return FrameInput{MoveX: -1}
+			},
+			dodged: true,
+		},
+		{
+			// Before it enters the field it can't threaten anyone
+			name:    "ship left before it came into view",
+			playerX: 260, x: 300, y: -300, size: 30,
+			script: func(w *World) FrameInput { return FrameInput{MoveX: -1} },
+			dodged: false,
+		},
+		{
+			name:    "near across the wrap seam",
+			cfg:     func(c *Config) { c.Wrap = true },
+			playerX: 5, x: 640 - 30 - margin + 10, size: 30,
+			script: still, dodged: true,
+		},
+		{
+			name:    "far across the seam without wrap",
+			playerX: 5, x: 640 - 30 - margin + 10, size: 30,
+			script: still, dodged: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			if tt.cfg != nil {
+				tt.cfg(&cfg)
+			}
+			w := quietWorld(cfg)
+			w.Player.X = tt.playerX
+			w.AddAsteroid(tt.x, tt.size, DefaultTuning.AsteroidSpeed)
+			if tt.y != 0 {
+				w.Asteroids[0].Y, w.Asteroids[0].PrevY = tt.y, tt.y
+			}
+			stepUntil(w, 200, tt.script, noAsteroids)
+			if w.GameOver {
+				t.Fatal("the asteroid hit the ship")
+			}
+			if len(w.Asteroids) != 0 {
+				t.Fatal("the asteroid never left the field")
+			}
+			wantDodged, wantScore := 0, 0
+			if tt.dodged {
+				wantDodged, wantScore = 1, DefaultTuning.DodgeScore
+			}
+			if w.Dodged != wantDodged || w.Score != wantScore {
+				t.Errorf("dodged %d for %d points, want %d for %d", w.Dodged, w.Score, wantDodged, wantScore)
+			}
+		})
+	}
+}
+
+func TestNoDodgeScore(t *testing.T) {
+	cfg := testConfig()
+	cfg.NoDodgeScore = true
+	w := quietWorld(cfg)
+	w.Player.X = 300
+	w.AddAsteroid(300+w.Player.Width+1, 30, DefaultTuning.AsteroidSpeed)
+	stepUntil(w, 200, still, noAsteroids)
+	if w.Dodged != 1 || w.Score != 0 {
+		t.Errorf("dodged %d for %d points, want 1 for none", w.Dodged, w.Score)
+	}
+}
+
+// kinds lists the kinds of events.
+func kinds(events []Event) []EventKind {
+	var k []EventKind
+	for _, e := range events {
+		k = append(k, e.Kind)
+	}
+	return k
+}
+
+func TestShotAsteroidLeavingDoesNotScoreADodge(t *testing.T) {
+	for _, shot := range []bool{false, true} {
+		w := quietWorld(testConfig())
+		w.Player.X = 0
+		// A threatening asteroid that crosses the bottom edge this tick,
+		// with a still bullet where it ends up
+		w.AddAsteroid(300, 30, 120)
+		a := &w.Asteroids[0]
+		a.Y, a.Threatened = w.Config.Height-1, true
+		if shot {
+			w.Bullets = append(w.Bullets, Bullet{X: 310, Y: w.Config.Height - 5, Active: true})
+		}
+		events := kinds(w.Step(FrameInput{}))
+
+		wantDodged, wantDestroyed, wantScore := 1, 0, DefaultTuning.DodgeScore
+		want := []EventKind{EventDodged}
+		if shot {
+			wantDodged, wantDestroyed, wantScore = 0, 1, DefaultTuning.DestroyScore
+			want = []EventKind{EventHit, EventDestroyed}
+		}
+		if w.Dodged != wantDodged || w.Destroyed != wantDestroyed || w.Score != wantScore {
+			t.Errorf("shot %v: dodged %d, destroyed %d, scored %d; want %d, %d, %d",
+				shot, w.Dodged, w.Destroyed, w.Score, wantDodged, wantDestroyed, wantScore)
+		}
+		if !slices.Equal(events, want) {
+			t.Errorf("shot %v: events %v, want %v", shot, events, want)
+		}
+	}
+}
+
+func TestBulletAndShipCannotBothTakeAnAsteroid(t *testing.T) {
+	w := quietWorld(testConfig())
+	p := &w.Player
+	// A still asteroid right on the ship, and a bullet inside it
+	w.AddAsteroid(p.X, 30, 0)
+	a := &w.Asteroids[0]
+	a.Y, a.PrevY = p.Y, p.Y
+	w.Bullets = append(w.Bullets, Bullet{X: p.X + 10, Y: p.Y + 10, Active: true})
+
+	events := kinds(w.Step(FrameInput{}))
+	if w.GameOver {
+		t.Error("the ship was hit by an asteroid the bullet destroyed")
+	}
+	if w.Destroyed != 1 || !slices.Equal(events, []EventKind{EventHit, EventDestroyed}) {
+		t.Errorf("destroyed %d with events %v, want 1 kill", w.Destroyed, events)
+	}
+}
+
+func TestGraceProtectsTheShip(t *testing.T) {
+	w := quietWorld(testConfig())
+	w.GraceUntil = 30
+	p := &w.Player
+	// A still asteroid right on the ship from the first tick
+	w.AddAsteroid(p.X, 30, 0)
+	a := &w.Asteroids[0]
+	a.Y, a.PrevY = p.Y, p.Y
+
+	// Each step counts the tick it plays before anything moves
+	for w.Time < w.GraceUntil-1 {
+		w.Step(FrameInput{})
+		if w.GameOver {
+			t.Fatalf("the ship was hit on tick %d, during the grace period", w.Time)
+		}
+	}
+	w.Step(FrameInput{})
+	if w.InGrace() || !w.GameOver {
+		t.Errorf("the ship survived the asteroid on tick %d, after the grace period", w.Time)
+	}
+}
+
+func TestMutatorsScaleScoreAndHoldTheWeapon(t *testing.T) {
+	kills := weaponLevels[0].killsToNext
+	tests := []struct {
+		name  string
+		cfg   func(*Config)
+		scale float64 // Points per kill, as a multiple of destroyScore
+		level int
+	}{
+		{"no mutators", func(*Config) {}, 1, 1},
+		{"score scale", func(c *Config) { c.ScoreScale = 2.25 }, 2.25, 1},
+		{"no weapon upgrades", func(c *Config) { c.NoWeaponUps = true }, 1, 0},
+	}
+	for _, tt := range tests {
+		cfg := testConfig()
+		tt.cfg(&cfg)
+		w := quietWorld(cfg)
+		w.Player.X = 0
+		for range kills {
+			w.AddAsteroid(300, 30, 0)
+			w.Asteroids[0].Y = 100
+			w.Bullets = append(w.Bullets, Bullet{X: 310, Y: 110, Active: true})
+			w.Step(FrameInput{})
+		}
+		score := int(math.Floor(float64(kills*DefaultTuning.DestroyScore) * tt.scale))
+		if w.Destroyed != kills || w.Score != score || w.WeaponLevel != tt.level {
+			t.Errorf("%s: %d kills scored %d at weapon level %d, want %d kills scoring %d at level %d",
+				tt.name, w.Destroyed, w.Score, w.WeaponLevel, kills, score, tt.level)
+		}
+	}
+}
+
+func TestBulletsCannotBothTakeAnAsteroid(t *testing.T) {
+	w := quietWorld(testConfig())
+	w.AddAsteroid(300, 30, 0)
+	w.Asteroids[0].Y = 100
+	w.Bullets = append(w.Bullets,
+		Bullet{X: 305, Y: 110, Active: true},
+		Bullet{X: 315, Y: 110, Active: true})
+
+	w.Step(FrameInput{})
+	if w.Destroyed != 1 || w.Score != DefaultTuning.DestroyScore {
+		t.Errorf("destroyed %d for %d points, want 1 for %d", w.Destroyed, w.Score, DefaultTuning.DestroyScore)
+	}
+	if len(w.Bullets) != 1 {
+		t.Errorf("%d bullets left, want the second one to fly on", len(w.Bullets))
+	}
+}
+
+func TestCenterHitsDealMoreDamage(t *testing.T) {
+	const x, size = 300, 60
+	tests := []struct {
+		name    string
+		bulletX float64
+		damage  int
+	}{
+		{"dead center", x + size/2 - BulletWidth/2, MaxHitDamage},
+		{"halfway out", x + size/4, 2},
+		{"clipping the left edge", x - BulletWidth + 1, 1},
+		{"clipping the right edge", x + size - 1, 1},
+	}
+	for _, tt := range tests {
+		w := quietWorld(testConfig())
+		w.AddAsteroid(x, size, 0)
+		w.Asteroids[0].Y = 100
+		w.Bullets = append(w.Bullets, Bullet{X: tt.bulletX, Y: 120, Active: true})
+
+		var hit Event
+		for _, e := range w.Step(FrameInput{}) {
+			if e.Kind == EventHit {
+				hit = e
+			}
+		}
+		if hit.Amount != tt.damage {
+			t.Errorf("%s: dealt %d damage, want %d", tt.name, hit.Amount, tt.damage)
+		}
+		destroyed := tt.damage >= MaxHitDamage
+		gone := len(w.Asteroids) == 0
+		if gone != destroyed || w.Destroyed == 1 != destroyed {
+			t.Errorf("%s: destroyed is %v, want %v", tt.name, gone, destroyed)
+		}
+		if !gone && w.Asteroids[0].HP != MaxHitDamage-tt.damage {
+			t.Errorf("%s: the asteroid has %d hit points left, want %d", tt.name, w.Asteroids[0].HP, MaxHitDamage-tt.damage)
+		}
+	}
+}
+
+func TestGlancingHitsAddUp(t *testing.T) {
+	w := quietWorld(testConfig())
+	w.AddAsteroid(300, 60, 0)
+	w.Asteroids[0].Y = 100
+	for i := 1; i <= MaxHitDamage; i++ {
+		w.Bullets = append(w.Bullets, Bullet{X: 297, Y: 120, Active: true})
+		w.Step(FrameInput{})
+		if alive := len(w.Asteroids) == 1; alive != (i < MaxHitDamage) {
+			t.Fatalf("after %d edge hits the asteroid is still there: %v", i, alive)
+		}
+	}
+	if w.Destroyed != 1 || w.Score != DefaultTuning.DestroyScore {
+		t.Errorf("destroyed %d for %d points, want 1 for %d", w.Destroyed, w.Score, DefaultTuning.DestroyScore)
+	}
+}
+
+func TestInertiaRampsUpAndStops(t *testing.T) {
+	cfg := testConfig()
+	cfg.Inertia = true
+	w := quietWorld(cfg)
+	speed := w.Stats().PlayerSpeed
+	dt := 1 / float64(cfg.TPS)
+	gain, loss := DefaultTuning.PlayerAccel*dt, DefaultTuning.PlayerFriction*dt
+	near := func(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+
+	// Steering right gains the same speed every tick up to full speed
+	// and no further
+	for i := 1; w.Player.VX < speed || i <= int(speed/gain)+5; i++ {
+		if i > 1000 {
+			t.Fatal("the ship never reached full speed")
+		}
+		w.Step(FrameInput{MoveX: 1})
+		if want := min(float64(i)*gain, speed); !near(w.Player.VX, want) || w.Player.VY != 0 {
+			t.Fatalf("tick %d of steering: velocity (%g, %g), want (%g, 0)", i, w.Player.VX, w.Player.VY, want)
+		}
+	}
+
+	// Let go and it slows at the friction rate until it stops, then stays put
+	stop := int(math.Ceil(speed / loss))
+	for i := 1; i <= stop; i++ {
+		w.Step(FrameInput{})
+		if want := max(speed-float64(i)*loss, 0); !near(w.Player.VX, want) {
+			t.Fatalf("tick %d after letting go: velocity %g, want %g", i, w.Player.VX, want)
+		}
+	}
+	x := w.Player.X
+	for range 30 {
+		w.Step(FrameInput{})
+	}
+	if w.Player.VX != 0 || w.Player.X != x {
+		t.Errorf("the ship drifted from x %g to %g, at %g, after stopping", x, w.Player.X, w.Player.VX)
+	}
+
+	// Snappy movement goes straight to full speed and straight to a stop
+	w = quietWorld(testConfig())
+	w.Step(FrameInput{MoveX: 1})
+	first := w.Player.VX
+	w.Step(FrameInput{})
+	if first != speed || w.Player.VX != 0 {
+		t.Errorf("snappy movement went to %g on the first tick and %g after letting go, want %g and 0", first, w.Player.VX, speed)
+	}
+}
+
+func TestSpreadIsSymmetric(t *testing.T) {
+	// Pellets fan out evenly either side of the line of fire through the
+	// ship's center, whatever its size, its weapon and the way it aims
+	dirs := [][2]float64{{0, -1}, {1, 0}, {math.Sqrt2 / 2, -math.Sqrt2 / 2}}
+	for _, ship := range Ships {
+		for level := range WeaponLevels() {
+			for _, d := range dirs {
+				cfg := testConfig()
+				cfg.Ship = ship
+				w := quietWorld(cfg)
+				w.WeaponLevel = level
+				w.shoot(w.Stats(), d[0], d[1])
+
+				p := &w.Player
+				cx, cy := p.X+p.Width/2, p.Y+p.Height/2
+				var offsets []float64
+				for _, b := range w.Bullets {
+					// Sideways from the line of fire
+					offsets = append(offsets, (b.X+BulletWidth/2-cx)*-d[1]+(b.Y-cy)*d[0])
+				}
+				slices.Sort(offsets)
+				for i, o := range offsets {
+					if mirror := offsets[len(offsets)-1-i]; math.Abs(o+mirror) > 1e-9 {
+						t.Errorf("%s at weapon level %d aiming (%.2f, %.2f): pellets at %v aren't symmetric about the center",
+							ship.Name, level+1, d[0], d[1], offsets)
+						break
+					}
+				}
+			}
+		}
+	}
+}
+
+// shotTicks steps w through ticks ticks, pressing fire on the ticks in
+// presses, and returns the ticks on which the gun fired.
+func shotTicks(w *World, ticks int, presses ...int) []int {
+	var shots []int
+	for i := 0; i < ticks; i++ {
+		in := FrameInput{FirePressed: slices.Contains(presses, w.Time+1)}
+		if slices.Contains(kinds(w.Step(in)), EventFired) {
+			shots = append(shots, w.Time)
+		}
+	}
+	return shots
+}
+
+func TestFirePressWaitsForTheGun(t *testing.T) {
+	buffer := quietWorld(testConfig()).Ticks(FireBuffer)
+	tests := []struct {
+		name    string
+		weapon  int
+		presses []int
+		want    []int
+	}{
+		{
+			name: "press-to-fire shoots every press", weapon: 0,
+			presses: []int{10, 11, 12}, want: []int{10, 11, 12},
+		},
+		{
+			// The gun is ready again 12 ticks after a shot
+			name: "press on the tick the gun is ready", weapon: 2,
+			presses: []int{10, 22}, want: []int{10, 22},
+		},
+		{
+			name: "press just before it is ready", weapon: 2,
+			presses: []int{10, 21}, want: []int{10, 22},
+		},
+		{
+			name: "press as early as the buffer allows", weapon: 2,
+			presses: []int{10, 22 - buffer}, want: []int{10, 22},
+		},
+		{
+			name: "press too early is dropped", weapon: 2,
+			presses: []int{10, 22 - buffer - 1}, want: []int{10},
+		},
+		{
+			// However fast the presses come, the gun keeps its rate, and
+			// the presses waiting at the end give one shot, not several
+			name: "mashing", weapon: 2,
+			presses: []int{10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21},
+			want:    []int{10, 22},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := quietWorld(testConfig())
+			w.WeaponLevel = tt.weapon
+			if got := shotTicks(w, 60, tt.presses...); !slices.Equal(got, tt.want) {
+				t.Errorf("fired on ticks %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPracticePlaysOneWave(t *testing.T) {
+	// waves plays a stage run out and returns the waves it started, with
+	// whether the stage cleared
+	waves := func(practice int) (started []int, cleared bool) {
+		cfg := testConfig()
+		cfg.Mode, cfg.PracticeWave = ModeStage, practice
+		w := NewWorld(cfg, 1)
+		w.Invulnerable = true
+		started = []int{w.Wave + 1}
+		for w.Time < 60*600 {
+			for _, e := range w.Step(FrameInput{}) {
+				switch e.Kind {
+				case EventWaveStarted:
+					started = append(started, e.Level)
+				case EventStageCleared:
+					return started, true
+				}
+			}
+		}
+		return started, false
+	}
+	for n := 1; n <= StageWaves; n++ {
+		started, cleared := waves(n)
+		if !cleared || len(started) != 1 || started[0] != n {
+			t.Errorf("practicing wave %d played waves %v, cleared: %v", n, started, cleared)
+		}
+	}
+	if started, cleared := waves(0); !cleared || len(started) != StageWaves {
+		t.Errorf("the full stage played waves %v, cleared: %v", started, cleared)
+	}
+}
@@ -0,0 +1,124 @@
+package core
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"reflect"
+	"slices"
+)
+
+// Tuning holds the balance values a playtester may want to change without
+// rebuilding. The defaults are embedded from tuning.json.
+type Tuning struct {
+	PlayerSpeed    float64 `json:"playerSpeed"`    // Pixels per second
+	PlayerAccel    float64 `json:"playerAccel"`    // Inertial movement: pixels per second the ship gains each second while steered
+	PlayerFriction float64 `json:"playerFriction"` // Inertial movement: pixels per second it loses each second once let go
+	BulletSpeed    float64 `json:"bulletSpeed"`    // Pixels per second
+	AsteroidSpeed  float64 `json:"asteroidSpeed"`  // Pixels per second
+	SpawnInterval  float64 `json:"spawnInterval"`  // Seconds between asteroid spawns outside endless mode
+
+	DodgeScore   int `json:"dodgeScore"`   // Points for a threatening asteroid passing the ship
+	DestroyScore int `json:"destroyScore"` // Points for shooting an asteroid
+
+	AsteroidSizes SizeTable `json:"asteroidSizes"` // How often asteroids of each size spawn
+	ThreatMargin  float64   `json:"threatMargin"`  // Horizontal slack beyond touching at which an asteroid counts as a threat
+	SpawnSafeZone float64   `json:"spawnSafeZone"` // No asteroid may spawn within this distance of the player
+
+	StickFireDelay    float64 `json:"stickFireDelay"`    // Seconds between aimed shots when the weapon has no auto-fire
+	ReviveShield      float64 `json:"reviveShield"`      // Seconds of invulnerability after reviving
+	ReviveClearRadius float64 `json:"reviveClearRadius"` // Asteroids this close to the ship are removed when it revives
+	StartGrace        float64 `json:"startGrace"`        // Seconds at the start of a run with no spawns and no hits
+
+	EndlessCurve Curve `json:"endlessCurve"` // How endless mode's spawns ramp up over a run
+}
+
+//go:embed tuning.json
+var defaultTuningJSON []byte
+
+// DefaultTuning is the tuning the game ships with.
+var DefaultTuning = func() Tuning {
+	var t Tuning
+	if err := decodeTuning(defaultTuningJSON, &t); err != nil {
+		panic(fmt.Sprintf("embedded tuning.json: %v", err))
+	}
+	return t
+}()
+
+// LoadTuning reads a tuning file. Fields it leaves out keep their default
+// values; unknown fields are an error, since they are most likely typos.
+func LoadTuning(data []byte) (Tuning, error) {
+	t := DefaultTuning
+	// Decoding reuses a slice's array, which is the default's
+	t.AsteroidSizes, t.EndlessCurve = slices.Clone(t.AsteroidSizes), slices.Clone(t.EndlessCurve)
+	err := decodeTuning(data, &t)
+	return t, err
+}
+
+func decodeTuning(data []byte, t *Tuning) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode(t)
+}
+
+// Validate checks the tuning makes sense for a playfield of the given size,
+// reporting every invalid field at once.
+func (t Tuning) Validate(width, height float64) error {
+	var errs []error
+	positive := func(name string, v float64) {
+		if v <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be positive, got %g", name, v))
+		}
+	}
+	positive("playerSpeed", t.PlayerSpeed)
+	positive("playerAccel", t.PlayerAccel)
+	positive("playerFriction", t.PlayerFriction)
+	positive("bulletSpeed", t.BulletSpeed)
+	positive("asteroidSpeed", t.AsteroidSpeed)
+	positive("spawnInterval", t.SpawnInterval)
+	if t.DodgeScore < 0 {
+		errs = append(errs, fmt.Errorf("dodgeScore can't be negative, got %d", t.DodgeScore))
+	}
+	if t.DestroyScore < 0 {
+		errs = append(errs, fmt.Errorf("destroyScore can't be negative, got %d", t.DestroyScore))
+	}
+	errs = append(errs, t.AsteroidSizes.validate(int(min(width, height))-1)...)
+	if t.ThreatMargin < 0 {
+		errs = append(errs, fmt.Errorf("threatMargin can't be negative, got %g", t.ThreatMargin))
+	}
+	if t.SpawnSafeZone < 0 {
+		errs = append(errs, fmt.Errorf("spawnSafeZone can't be negative, got %g", t.SpawnSafeZone))
+	}
+	positive("stickFireDelay", t.StickFireDelay)
+	if t.ReviveShield < 0 {
+		errs = append(errs, fmt.Errorf("reviveShield can't be negative, got %g", t.ReviveShield))
+	}
+	if t.ReviveClearRadius < 0 {
+		errs = append(errs, fmt.Errorf("reviveClearRadius can't be negative, got %g", t.ReviveClearRadius))
+	}
+	if t.StartGrace < 0 {
+		errs = append(errs, fmt.Errorf("startGrace can't be negative, got %g", t.StartGrace))
+	}
+	errs = append(errs, t.EndlessCurve.validate(t.AsteroidSizes)...)
+	return errors.Join(errs...)
+}
+
+// Equal reports whether two tunings hold the same values.
+func (t Tuning) Equal(o Tuning) bool {
+	return reflect.DeepEqual(t, o)
+}
+
+// Checksum identifies the tuning, so runs under modified values can be
+// told apart from ones under the defaults.
+func (t Tuning) Checksum() string {
+	data, err := json.Marshal(t)
+	if err != nil {
+		panic(err) // Tuning holds only plain data
+	}
+	h := fnv.New64a()
+	h.Write(data)
+	return fmt.Sprintf("%016x", h.Sum64())
+}
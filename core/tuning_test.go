@@ -0,0 +1,75 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDefaultTuningIsValid(t *testing.T) {
+	if err := DefaultTuning.Validate(640, 480); err != nil {
+		t.Errorf("the embedded tuning is invalid: %v", err)
+	}
+}
+
+func TestValidateSpawnInterval(t *testing.T) {
+	for _, tt := range []struct {
+		interval float64
+		ok       bool
+	}{
+		{1, true},
+		{0.25, true}, // Sub-second intervals are fine; the unit is seconds
+		{1.0 / 60, true},
+		{0, false},
+		{-1, false},
+	} {
+		tun := DefaultTuning
+		tun.SpawnInterval = tt.interval
+		if err := tun.Validate(640, 480); (err == nil) != tt.ok {
+			t.Errorf("spawnInterval %g: Validate = %v, want ok %v", tt.interval, err, tt.ok)
+		}
+	}
+}
+
+func TestValidateListsEveryProblem(t *testing.T) {
+	tun := DefaultTuning
+	tun.PlayerSpeed = 0
+	tun.SpawnInterval = -1
+	tun.DodgeScore = -3
+	tun.AsteroidSizes = SizeTable{{Min: 20, Max: 900, Weight: 1}}
+	tun.EndlessCurve = Curve{{Time: 0, SpawnInterval: 0, SpeedScale: 1}}
+	err := tun.Validate(640, 480)
+	if err == nil {
+		t.Fatal("Validate accepted a broken tuning")
+	}
+	for _, field := range []string{"playerSpeed", "spawnInterval", "dodgeScore", "asteroidSizes[0].max", "endlessCurve[0].spawnInterval"} {
+		if !strings.Contains(err.Error(), field) {
+			t.Errorf("error doesn't mention %s: %v", field, err)
+		}
+	}
+}
+
+func TestLoadTuning(t *testing.T) {
+	tun, err := LoadTuning([]byte(`{"playerSpeed": 123}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tun.PlayerSpeed != 123 || tun.BulletSpeed != DefaultTuning.BulletSpeed {
+		t.Errorf("partial file gave %+v, want playerSpeed 123 and the rest default", tun)
+	}
+	if tun.Checksum() == DefaultTuning.Checksum() {
+		t.Error("a modified tuning has the default checksum")
+	}
+
+	if _, err := LoadTuning([]byte(`{"playerSped": 123}`)); err == nil {
+		t.Error("LoadTuning accepted a misspelled field")
+	}
+
+	// Decoding into the copy mustn't reach the defaults' slices
+	sizes := DefaultTuning.AsteroidSizes[0]
+	if _, err := LoadTuning([]byte(`{"asteroidSizes": [{"min": 1, "max": 2, "weight": 1}]}`)); err != nil {
+		t.Fatal(err)
+	}
+	if DefaultTuning.AsteroidSizes[0] != sizes {
+		t.Error("loading a tuning file changed the defaults")
+	}
+}
@@ -0,0 +1,456 @@
+// Package core is the Space Dodger simulation. It knows nothing about
+// rendering, input devices or the platform: a front end feeds it one
+// FrameInput per tick and presents the World and the Events Step returns.
+package core
+
+import (
+	"cmp"
+	"encoding/json"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"slices"
+)
+
+const (
+	TuningVersion = 12 // Bump whenever a change alters gameplay, as the golden tests show; invalidates ghosts and saves
+	SpawnRetries  = 5  // Attempts at a safe spawn position before skipping the spawn
+
+	DefaultMaxAsteroids = 256 // Live asteroids beyond this are not spawned
+	DefaultMaxBullets   = 128 // Firing beyond this recycles the oldest bullet
+
+	StageWaves   = 5    // Waves in a stage-mode run
+	WaveBaseSize = 8    // Asteroids in the first wave
+	WaveGrowth   = 4    // Extra asteroids in each following wave
+	WaveSpeedup  = 0.25 // Each wave spawns this much faster than the first, as a fraction
+	WaveBreak    = 3.0  // Seconds of calm between waves
+
+	IdleDecayDelay = 10.0 // Seconds without a kill before dodge points start to shrink
+	IdleDecayRate  = 0.1  // Fraction of the multiplier lost per further second
+	IdleDecayFloor = 0.25 // The multiplier never drops below this
+
+	HitboxAssistScale = 0.6 // Fraction of the ship's width and height the SmallHitbox assist leaves hittable
+
+	AsteroidPoints     = 10   // Vertices in an asteroid outline
+	AsteroidJaggedness = 0.35 // Max radius reduction per vertex (0 = circle)
+
+	BulletWidth  = 4
+	BulletHeight = 10
+	FireBuffer   = 0.1 // Seconds a fire press waits for the gun to be ready before it is dropped
+
+	CenterHitMinSize = 32 // Asteroids at least this wide have MaxHitDamage hit points and take more damage the nearer their middle a bullet hits
+	MaxHitDamage     = 3  // Damage of a dead-center hit on one of them; a hit that only clips the edge deals 1
+)
+
+// Mode decides how asteroids arrive and how a run can end.
+type Mode int
+
+const (
+	ModeEndless   Mode = iota // Asteroids keep coming until the ship is hit
+	ModeStage                 // StageWaves waves; surviving the last one clears the stage
+	ModeHardcore              // Endless, but levels come faster and each one spawns faster
+	ModeTwinStick             // Endless, with asteroids from every edge and shots aimed any way
+	ModeCount
+)
+
+// Config is fixed for the length of a run.
+type Config struct {
+	TPS       int     `json:"tps"` // Ticks per second
+	Width     float64 `json:"width"`
+	Height    float64 `json:"height"`
+	Wrap      bool    `json:"wrap"` // Ship and bullets leave one side and reappear on the other
+	Mode      Mode    `json:"mode"`
+	IdleDecay bool    `json:"idleDecay"` // Dodge points shrink while the player goes without a kill
+
+	NoDodgeScore bool `json:"noDodgeScore,omitempty"` // Dodges are counted but score nothing; points come only from kills
+	Inertia      bool `json:"inertia,omitempty"`      // The ship speeds up and slows down instead of moving at full speed at once
+	PracticeWave int  `json:"practiceWave,omitempty"` // Stage mode: play only this wave, counted from 1, and clear the stage once it's gone. 0 plays them all
+	SharedField  bool `json:"sharedField,omitempty"`  // Spawns never depend on where the ship is, so worlds with the same seed get the same asteroids whoever flies them
+
+	// Mutators, which make a run harder for more points
+	Mutators    []string `json:"mutators,omitempty"`    // Names of those chosen, for the record; their effects are in the rest of the config
+	ScoreScale  float64  `json:"scoreScale,omitempty"`  // Multiplier on every point scored. 0 means 1
+	NoWeaponUps bool     `json:"noWeaponUps,omitempty"` // Kills never level up the weapon
+
+	BroadPhase   BroadPhase  `json:"broadPhase"`  // How collision checks find nearby asteroids; no effect on the outcome
+	SpawnPolicy  SpawnPolicy `json:"spawnPolicy"` // Where lone asteroids spawn
+	MaxAsteroids int         `json:"maxAsteroids"`
+	MaxBullets   int         `json:"maxBullets"`
+
+	// Multipliers over the tuning's player and bullet speeds; 0 means 1
+	PlayerSpeedScale float64 `json:"playerSpeedScale"`
+	BulletSpeedScale float64 `json:"bulletSpeedScale"`
+	SpawnScale       float64 `json:"spawnScale,omitempty"` // Multiplier on how often asteroids spawn, for fields wider than the tuning was made for
+
+	// Assists, which make a run easier without changing its rules
+	TimeScale   float64 `json:"timeScale,omitempty"`   // Game seconds per real second; below 1 slows everything alike, above 1 is a challenge rather than an assist. 0 means 1
+	SmallHitbox bool    `json:"smallHitbox,omitempty"` // Only the middle HitboxAssistScale of the ship can be hit
+	AutoFire    bool    `json:"autoFire,omitempty"`    // Fire as if it were always held
+
+	Tuning Tuning `json:"tuning"` // The zero Tuning means DefaultTuning
+	Ship   Ship   `json:"ship"`   // The zero Ship means DefaultShip
+}
+
+// timeScale is TimeScale with 0 read as 1.
+func (c Config) timeScale() float64 {
+	if c.TimeScale == 0 {
+		return 1
+	}
+	return c.TimeScale
+}
+
+// scoreScale is ScoreScale with 0 read as 1.
+func (c Config) scoreScale() float64 {
+	if c.ScoreScale == 0 {
+		return 1
+	}
+	return c.ScoreScale
+}
+
+// Assisted reports whether any assist is on. Running faster than normal
+// isn't one.
+func (c Config) Assisted() bool {
+	return c.timeScale() < 1 || c.SmallHitbox || c.AutoFire
+}
+
+// World is the complete state of a run. It round-trips through JSON.
+type World struct {
+	Config    Config     `json:"config"`
+	Player    Player     `json:"player"`
+	Bullets   []Bullet   `json:"bullets"`
+	Asteroids []Asteroid `json:"asteroids"`
+	Score     int        `json:"score"`
+	Destroyed int        `json:"destroyed"` // Asteroids shot this run
+	Dodged    int        `json:"dodged"`    // Threatening asteroids that passed the player this run
+	Time      int        `json:"time"`      // Ticks simulated this run; drives all timers
+	NextSpawn int        `json:"nextSpawn"` // Time of the next asteroid spawn
+	NextID    uint64     `json:"nextID"`    // Last ID handed out; each bullet and asteroid gets the next one when it spawns
+	GameOver  bool       `json:"gameOver"`
+	Cleared   bool       `json:"cleared"`          // The run ended by clearing the stage rather than a hit
+	Killer    *Asteroid  `json:"killer,omitempty"` // The asteroid that hit the ship, as it was then; nil until a hit
+
+	TicksSinceKill int     `json:"ticksSinceKill"`
+	ScoreFraction  float64 `json:"scoreFraction"` // Partial point left over from multiplied or scaled points
+
+	Wave        int `json:"wave"`        // Stage mode: current wave, from 0; StageWaves once all have spawned
+	WaveSpawned int `json:"waveSpawned"` // Stage mode: asteroids spawned so far this wave
+
+	WeaponLevel     int `json:"weaponLevel"` // Index into weaponLevels
+	KillsTowardNext int `json:"killsTowardNext"`
+	FireReadyAt     int `json:"fireReadyAt"`               // Time from which the gun shoots again
+	FireQueuedUntil int `json:"fireQueuedUntil,omitempty"` // A fire press waits for the gun until this Time; 0 for none
+
+	HoldSpawns   bool `json:"holdSpawns"`   // Skip normal spawning, e.g. during the tutorial
+	Invulnerable bool `json:"invulnerable"` // Asteroids pass through the player
+	ShieldUntil  int  `json:"shieldUntil"`  // Asteroids also pass through the player before this Time
+	GraceUntil   int  `json:"graceUntil"`   // Nothing spawns and nothing hits the player before this Time
+
+	// RNG is the gameplay stream: spawns, formations and anything else that
+	// changes what happens. Effects that only change how things look must
+	// draw from their own source, so turning them on or off can't change a
+	// seeded run.
+	RNG RNG `json:"rng"`
+
+	rng        *rand.Rand
+	events     []Event
+	broad      broadPhase
+	candidates []int // Scratch space for broad-phase queries
+}
+
+// Entities keep their position from the previous tick so a front end can
+// interpolate between ticks.
+type Player struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	PrevX  float64 `json:"prevX"`
+	PrevY  float64 `json:"prevY"`
+	VX     float64 `json:"vx"` // Pixels per second
+	VY     float64 `json:"vy"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+type Bullet struct {
+	ID     uint64  `json:"id"` // See World.NextID
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	PrevX  float64 `json:"prevX"`
+	PrevY  float64 `json:"prevY"`
+	VX     float64 `json:"vx"` // Pixels per second
+	VY     float64 `json:"vy"`
+	Active bool    `json:"active"`
+}
+
+type Asteroid struct {
+	ID     uint64  `json:"id"` // See World.NextID
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	PrevX  float64 `json:"prevX"`
+	PrevY  float64 `json:"prevY"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+	Speed  float64 `json:"speed"`          // Pixels per second, downward; negative is upward
+	VX     float64 `json:"vx,omitempty"`   // Pixels per second, rightward
+	From   Edge    `json:"from,omitempty"` // Edge it entered through
+	Active bool    `json:"active"`
+	HP     int     `json:"hp,omitempty"` // Hit points left; any hit destroys an asteroid with none, as in saves from before they had them
+	Shape  []Point `json:"shape"`        // Outline offsets from the asteroid's center
+
+	Threatened bool `json:"threatened"` // Came within the tuning's threat margin of the player horizontally
+}
+
+type Point struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// Muzzle is where a bullet leaves the ship. It is relative to the ship's
+// rect and line of fire, so shots line up on a ship of any size.
+type Muzzle struct {
+	Across float64 `json:"across"` // Sideways from the ship's center, as a fraction of its width
+	Back   float64 `json:"back"`   // Behind the edge the ship fires from, as a fraction of its height
+}
+
+// weaponLevel describes the gun at one step of its upgrade path.
+type weaponLevel struct {
+	muzzles       []Muzzle // One per bullet
+	autoFireDelay float64  // Seconds between shots while fire is held; 0 means press-to-fire only
+	killsToNext   int      // Kills needed to reach the following level
+}
+
+var weaponLevels = []weaponLevel{
+	{muzzles: []Muzzle{{0, 0}}, killsToNext: 5},
+	{muzzles: []Muzzle{{-0.2, 0}, {0.2, 0}}, killsToNext: 10},
+	{muzzles: []Muzzle{{-0.2, 0}, {0.2, 0}}, autoFireDelay: 0.2, killsToNext: 15},
+	{muzzles: []Muzzle{{-1.0 / 3, 0}, {0, 0}, {1.0 / 3, 0}}, autoFireDelay: 0.133},
+}
+
+// WaveSize is how many asteroids wave number n, counted from 0, spawns.
+func WaveSize(n int) int {
+	return WaveBaseSize + WaveGrowth*n
+}
+
+// WeaponLevels is the number of steps on the weapon upgrade path.
+func WeaponLevels() int {
+	return len(weaponLevels)
+}
+
+// NewWorld starts a run with the player at the bottom center.
+func NewWorld(cfg Config, seed int64) *World {
+	if cfg.PlayerSpeedScale == 0 {
+		cfg.PlayerSpeedScale = 1
+	}
+	if cfg.BulletSpeedScale == 0 {
+		cfg.BulletSpeedScale = 1
+	}
+	if cfg.Tuning.Equal(Tuning{}) {
+		cfg.Tuning = DefaultTuning
+	}
+	if cfg.Ship == (Ship{}) {
+		cfg.Ship = DefaultShip
+	}
+	size := cfg.Ship.Size
+	w := &World{
+		Config: cfg,
+		Player: Player{
+			X:      cfg.Width/2 - size/2,
+			Y:      cfg.Height - 10 - size,
+			Width:  size,
+			Height: size,
+		},
+		RNG: RNG{Origin: seed},
+	}
+	w.Player.PrevX, w.Player.PrevY = w.Player.X, w.Player.Y
+	if cfg.Mode == ModeStage && cfg.PracticeWave > 0 {
+		w.Wave = min(cfg.PracticeWave, StageWaves) - 1
+	}
+	w.GraceUntil = w.Ticks(cfg.Tuning.StartGrace)
+	w.NextSpawn = w.GraceUntil + w.Ticks(w.spawnInterval())
+	return w
+}
+
+// waveSize is how many asteroids the current wave spawns, with more on a
+// scaled-up field so waves last as long and are as dense.
+func (w *World) waveSize() int {
+	if s := w.Config.SpawnScale; s > 0 {
+		return int(math.Round(float64(WaveSize(w.Wave)) * s))
+	}
+	return WaveSize(w.Wave)
+}
+
+// Difficulty is the endless curve's values at this point in the run. ok
+// is false in the other modes, which ramp up their own ways.
+func (w *World) Difficulty() (d Difficulty, ok bool) {
+	if w.Config.Mode != ModeEndless {
+		return Difficulty{}, false
+	}
+	t := &w.Config.Tuning
+	return t.EndlessCurve.At(w.seconds(), t.AsteroidSizes), true
+}
+
+// seconds is the game time since the run began.
+func (w *World) seconds() float64 {
+	return float64(w.Time) / float64(w.Config.TPS) * w.Config.timeScale()
+}
+
+// asteroidSpeed is how fast a normal asteroid spawned now falls.
+func (w *World) asteroidSpeed() float64 {
+	if d, ok := w.Difficulty(); ok {
+		return w.Config.Tuning.AsteroidSpeed * d.SpeedScale
+	}
+	return w.Config.Tuning.AsteroidSpeed
+}
+
+// asteroidSize rolls the width of an asteroid spawned now.
+func (w *World) asteroidSize() float64 {
+	t := &w.Config.Tuning
+	if d, ok := w.Difficulty(); ok {
+		return t.AsteroidSizes.PickWeighted(w.rand(), d.SizeWeights)
+	}
+	return t.AsteroidSizes.Pick(w.rand())
+}
+
+// spawnInterval is the seconds between asteroid spawns.
+func (w *World) spawnInterval() float64 {
+	interval := w.Config.Tuning.SpawnInterval
+	if d, ok := w.Difficulty(); ok {
+		interval = d.SpawnInterval
+	}
+	if s := w.Config.SpawnScale; s > 0 {
+		interval /= s
+	}
+	if w.Config.Mode == ModeHardcore {
+		interval /= math.Min(1+HardcoreSpeedup*float64(w.level()), HardcoreSpeedupMax)
+	}
+	return interval
+}
+
+// Ticks converts a duration to whole ticks.
+func (w *World) Ticks(seconds float64) int {
+	return int(math.Round(seconds * float64(w.Config.TPS) / w.Config.timeScale()))
+}
+
+func (w *World) rand() *rand.Rand {
+	if w.rng == nil {
+		w.rng = rand.New(&w.RNG)
+	}
+	return w.rng
+}
+
+// newID hands out the next entity ID. IDs start at 1, so 0 can mean none.
+func (w *World) newID() uint64 {
+	w.NextID++
+	return w.NextID
+}
+
+// FindAsteroid looks up a live asteroid by ID, for anything that has to
+// follow one from tick to tick; indices shift whenever the slice is
+// compacted. ok is false once the asteroid is gone. The pointer is only
+// good until the next Step.
+func (w *World) FindAsteroid(id uint64) (a *Asteroid, ok bool) {
+	// Asteroids are only ever appended as they spawn and compaction keeps
+	// their order, so the slice is sorted by ID
+	i, found := slices.BinarySearchFunc(w.Asteroids, id, func(a Asteroid, id uint64) int { return cmp.Compare(a.ID, id) })
+	if !found || id == 0 || !w.Asteroids[i].Active {
+		return nil, false
+	}
+	return &w.Asteroids[i], true
+}
+
+// FindBullet is FindAsteroid for bullets, which are likewise sorted by ID.
+func (w *World) FindBullet(id uint64) (b *Bullet, ok bool) {
+	i, found := slices.BinarySearchFunc(w.Bullets, id, func(b Bullet, id uint64) int { return cmp.Compare(b.ID, id) })
+	if !found || id == 0 || !w.Bullets[i].Active {
+		return nil, false
+	}
+	return &w.Bullets[i], true
+}
+
+// AddBullet fires a bullet from (x, y) at the given velocity in pixels per
+// second, as if the player had shot it.
+func (w *World) AddBullet(x, y, vx, vy float64) {
+	w.Bullets = append(w.Bullets, Bullet{ID: w.newID(), X: x, Y: y, PrevX: x, PrevY: y, VX: vx, VY: vy, Active: true})
+}
+
+// AddAsteroid drops an asteroid of the given size and speed from just above
+// the top of the playfield.
+func (w *World) AddAsteroid(x, width, speed float64) {
+	a := w.newAsteroid(x, width)
+	a.Speed = speed
+	w.Asteroids = append(w.Asteroids, a)
+}
+
+// ResumeSpawning ends HoldSpawns, with the next spawn a full interval away.
+func (w *World) ResumeSpawning() {
+	w.HoldSpawns = false
+	w.NextSpawn = w.Time + w.Ticks(w.spawnInterval())
+}
+
+// ScoreMultiplier is what dodge points are worth right now. With IdleDecay
+// on it shrinks once the player has gone IdleDecayDelay without a kill.
+func (w *World) ScoreMultiplier() float64 {
+	idle := w.TicksSinceKill - w.Ticks(IdleDecayDelay)
+	if !w.Config.IdleDecay || idle <= 0 {
+		return 1
+	}
+	secs := float64(idle) / float64(w.Config.TPS) * w.Config.timeScale()
+	return math.Max(IdleDecayFloor, math.Pow(1-IdleDecayRate, secs))
+}
+
+// InGrace reports whether the run is still in its opening grace period,
+// which gives the player time to get their bearings.
+func (w *World) InGrace() bool {
+	return w.Time < w.GraceUntil
+}
+
+// Shielded reports whether the player is in a post-revive grace period.
+func (w *World) Shielded() bool {
+	return w.Time < w.ShieldUntil
+}
+
+// Revive brings a destroyed ship back, keeping the score. Asteroids around
+// it are cleared and it can't be hit for a short while.
+func (w *World) Revive() {
+	w.GameOver = false
+	w.Killer = nil
+	w.ShieldUntil = w.Time + w.Ticks(w.Config.Tuning.ReviveShield)
+	p := &w.Player
+	p.VX, p.VY = 0, 0
+	px, py := p.X+p.Width/2, p.Y+p.Height/2
+	for i := range w.Asteroids {
+		a := &w.Asteroids[i]
+		if math.Hypot(a.X+a.Width/2-px, a.Y+a.Height/2-py) < w.Config.Tuning.ReviveClearRadius {
+			a.Active = false
+		}
+	}
+}
+
+// WrapX maps x into [0, Width).
+func (w *World) WrapX(x float64) float64 {
+	x = math.Mod(x, w.Config.Width)
+	if x < 0 {
+		x += w.Config.Width
+	}
+	return x
+}
+
+// PlayerCopies returns the x positions a ship at x occupies. With wrapping
+// on, a ship straddling the right edge also pokes out of the left one.
+func (w *World) PlayerCopies(x float64) []float64 {
+	if w.Config.Wrap && x > w.Config.Width-w.Player.Width {
+		return []float64{x, x - w.Config.Width}
+	}
+	return []float64{x}
+}
+
+// Hash summarizes the world's state, for checking that two copies of a
+// world have stayed identical.
+func (w *World) Hash() uint64 {
+	data, err := json.Marshal(w)
+	if err != nil {
+		panic(err) // World holds only plain data
+	}
+	h := fnv.New64a()
+	h.Write(data)
+	return h.Sum64()
+}
@@ -0,0 +1,124 @@
+package core
+
+import (
+	"math"
+	"testing"
+)
+
+func TestIdleDecay(t *testing.T) {
+	cfg := testConfig()
+	cfg.IdleDecay = true
+	w := quietWorld(cfg)
+	tests := []struct {
+		secs float64 // Idle so far
+		want float64
+	}{
+		{0, 1},
+		{IdleDecayDelay, 1},
+		{IdleDecayDelay + 1, 1 - IdleDecayRate},
+		{IdleDecayDelay + 5, math.Pow(1-IdleDecayRate, 5)},
+		{IdleDecayDelay + 10, math.Pow(1-IdleDecayRate, 10)},
+		{IdleDecayDelay + 60, IdleDecayFloor},
+		{IdleDecayDelay + 600, IdleDecayFloor},
+	}
+	for _, tt := range tests {
+		stepUntil(w, w.Ticks(tt.secs)-w.TicksSinceKill, still, func(*World) bool { return false })
+		if got := w.ScoreMultiplier(); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("multiplier is %g after %gs idle, want %g", got, tt.secs, tt.want)
+		}
+	}
+
+	// A kill restores it at once
+	w.Player.X = 300
+	w.AddAsteroid(300, 30, 0)
+	w.Asteroids[0].Y = w.Player.Y - 100
+	stepUntil(w, 100, func(*World) FrameInput { return FrameInput{FirePressed: true} }, noAsteroids)
+	if w.Destroyed != 1 {
+		t.Fatal("the shot missed")
+	}
+	if got := w.ScoreMultiplier(); got != 1 {
+		t.Errorf("multiplier is %g right after a kill, want 1", got)
+	}
+}
+
+func TestIdleDecayOff(t *testing.T) {
+	w := quietWorld(testConfig())
+	stepUntil(w, w.Ticks(IdleDecayDelay+60), still, func(*World) bool { return false })
+	if got := w.ScoreMultiplier(); got != 1 {
+		t.Errorf("multiplier is %g without IdleDecay, want 1", got)
+	}
+}
+
+func TestSpeedIsAChangeOfTickRate(t *testing.T) {
+	// Game time runs TimeScale times faster than real time, so a run at
+	// speed s and TPS ticks a second is the same run as one at normal
+	// speed and TPS / s ticks a second
+	weave := func(w *World) FrameInput {
+		return FrameInput{MoveX: float64(w.Time/90%2*2 - 1), FirePressed: w.Time%15 == 0}
+	}
+	run := func(tps int, scale float64) *World {
+		cfg := testConfig()
+		cfg.TPS, cfg.TimeScale = tps, scale
+		w := NewWorld(cfg, 5)
+		w.Invulnerable = true
+		for w.Time < 3600 {
+			w.Step(weave(w))
+		}
+		return w
+	}
+	want := run(60, 1)
+	if want.Destroyed == 0 || want.Dodged == 0 {
+		t.Fatal("nothing was hit or dodged, so the test proves nothing")
+	}
+	for _, tt := range []struct {
+		tps   int
+		scale float64
+	}{{30, 0.5}, {90, 1.5}, {120, 2}} {
+		w := run(tt.tps, tt.scale)
+		if w.Score != want.Score || w.Destroyed != want.Destroyed || w.Dodged != want.Dodged ||
+			w.RNG.Draws != want.RNG.Draws || w.Player.X != want.Player.X {
+			t.Errorf("%d TPS at %gx: score %d, %d destroyed, %d dodged, %d draws, ship at %g; want %d, %d, %d, %d, %g",
+				tt.tps, tt.scale, w.Score, w.Destroyed, w.Dodged, w.RNG.Draws, w.Player.X,
+				want.Score, want.Destroyed, want.Dodged, want.RNG.Draws, want.Player.X)
+		}
+	}
+}
+
+func TestFindAsteroidFollowsIDs(t *testing.T) {
+	w := quietWorld(testConfig())
+	w.Player.X = 0
+	// Three still asteroids in a row; the middle one is about to be shot
+	for _, x := range []float64{100, 300, 500} {
+		w.AddAsteroid(x, 30, 0)
+		w.Asteroids[len(w.Asteroids)-1].Y = 100
+	}
+	first, target, last := w.Asteroids[0].ID, w.Asteroids[1].ID, w.Asteroids[2].ID
+	if a, ok := w.FindAsteroid(target); !ok || a.X != 300 {
+		t.Fatalf("FindAsteroid(%d) didn't find the middle asteroid", target)
+	}
+	w.AddBullet(310, 110, 0, 0)
+	bullet := w.Bullets[0].ID
+
+	w.Step(FrameInput{})
+	if w.Destroyed != 1 {
+		t.Fatalf("destroyed %d asteroids, want the middle one", w.Destroyed)
+	}
+	if a, ok := w.FindAsteroid(target); ok || a != nil {
+		t.Errorf("the destroyed asteroid is still found, at x %g", a.X)
+	}
+	if _, ok := w.FindBullet(bullet); ok {
+		t.Error("the used-up bullet is still found")
+	}
+	// The last asteroid moved down the slice, but its ID still finds it
+	for id, x := range map[uint64]float64{first: 100, last: 500} {
+		if a, ok := w.FindAsteroid(id); !ok || a.X != x || a.ID != id {
+			t.Errorf("FindAsteroid(%d) lost the asteroid at x %g", id, x)
+		}
+	}
+	if _, ok := w.FindAsteroid(0); ok {
+		t.Error("ID 0 found an asteroid")
+	}
+	if _, ok := w.FindAsteroid(w.NextID + 1); ok {
+		t.Error("an ID not yet handed out found an asteroid")
+	}
+}
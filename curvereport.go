@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"slices"
+
+	"example/hello/core"
+)
+
+const (
+	curveReportRuns     = 25    // Bot runs per skill
+	curveReportOvertime = 120.0 // Seconds a run may go on past the last keyframe before it is called
+)
+
+// reportCurve flies the bot at every skill through endless runs under t
+// and prints, for each stretch of the difficulty curve, the median time
+// the runs that got there lasted in it, for -report-curve.
+func reportCurve(t core.Tuning, out io.Writer) {
+	// Stretches run from one keyframe to the next, the first from the
+	// start of the run and the last for the overtime
+	bounds := []float64{0}
+	for _, k := range t.EndlessCurve {
+		if k.Time > 0 {
+			bounds = append(bounds, k.Time)
+		}
+	}
+	end := bounds[len(bounds)-1] + curveReportOvertime
+	bounds = append(bounds, end)
+
+	fmt.Fprintf(out, "endless curve, tuning %s: median seconds lasted in each stretch (runs that got there), %d runs per skill\n", t.Checksum(), curveReportRuns)
+	fmt.Fprintf(out, "%-14s", "stretch")
+	for _, s := range core.BotSkills {
+		fmt.Fprintf(out, "%16s", s.Name)
+	}
+	fmt.Fprintln(out)
+
+	survived := make([][]float64, len(core.BotSkills)) // Seconds each run lasted, by skill
+	for i, s := range core.BotSkills {
+		for seed := int64(1); seed <= curveReportRuns; seed++ {
+			survived[i] = append(survived[i], curveRun(t, s, seed, end))
+		}
+	}
+	for j := 0; j+1 < len(bounds); j++ {
+		lo, hi := bounds[j], bounds[j+1]
+		fmt.Fprintf(out, "%-14s", fmt.Sprintf("%g-%gs", lo, hi))
+		for i := range core.BotSkills {
+			var lasted []float64
+			for _, secs := range survived[i] {
+				if secs > lo {
+					lasted = append(lasted, min(secs, hi)-lo)
+				}
+			}
+			cell := "-"
+			if len(lasted) > 0 {
+				cell = fmt.Sprintf("%.1f (%d)", median(lasted), len(lasted))
+			}
+			fmt.Fprintf(out, "%16s", cell)
+		}
+		fmt.Fprintln(out)
+	}
+}
+
+// curveRun flies one endless run with the bot's input delayed by its
+// reaction time, and returns the seconds it lasted, up to limit.
+func curveRun(t core.Tuning, skill core.BotSkill, seed int64, limit float64) float64 {
+	w := core.NewWorld(core.Config{
+		TPS:          defaultTPS,
+		Width:        float64(screenWidth),
+		Height:       float64(screenHeight),
+		Mode:         core.ModeEndless,
+		MaxAsteroids: core.DefaultMaxAsteroids,
+		MaxBullets:   core.DefaultMaxBullets,
+		SpawnPolicy:  core.SpawnFair,
+		Tuning:       t,
+	}, seed)
+	pending := make([]core.FrameInput, w.Ticks(skill.Reaction)) // Decided but not yet acted on
+	for !w.GameOver && w.Time < w.Ticks(limit) {
+		pending = append(pending, core.BotInput(w, skill))
+		w.Step(pending[0])
+		pending = pending[1:]
+	}
+	return float64(w.Time) / defaultTPS
+}
+
+// median is the middle of vs, which it sorts.
+func median(vs []float64) float64 {
+	slices.Sort(vs)
+	n := len(vs)
+	if n%2 == 1 {
+		return vs[n/2]
+	}
+	return (vs[n/2-1] + vs[n/2]) / 2
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"hash/fnv"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"example/hello/core"
+)
+
+// The daily challenge gives everyone the same run each day: the seed comes
+// from the date, and the playfield ignores personal settings.
+
+const dailyDateFormat = "2006-01-02"
+
+// today is the current daily challenge's date. Days are UTC so every
+// player's day starts at the same moment.
+func today() string {
+	return time.Now().UTC().Format(dailyDateFormat)
+}
+
+func dailySeed(date string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte("space-dodger-daily-" + date))
+	return int64(h.Sum64() >> 1)
+}
+
+// dailyConfig is the fixed playfield every daily challenge uses.
+func dailyConfig() core.Config {
+	return core.Config{
+		TPS:          ebiten.TPS(),
+		Width:        landscapeWidth,
+		Height:       landscapeHeight,
+		MaxAsteroids: core.DefaultMaxAsteroids,
+		MaxBullets:   core.DefaultMaxBullets,
+		Tuning:       core.DefaultTuning,
+		SpawnPolicy:  core.SpawnFair,
+		IdleDecay:    true,
+	}
+}
+
+// startDaily begins today's challenge.
+func (g *Game) startDaily() {
+	g.daily = today()
+	g.practice = 0
+	g.reset()
+	g.screen = screenPlaying
+}
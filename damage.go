@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+
+	"example/hello/core"
+)
+
+const (
+	damageNumberLife = 0.8 // Seconds a damage number floats
+	damageNumberRise = 30  // Pixels it rises over its life
+	damageNumberJog  = 6   // Largest sideways nudge, so numbers from a burst don't stack
+	dpsWindow        = 5.0 // Seconds of hits the DPS meter averages over
+)
+
+// damageNumber is a hit's damage, drawn floating up from where it landed.
+type damageNumber struct {
+	x, y   float64
+	amount int
+	at     int // World time of the hit
+}
+
+// dpsMeter tracks recent damage for one weapon level.
+type dpsMeter struct {
+	weapon int // Level, counted from 1
+	hits   []damageNumber
+}
+
+// damageStatsOn reports whether hits are being measured. They are in
+// practice runs and otherwise only with the debug overlay up.
+func (g *Game) damageStatsOn() bool {
+	return g.debug || g.practice > 0
+}
+
+func (g *Game) onHit(e core.Event) {
+	if !g.damageStatsOn() {
+		return
+	}
+	now := g.world.Time
+	hit := damageNumber{x: e.X, y: e.Y, amount: e.Amount, at: now}
+	shown := hit
+	shown.x += (g.fx.Float64()*2 - 1) * damageNumberJog
+
+	live := g.damageNumbers[:0]
+	for _, d := range g.damageNumbers {
+		if now-d.at < g.world.Ticks(damageNumberLife) {
+			live = append(live, d)
+		}
+	}
+	g.damageNumbers = append(live, shown)
+
+	if e.Level != g.dps.weapon {
+		g.dps = dpsMeter{weapon: e.Level}
+	}
+	recent := g.dps.hits[:0]
+	for _, d := range g.dps.hits {
+		if now-d.at < g.world.Ticks(dpsWindow) {
+			recent = append(recent, d)
+		}
+	}
+	g.dps.hits = append(recent, hit)
+}
+
+// rate is the damage per second over the last dpsWindow seconds.
+func (m *dpsMeter) rate(w *core.World) float64 {
+	total := 0
+	for _, d := range m.hits {
+		if w.Time-d.at < w.Ticks(dpsWindow) {
+			total += d.amount
+		}
+	}
+	return float64(total) / dpsWindow
+}
+
+func (g *Game) drawDamageNumbers(screen *ebiten.Image, ox float64) {
+	if !g.damageStatsOn() {
+		return
+	}
+	life := g.world.Ticks(damageNumberLife)
+	for _, d := range g.damageNumbers {
+		age := g.world.Time - d.at
+		if age >= life {
+			continue
+		}
+		y := d.y - damageNumberRise*float64(age)/float64(life)
+		ebitenutil.DebugPrintAt(screen, strconv.Itoa(d.amount), int(d.x+ox)-3, int(y)-16)
+	}
+}
+
+func (g *Game) drawDPS(screen *ebiten.Image, x, y int) {
+	if g.dps.weapon == 0 {
+		return
+	}
+	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("DPS Lv%d: %.1f", g.dps.weapon, g.dps.rate(g.world)), x, y)
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"testing"
+
+	"example/hello/core"
+)
+
+func TestDamageStatsOnlyInPracticeOrDebug(t *testing.T) {
+	tests := []struct {
+		name     string
+		debug    bool
+		practice int
+		want     bool
+	}{
+		{"normal run", false, 0, false},
+		{"debug overlay", true, 0, true},
+		{"practice run", false, 3, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, _ := newTestGame(t)
+			g.debug, g.practice = tt.debug, tt.practice
+			g.Publish(core.Event{Kind: core.EventHit, Level: 1, Amount: 10})
+			if got := len(g.dps.hits) == 1 && len(g.damageNumbers) == 1; got != tt.want {
+				t.Errorf("hit measured: %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDPSMeter(t *testing.T) {
+	g, _ := newTestGame(t)
+	g.practice = 1
+	w := g.world
+	hit := func(level, amount int) {
+		g.Publish(core.Event{Kind: core.EventHit, Level: level, Amount: amount})
+	}
+
+	hit(1, 10)
+	w.Time += w.Ticks(1)
+	hit(1, 15)
+	if got := g.dps.rate(w); got != 25/dpsWindow {
+		t.Errorf("rate is %g, want %g", got, 25/dpsWindow)
+	}
+	// The first hit leaves the window
+	w.Time += w.Ticks(dpsWindow) - w.Ticks(1)
+	if got := g.dps.rate(w); got != 15/dpsWindow {
+		t.Errorf("rate is %g once the first hit is old, want %g", got, 15/dpsWindow)
+	}
+	// A new weapon starts from nothing
+	hit(2, 7)
+	if g.dps.weapon != 2 || g.dps.rate(w) != 7/dpsWindow {
+		t.Errorf("meter is for weapon %d at %g after switching, want weapon 2 at %g", g.dps.weapon, g.dps.rate(w), 7/dpsWindow)
+	}
+}
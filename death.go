@@ -0,0 +1,66 @@
+package main
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/colorm"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"example/hello/core"
+)
+
+// deathShot is the frame a run ended on, kept to show behind the results.
+type deathShot struct {
+	img    *ebiten.Image
+	killer *core.Asteroid // The hit it was taken for
+	ox     float64        // Camera offset it was drawn with
+}
+
+// updateDeathShot copies the frame drawn so far the first time it is drawn
+// after the ship is hit.
+func (g *Game) updateDeathShot(screen *ebiten.Image, ox float64) {
+	d := &g.deathShot
+	if g.world.Killer == nil || g.world.Killer == d.killer {
+		return
+	}
+	b := screen.Bounds()
+	if d.img == nil || d.img.Bounds() != b {
+		d.img = ebiten.NewImage(b.Dx(), b.Dy())
+	}
+	d.img.Clear()
+	d.img.DrawImage(screen, nil)
+	d.killer, d.ox = g.world.Killer, ox
+}
+
+// drawDeathShot draws the frozen frame in grey, with the asteroid that hit
+// the ship outlined and a line from it to the ship.
+func (g *Game) drawDeathShot(screen *ebiten.Image) {
+	d := &g.deathShot
+	if d.img == nil || d.killer == nil || d.killer != g.world.Killer {
+		return
+	}
+	var cm colorm.ColorM
+	cm.ChangeHSV(0, 0.15, 0.7)
+	colorm.DrawImage(screen, d.img, cm, nil)
+
+	red := color.RGBA{255, 40, 40, 255}
+	k := d.killer
+	kx, ky := k.X+d.ox+k.Width/2, k.Y+k.Height/2
+	for i, p := range k.Shape {
+		q := k.Shape[(i+1)%len(k.Shape)]
+		vector.StrokeLine(screen, float32(kx+p.X), float32(ky+p.Y), float32(kx+q.X), float32(ky+q.Y), 2, red, true)
+	}
+
+	// To whichever copy of the ship it hit
+	p := &g.world.Player
+	sx, sy := 0.0, p.Y+p.Height/2
+	best := math.Inf(1)
+	for _, px := range g.world.PlayerCopies(p.X) {
+		if x := px + d.ox + p.Width/2; math.Abs(x-kx) < best {
+			sx, best = x, math.Abs(x-kx)
+		}
+	}
+	vector.StrokeLine(screen, float32(kx), float32(ky), float32(sx), float32(sy), 1, red, true)
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+const (
+	frameGraphSize   = 120              // Frames kept in the frame-time graph
+	frameGraphHeight = 60               // Pixel height of the budget line
+	frameBudget      = time.Second / 60 // Frame time that maps to the budget line
+	debugWidth       = 160              // Pixel width of the readout column
+)
+
+// frameGraph is a ring buffer of recent frame durations.
+type frameGraph struct {
+	samples [frameGraphSize]time.Duration
+	next    int
+	last    time.Time
+}
+
+// record notes that a frame was drawn at now.
+func (f *frameGraph) record(now time.Time) {
+	if !f.last.IsZero() {
+		f.samples[f.next] = now.Sub(f.last)
+		f.next = (f.next + 1) % frameGraphSize
+	}
+	f.last = now
+}
+
+// draw renders the graph with its bottom-left corner at (x, y), oldest
+// sample first. Bars over budget are red.
+func (f *frameGraph) draw(screen *ebiten.Image, x, y float64) {
+	ebitenutil.DrawRect(screen, x, y-2*frameGraphHeight, frameGraphSize, 2*frameGraphHeight, color.RGBA{0, 0, 0, 128})
+	for i := 0; i < frameGraphSize; i++ {
+		d := f.samples[(f.next+i)%frameGraphSize]
+		h := min(float64(d)/float64(frameBudget)*frameGraphHeight, 2*frameGraphHeight)
+		clr := color.RGBA{0, 200, 0, 255}
+		if d > frameBudget {
+			clr = color.RGBA{220, 0, 0, 255}
+		}
+		ebitenutil.DrawRect(screen, x+float64(i), y-h, 1, h, clr)
+	}
+	ebitenutil.DrawRect(screen, x, y-frameGraphHeight, frameGraphSize, 1, color.RGBA{255, 255, 0, 255})
+	ebitenutil.DebugPrintAt(screen, "16.6ms", int(x)+frameGraphSize+4, int(y-frameGraphHeight)-8)
+}
+
+func (g *Game) drawDebug(screen *ebiten.Image) {
+	hud := g.hud()
+	x := hud.right(debugWidth)
+	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("TPS: %.1f  FPS: %.1f", ebiten.ActualTPS(), ebiten.ActualFPS()), x, hud.top(0))
+	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Asteroids: %d/%d", len(g.world.Asteroids), g.maxAsteroids), x, hud.top(1))
+	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Bullets:   %d/%d", len(g.world.Bullets), g.maxBullets), x, hud.top(2))
+	g.drawDPS(screen, x, hud.top(3))
+	g.drawStats(screen, x, hud.top(4))
+	g.drawDifficulty(screen, x, hud.top(6))
+	// The passes so far this frame, which are every batched one
+	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Draws: %d for %d shapes", frameDraws.calls, frameDraws.shapes), x, hud.top(8))
+	if g.devErr != nil {
+		hud.bottomLeft(screen, fmt.Sprintf("Reload failed:\n%v", g.devErr), 0)
+	}
+	if g.showFrameGraph {
+		g.frameGraph.draw(screen, float64(x-10), float64(screenHeight-hud.margin))
+	}
+}
+
+// drawDifficulty shows where an endless run is on the difficulty curve.
+func (g *Game) drawDifficulty(screen *ebiten.Image, x, y int) {
+	d, ok := g.world.Difficulty()
+	if !ok {
+		return
+	}
+	sizes := make([]string, len(d.SizeWeights))
+	for i, w := range d.SizeWeights {
+		sizes[i] = fmt.Sprintf("%.3g", w)
+	}
+	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Spawn %.2fs  Rock x%.2f", d.SpawnInterval, d.SpeedScale), x, y)
+	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Form %.0f%%  Size %s", d.Formations*100, strings.Join(sizes, "/")), x, y+16)
+}
+
+// drawStats shows the player's resolved stats, as Step sees them this tick.
+func (g *Game) drawStats(screen *ebiten.Image, x, y int) {
+	s := g.world.Stats()
+	fire := "-"
+	if s.AutoFireTicks > 0 {
+		fire = fmt.Sprint(s.AutoFireTicks)
+	}
+	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Speed %.0f  Shot %.0f", s.PlayerSpeed, s.BulletSpeed), x, y)
+	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Fire %st  Aim %dt  x%d", fire, s.AimedFireTicks, len(s.Muzzles)), x, y+16)
+}
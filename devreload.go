@@ -0,0 +1,123 @@
+//go:build dev
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	devBuild         = true
+	devPollInterval  = 500 * time.Millisecond
+	devChangedBuffer = 16
+)
+
+// fileWatcher polls files for changes so they can be reloaded mid-run.
+type fileWatcher struct {
+	changed chan string
+}
+
+// startFileWatcher watches paths in the background. A path that doesn't
+// exist yet is picked up once it appears, and one that disappears counts as
+// a change.
+func startFileWatcher(paths []string) *fileWatcher {
+	w := &fileWatcher{changed: make(chan string, devChangedBuffer)}
+	go func() {
+		modTimes := make(map[string]time.Time)
+		for _, p := range paths {
+			if fi, err := os.Stat(p); err == nil {
+				modTimes[p] = fi.ModTime()
+			}
+		}
+		for range time.Tick(devPollInterval) {
+			for _, p := range paths {
+				fi, err := os.Stat(p)
+				last, seen := modTimes[p]
+				switch {
+				case err != nil && seen:
+					delete(modTimes, p)
+				case err == nil && !fi.ModTime().Equal(last):
+					modTimes[p] = fi.ModTime()
+				default:
+					continue
+				}
+				select {
+				case w.changed <- p:
+				default:
+					// Reloads are already queued; they'll read the latest file anyway
+				}
+			}
+		}
+	}()
+	return w
+}
+
+// updateDevReload reloads whatever changed since the last tick. A file
+// that fails to load leaves the previous values in place, with the error
+// shown in the debug overlay.
+func (g *Game) updateDevReload() {
+	if g.watcher == nil {
+		return
+	}
+	for {
+		select {
+		case path := <-g.watcher.changed:
+			if path == g.tuningPath {
+				g.reloadTuning()
+			} else {
+				g.reloadSprite(path)
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (g *Game) reloadTuning() {
+	t, err := loadTuningFile(g.tuningPath, g.fieldWidth())
+	g.devErr = err
+	if err != nil {
+		g.pushToast(tr("event.reload_failed"))
+		return
+	}
+	g.tuning = t
+	if g.daily == "" {
+		g.world.Config.Tuning = t
+	}
+	g.pushToast(tr("event.tuning_reloaded"))
+}
+
+// reloadSprite swaps in the sprite read from path. The loader would quietly
+// fall back to another copy of a file that fails to decode, so the changed
+// file is checked first and a bad one keeps the current sprite.
+func (g *Game) reloadSprite(path string) {
+	if g.loading != nil {
+		return // The loader is still reading; it will see the new file
+	}
+	name := filepath.Base(path)
+	data, err := os.ReadFile(path)
+	if err == nil {
+		_, err = decodeAsset(name, data)
+	}
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		g.devErr = fmt.Errorf("%s: %w", path, err)
+		g.pushToast(trf("event.sprite_reload_failed", name))
+		return
+	}
+	// The file decodes or was removed, so load it the usual way in case a
+	// copy elsewhere takes precedence
+	img, err := g.newAssetLoader().image(name)
+	if err != nil {
+		g.devErr = err
+		g.pushToast(trf("event.sprite_reload_failed", name))
+		return
+	}
+	g.sprites.set(name, img)
+	g.devErr = nil
+	g.pushToast(trf("event.sprite_reloaded", name))
+}
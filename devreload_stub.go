@@ -0,0 +1,12 @@
+//go:build !dev
+
+package main
+
+const devBuild = false
+
+// fileWatcher does nothing unless the game is built with -tags dev.
+type fileWatcher struct{}
+
+func startFileWatcher(paths []string) *fileWatcher { return nil }
+
+func (g *Game) updateDevReload() {}
@@ -0,0 +1,156 @@
+//go:build discord
+
+package main
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+)
+
+// discordAppID is the Discord application the presence is published under.
+// Set it at build time with -ldflags "-X main.discordAppID=...".
+var discordAppID = ""
+
+const (
+	presenceInterval = 15 * time.Second // Discord rate-limits activity updates
+	presenceTimeout  = 5 * time.Second  // Per connect, read or write
+)
+
+// richPresence publishes activity to a local Discord client. All IPC runs on
+// its own goroutine; set only hands over the latest activity and never
+// blocks.
+type richPresence struct {
+	updates chan presence
+	last    presence
+}
+
+func startRichPresence() *richPresence {
+	rp := &richPresence{updates: make(chan presence, 1)}
+	if discordAppID == "" {
+		slog.Info("discord: no application ID built in; presence disabled")
+		return rp
+	}
+	go rp.run()
+	return rp
+}
+
+func (rp *richPresence) set(p presence) {
+	if p == rp.last {
+		return
+	}
+	rp.last = p
+	// Replace any activity the goroutine hasn't picked up yet
+	select {
+	case <-rp.updates:
+	default:
+	}
+	select {
+	case rp.updates <- p:
+	default:
+	}
+}
+
+func (rp *richPresence) run() {
+	var conn io.ReadWriteCloser
+	var sent time.Time
+	for p := range rp.updates {
+		if wait := presenceInterval - time.Since(sent); wait > 0 {
+			time.Sleep(wait)
+			// Skip to whatever is newest after the wait
+			select {
+			case p = <-rp.updates:
+			default:
+			}
+		}
+		sent = time.Now()
+		if conn == nil {
+			c, err := connectDiscord()
+			if err != nil {
+				slog.Debug("discord: not connected", "err", err)
+				continue // Discord isn't running; try again next update
+			}
+			conn = c
+		}
+		if err := sendActivity(conn, p); err != nil {
+			slog.Warn("discord: lost connection", "err", err)
+			conn.Close()
+			conn = nil
+		}
+	}
+}
+
+// Opcodes of the IPC framing.
+const (
+	opHandshake = 0
+	opFrame     = 1
+)
+
+func connectDiscord() (io.ReadWriteCloser, error) {
+	conn, err := dialDiscord()
+	if err != nil {
+		return nil, err
+	}
+	handshake := map[string]any{"v": 1, "client_id": discordAppID}
+	if err := exchange(conn, opHandshake, handshake); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+func sendActivity(conn io.ReadWriteCloser, p presence) error {
+	activity := map[string]any{"details": p.details}
+	if p.state != "" {
+		activity["state"] = p.state
+	}
+	if !p.start.IsZero() {
+		activity["timestamps"] = map[string]any{"start": p.start.Unix()}
+	}
+	return exchange(conn, opFrame, map[string]any{
+		"cmd":   "SET_ACTIVITY",
+		"args":  map[string]any{"pid": os.Getpid(), "activity": activity},
+		"nonce": fmt.Sprint(time.Now().UnixNano()),
+	})
+}
+
+// exchange writes one frame and reads the reply, failing on a timeout or
+// an error reply.
+func exchange(conn io.ReadWriteCloser, op uint32, payload any) error {
+	if d, ok := conn.(interface{ SetDeadline(time.Time) error }); ok {
+		d.SetDeadline(time.Now().Add(presenceTimeout))
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	header := make([]byte, 8)
+	binary.LittleEndian.PutUint32(header[0:], op)
+	binary.LittleEndian.PutUint32(header[4:], uint32(len(body)))
+	if _, err := conn.Write(append(header, body...)); err != nil {
+		return err
+	}
+
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return err
+	}
+	reply := make([]byte, binary.LittleEndian.Uint32(header[4:]))
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return err
+	}
+	var r struct {
+		Evt  string `json:"evt"`
+		Data struct {
+			Message string `json:"message"`
+		} `json:"data"`
+	}
+	if json.Unmarshal(reply, &r) == nil && r.Evt == "ERROR" {
+		return errors.New("discord: " + r.Data.Message)
+	}
+	return nil
+}
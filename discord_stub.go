@@ -0,0 +1,10 @@
+//go:build !discord
+
+package main
+
+// richPresence does nothing unless the game is built with -tags discord.
+type richPresence struct{}
+
+func startRichPresence() *richPresence { return &richPresence{} }
+
+func (rp *richPresence) set(p presence) {}
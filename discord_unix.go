@@ -0,0 +1,32 @@
+//go:build discord && !windows
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+)
+
+// dialDiscord finds the client's IPC socket in the usual temp directories.
+func dialDiscord() (io.ReadWriteCloser, error) {
+	var dirs []string
+	for _, env := range []string{"XDG_RUNTIME_DIR", "TMPDIR", "TMP", "TEMP"} {
+		if d := os.Getenv(env); d != "" {
+			dirs = append(dirs, d)
+		}
+	}
+	dirs = append(dirs, "/tmp")
+	for _, dir := range dirs {
+		for i := 0; i < 10; i++ {
+			path := filepath.Join(dir, fmt.Sprintf("discord-ipc-%d", i))
+			if conn, err := net.DialTimeout("unix", path, presenceTimeout); err == nil {
+				return conn, nil
+			}
+		}
+	}
+	return nil, errors.New("discord: no IPC socket found")
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"log/slog"
+
+	"example/hello/core"
+)
+
+// eventBus hands each simulation event to whoever subscribed to its kind,
+// in the order they subscribed.
+type eventBus struct {
+	handlers map[core.EventKind][]func(core.Event)
+}
+
+// Subscribe calls fn for every published event of the given kind.
+func (g *Game) Subscribe(kind core.EventKind, fn func(core.Event)) {
+	if g.events.handlers == nil {
+		g.events.handlers = make(map[core.EventKind][]func(core.Event))
+	}
+	g.events.handlers[kind] = append(g.events.handlers[kind], fn)
+}
+
+// Publish passes e to its kind's subscribers.
+func (g *Game) Publish(e core.Event) {
+	for _, fn := range g.events.handlers[e.Kind] {
+		fn(e)
+	}
+}
+
+// subscribeDefaults hooks up the game's own reactions to events: the
+// ticker, gamepad rumble, effects and ending the run.
+func (g *Game) subscribeDefaults() {
+	// Ticker
+	g.Subscribe(core.EventDodged, func(core.Event) { g.pushEvent(tr("event.close_call")) })
+	g.Subscribe(core.EventDestroyed, func(core.Event) { g.pushEvent(tr("event.destroyed")) })
+	g.Subscribe(core.EventWeaponUp, func(e core.Event) { g.pushEvent(trf("event.weapon_level", e.Level)) })
+	g.Subscribe(core.EventWaveStarted, func(e core.Event) { g.pushEvent(trf("event.wave", e.Level)) })
+
+	// Rumble
+	g.Subscribe(core.EventDestroyed, func(core.Event) { g.vibrate(rumbleDestroy) })
+	g.Subscribe(core.EventWeaponUp, func(core.Event) { g.vibrate(rumbleWeaponUp) })
+	g.Subscribe(core.EventPlayerHit, func(core.Event) { g.vibrate(rumbleHit) })
+
+	// Muzzle flashes
+	g.Subscribe(core.EventFired, g.onFired)
+
+	// Damage numbers and the DPS meter; a new weapon starts a fresh meter
+	g.Subscribe(core.EventHit, g.onHit)
+	g.Subscribe(core.EventWeaponUp, func(e core.Event) { g.dps = dpsMeter{weapon: e.Level} })
+
+	// Where the ship was hit, before the run can end and record it
+	g.Subscribe(core.EventPlayerHit, func(core.Event) { g.runHeat.hit(g.world) })
+
+	// End of the run, or the offer to continue it
+	g.Subscribe(core.EventPlayerHit, func(core.Event) {
+		if g.continuesLeft > 0 {
+			g.continueTimer = g.world.Ticks(continueWindow)
+		} else {
+			g.endRun()
+		}
+	})
+	g.Subscribe(core.EventStageCleared, func(core.Event) { g.endRun() })
+
+	// Milestones, for -loglevel debug
+	g.Subscribe(core.EventWaveStarted, func(e core.Event) { slog.Debug("wave started", "wave", e.Level, "tick", g.world.Time) })
+	g.Subscribe(core.EventPlayerHit, func(core.Event) {
+		slog.Debug("player hit", "tick", g.world.Time, "score", g.world.Score)
+	})
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"example/hello/core"
+)
+
+func TestEventsForAScriptedRun(t *testing.T) {
+	g, in := newTestGame(t, WithContinues(0))
+	var got []core.Event
+	for kind := core.EventDodged; kind <= core.EventFired; kind++ {
+		g.Subscribe(kind, func(e core.Event) { got = append(got, e) })
+	}
+
+	// Five still asteroids in a column over the ship, shot down one a
+	// second, which earns the next weapon
+	w := g.world
+	w.HoldSpawns, w.GraceUntil = true, 0
+	w.Asteroids = w.Asteroids[:0]
+	x := w.Player.X + w.Player.Width/2 - 15
+	for i := 0; i < 5; i++ {
+		w.AddAsteroid(x, 30, 0)
+		a := &w.Asteroids[i]
+		a.Y, a.PrevY = float64(100+50*i), float64(100+50*i)
+	}
+	for i := 0; i < 5; i++ {
+		in.press(inputFire)
+		updates(t, g, 60)
+	}
+	if len(w.Asteroids) != 0 {
+		t.Fatalf("%d asteroids survived the shots", len(w.Asteroids))
+	}
+	// Then one falls on the ship
+	w.AddAsteroid(w.Player.X, 30, core.DefaultTuning.AsteroidSpeed)
+	updates(t, g, 120)
+
+	var kinds []core.EventKind
+	for _, e := range got {
+		kinds = append(kinds, e.Kind)
+	}
+	kill := []core.EventKind{core.EventFired, core.EventHit, core.EventDestroyed}
+	var want []core.EventKind
+	for i := 0; i < 5; i++ {
+		want = append(want, kill...)
+	}
+	want = append(want, core.EventWeaponUp, core.EventPlayerHit)
+	if !reflect.DeepEqual(kinds, want) {
+		t.Fatalf("events were\n %v\nwant\n %v", kinds, want)
+	}
+	for _, e := range got {
+		switch e.Kind {
+		case core.EventHit:
+			if e.Level != 1 || e.Amount <= 0 {
+				t.Errorf("hit with weapon %d for %d damage, want weapon 1 and some damage", e.Level, e.Amount)
+			}
+		case core.EventWeaponUp:
+			if e.Level != 2 {
+				t.Errorf("weapon went up to %d, want 2", e.Level)
+			}
+		}
+	}
+
+	// The game's own subscribers ran too
+	if !w.GameOver || g.profile.Stats.GamesPlayed != 1 {
+		t.Error("the hit didn't end and record the run")
+	}
+}
+
+func TestSubscribersRunInOrder(t *testing.T) {
+	var g Game
+	var order []int
+	for i := 0; i < 3; i++ {
+		g.Subscribe(core.EventDodged, func(core.Event) { order = append(order, i) })
+	}
+	g.Subscribe(core.EventDestroyed, func(core.Event) { t.Error("a destroyed subscriber saw a dodge") })
+	g.Publish(core.Event{Kind: core.EventDodged})
+	if !reflect.DeepEqual(order, []int{0, 1, 2}) {
+		t.Errorf("subscribers ran in order %v", order)
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"math"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// Rumble strengths for game events. Keep them short: they should be felt,
+// not sat through.
+var (
+	rumbleHit      = rumble{magnitude: 1, duration: 300 * time.Millisecond}
+	rumbleWeaponUp = rumble{magnitude: 0.5, duration: 120 * time.Millisecond}
+	rumbleDestroy  = rumble{magnitude: 0.25, duration: 60 * time.Millisecond}
+)
+
+type rumble struct {
+	magnitude float64 // 0 to 1
+	duration  time.Duration
+}
+
+// vibrate rumbles every connected gamepad. Pads without vibration support
+// ignore it.
+func (g *Game) vibrate(r rumble) {
+	if g.settings.NoVibration {
+		return
+	}
+	opts := &ebiten.VibrateGamepadOptions{
+		Duration:        r.duration,
+		StrongMagnitude: r.magnitude,
+		WeakMagnitude:   r.magnitude,
+	}
+	for _, id := range ebiten.AppendGamepadIDs(nil) {
+		ebiten.VibrateGamepad(id, opts)
+	}
+}
+
+const (
+	stickDeadzone      = 0.2 // Stick deflection treated as centered
+	stickFireThreshold = 0.5 // Right stick deflection that fires in twin-stick mode
+)
+
+// gamepadStick reads a stick of the first standard-layout gamepad, scaled
+// so the edge of the deadzone reads as zero. ok is false when no stick is
+// deflected past the deadzone.
+func gamepadStick(h, v ebiten.StandardGamepadAxis) (x, y float64, ok bool) {
+	for _, id := range ebiten.AppendGamepadIDs(nil) {
+		if !ebiten.IsStandardGamepadLayoutAvailable(id) {
+			continue
+		}
+		x = ebiten.StandardGamepadAxisValue(id, h)
+		y = ebiten.StandardGamepadAxisValue(id, v)
+		mag := math.Hypot(x, y)
+		if mag < stickDeadzone {
+			return 0, 0, false
+		}
+		scale := math.Min(1, (mag-stickDeadzone)/(1-stickDeadzone)) / mag
+		return x * scale, y * scale, true
+	}
+	return 0, 0, false
+}
+
+// padInputs are the gamepad actions that stand for each input action.
+var padInputs = map[padAction]inputAction{
+	padFire:     inputFire,
+	padPause:    inputPause,
+	padContinue: inputContinue,
+	padRestart:  inputRestart,
+	padMenu:     inputMenu,
+}
+
+// gamepadSource reads every standard-layout gamepad through its bindings.
+// The left stick steers and the right stick aims.
+type gamepadSource struct {
+	g    *Game
+	pads padState
+}
+
+func (p *gamepadSource) read() inputState {
+	p.pads.update(&p.g.settings)
+	var s inputState
+	s.moveX, s.moveY = p.pads.moveX, p.pads.moveY
+	s.aimX, s.aimY, s.aiming = aimInput()
+	for pa, a := range padInputs {
+		s.held[a] = s.held[a] || p.pads.held[pa]
+		s.pressed[a] = s.pressed[a] || p.pads.justPressed(pa)
+	}
+	for _, id := range standardPads() {
+		for _, b := range inpututil.AppendJustPressedStandardGamepadButtons(id, nil) {
+			s.buttons = append(s.buttons, padPress{id: id, button: b})
+		}
+		if s.stepX == 0 && s.stepY == 0 {
+			s.stepX = padStep(id, ebiten.StandardGamepadButtonLeftLeft, ebiten.StandardGamepadButtonLeftRight)
+			s.stepY = padStep(id, ebiten.StandardGamepadButtonLeftTop, ebiten.StandardGamepadButtonLeftBottom)
+		}
+	}
+	if p.pads.used {
+		s.used, s.device = true, deviceGamepad
+	}
+	return s
+}
+
+// padStep is a menu step of -1 from neg or 1 from pos on gamepad id's
+// d-pad, repeating while the button is held.
+func padStep(id ebiten.GamepadID, neg, pos ebiten.StandardGamepadButton) int {
+	switch {
+	case repeats(inpututil.StandardGamepadButtonPressDuration(id, neg), ebiten.TPS()):
+		return -1
+	case repeats(inpututil.StandardGamepadButtonPressDuration(id, pos), ebiten.TPS()):
+		return 1
+	}
+	return 0
+}
+
+// aimInput returns the unit direction of the right stick in twin-stick
+// mode. firing is false while the stick is near the center.
+func aimInput() (dx, dy float64, firing bool) {
+	x, y, ok := gamepadStick(ebiten.StandardGamepadAxisRightStickHorizontal, ebiten.StandardGamepadAxisRightStickVertical)
+	if !ok {
+		return 0, 0, false
+	}
+	mag := math.Hypot(x, y)
+	// Compare the raw deflection, before the deadzone rescale
+	if mag*(1-stickDeadzone)+stickDeadzone < stickFireThreshold {
+		return 0, 0, false
+	}
+	return x / mag, y / mag, true
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+
+	"example/hello/core"
+)
+
+const maxGhostSamples = 18000 // Longer traces are downsampled to fit
+
+// ghostTrace is the recorded path of the player's best run for one kind of
+// playfield.
+type ghostTrace struct {
+	TuningVersion int       `json:"tuningVersion"`
+	TPS           int       `json:"tps"`
+	Stride        int       `json:"stride"` // Ticks between samples
+	Score         int       `json:"score"`
+	Points        []float32 `json:"points"` // Player x, y per sample
+	Tuning        string    `json:"tuning"` // Checksum of the tuning the run was played under
+}
+
+var ghostSchema = schema{version: 1}
+
+func ghostsDir() string {
+	return filepath.Join(dataDir(), "ghosts")
+}
+
+func ghostPath(profile, variant string) string {
+	return filepath.Join(ghostsDir(), profile+"."+variant+".json")
+}
+
+// runVariant names the playfield rules that make runs comparable.
+func (g *Game) runVariant() string {
+	if g.daily != "" {
+		return "daily-" + g.daily
+	}
+	v := "classic"
+	if g.worldWidth > float64(baseWidth) {
+		v = "wide"
+	}
+	if screenHeight == portraitHeight {
+		v += "-portrait"
+	}
+	// Extended fields race against ghosts of the same width
+	if g.spawnScale() != 1 {
+		v += fmt.Sprintf("-extend%g", g.fieldWidth())
+	}
+	if g.settings.Wrap {
+		v += "-wrap"
+	}
+	if g.settings.Mode != core.ModeEndless {
+		v += "-" + modeKey(g.settings.Mode)
+	}
+	if s := core.Ships[g.shipIndex()]; s != core.DefaultShip {
+		v += "-" + s.Name
+	}
+	// Runs at other speeds race against their own ghosts
+	ps, bs := speedScale(g.settings.PlayerSpeed), speedScale(g.settings.BulletSpeed)
+	if ps != 1 || bs != 1 {
+		v += fmt.Sprintf("-speed%gx%g", ps, bs)
+	}
+	// And runs with assists
+	if s := gameSpeed(g.settings.GameSpeed); s != 1 {
+		v += fmt.Sprintf("-gamespeed%g", s)
+	}
+	if g.settings.SmallHitbox {
+		v += "-smallhitbox"
+	}
+	if g.settings.AutoFire {
+		v += "-autofire"
+	}
+	// Mutated runs race against runs with the same mutators
+	v += mutatorSuffix(g.runMutators().keys())
+	// Scores without dodge points can't be compared with the rest
+	if g.settings.NoDodgeScore {
+		v += "-nododge"
+	}
+	// So do runs under a modified tuning file
+	if sum := g.tuningSum(); sum != "" {
+		v += "-tuned" + sum[:8]
+	}
+	return v
+}
+
+// loadGhost reads the best-run trace for the current profile and variant.
+// Traces recorded under different tunables or tick rates are ignored.
+func (g *Game) loadGhost() *ghostTrace {
+	var gt ghostTrace
+	if err := loadFile(ghostPath(g.profile.Name, g.runVariant()), ghostSchema, &gt); err != nil {
+		return nil
+	}
+	if gt.TuningVersion != core.TuningVersion || gt.Tuning != g.tuning.Checksum() || gt.TPS != ebiten.TPS() || gt.Stride < 1 {
+		slog.Debug("ghost recorded under different settings; ignoring it", "variant", g.runVariant())
+		return nil
+	}
+	return &gt
+}
+
+// recordGhostSample appends the player's position every recordStride
+// ticks. Once the recording is full it is downsampled in place and the
+// stride doubles, so however long the run it stays under maxGhostSamples.
+func (g *Game) recordGhostSample() {
+	if (g.world.Time-1)%g.recordStride != 0 {
+		return
+	}
+	g.recording = append(g.recording, float32(g.world.Player.X), float32(g.world.Player.Y))
+	if len(g.recording)/2 > maxGhostSamples {
+		g.recording = downsample(g.recording)
+		g.recordStride *= 2
+	}
+}
+
+// saveGhost keeps the run just finished if it beat the stored ghost.
+func (g *Game) saveGhost() error {
+	if g.restored || g.daily != "" || g.ghost != nil && g.world.Score <= g.ghost.Score {
+		return nil
+	}
+	gt := &ghostTrace{
+		TuningVersion: core.TuningVersion,
+		TPS:           ebiten.TPS(),
+		Stride:        g.recordStride,
+		Score:         g.world.Score,
+		Points:        slices.Clone(g.recording),
+		Tuning:        g.tuning.Checksum(),
+	}
+	if err := saveFile(ghostPath(g.profile.Name, g.runVariant()), ghostSchema, gt); err != nil {
+		return fmt.Errorf("ghost: %w", err)
+	}
+	g.ghost = gt
+	return nil
+}
+
+// downsample drops every second x, y pair, reusing points' storage.
+func downsample(points []float32) []float32 {
+	out := points[:0]
+	for i := 0; i+1 < len(points); i += 4 {
+		out = append(out, points[i], points[i+1])
+	}
+	return out
+}
+
+func deleteGhosts(profile string) error {
+	paths, _ := filepath.Glob(filepath.Join(ghostsDir(), profile+".*.json"))
+	var errs []error
+	for _, p := range paths {
+		if err := os.Remove(p); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// drawGhost draws the best run's ship where it was at this point of that
+// run. Once the recorded run has ended there is nothing to draw.
+func (g *Game) drawGhost(screen *ebiten.Image, ox float64) {
+	if g.ghost == nil || g.settings.HideGhost || g.world.Time == 0 {
+		return
+	}
+	i := (g.world.Time - 1) / g.ghost.Stride * 2
+	if i+1 >= len(g.ghost.Points) {
+		return
+	}
+	x, y := float64(g.ghost.Points[i]), float64(g.ghost.Points[i+1])
+	for _, px := range g.world.PlayerCopies(x) {
+		ebitenutil.DrawRect(screen, px+ox, y, g.world.Player.Width, g.world.Player.Height, color.RGBA{80, 160, 255, 80})
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"example/hello/core"
+)
+
+const (
+	heatmapCols  = 32
+	heatmapRows  = 24
+	heatmapCells = heatmapCols * heatmapRows
+	heatmapAlpha = 0.8 // Opacity of the most visited cell
+	heatmapDim   = 192 // Opacity, out of 255, of the black laid over the playfield under the map
+	deathMarker  = 4   // Half the width of the cross marking a cell the ship was hit in
+)
+
+// heatmap counts where the ship has been, on a coarse grid over the
+// playfield: the ticks it spent in each cell and the hits it took there.
+// Cells are fractions of the field, so fields of any size share a grid.
+type heatmap struct {
+	Visits [heatmapCells]int `json:"visits"` // Ticks spent in each cell, row by row from the top left
+	Hits   [heatmapCells]int `json:"hits"`   // Hits taken in each cell
+}
+
+// heatmapCell is the grid cell under the middle of the ship.
+func heatmapCell(w *core.World) int {
+	p := &w.Player
+	col := int((p.X + p.Width/2) / w.Config.Width * heatmapCols)
+	row := int((p.Y + p.Height/2) / w.Config.Height * heatmapRows)
+	col = max(0, min(col, heatmapCols-1))
+	row = max(0, min(row, heatmapRows-1))
+	return row*heatmapCols + col
+}
+
+// visit counts a tick spent where the ship is now.
+func (h *heatmap) visit(w *core.World) {
+	h.Visits[heatmapCell(w)]++
+}
+
+// hit counts a hit taken where the ship is now.
+func (h *heatmap) hit(w *core.World) {
+	h.Hits[heatmapCell(w)]++
+}
+
+// add folds another heatmap into h.
+func (h *heatmap) add(o *heatmap) {
+	for i := range h.Visits {
+		h.Visits[i] += o.Visits[i]
+		h.Hits[i] += o.Hits[i]
+	}
+}
+
+// draw darkens the screen and lays the grid over all of it, each cell
+// shading from cold to hot and clear to opaque with its share of the most
+// visited cell's ticks. Cells the ship was hit in are crossed out.
+func (h *heatmap) draw(screen *ebiten.Image) {
+	ebitenutil.DrawRect(screen, 0, 0, float64(screenWidth), float64(screenHeight), color.NRGBA{0, 0, 0, heatmapDim})
+	most := 0
+	for _, v := range h.Visits {
+		most = max(most, v)
+	}
+	cw, ch := float64(screenWidth)/heatmapCols, float64(screenHeight)/heatmapRows
+	for i, v := range h.Visits {
+		if v == 0 {
+			continue
+		}
+		f := float64(v) / float64(most)
+		x, y := float64(i%heatmapCols)*cw, float64(i/heatmapCols)*ch
+		c := color.NRGBA{
+			R: uint8(40 + 215*f),
+			G: uint8(90 - 30*f),
+			B: uint8(255 * (1 - f)),
+			A: uint8(255 * heatmapAlpha * f),
+		}
+		ebitenutil.DrawRect(screen, x, y, cw, ch, c)
+	}
+	for i, n := range h.Hits {
+		if n == 0 {
+			continue
+		}
+		x, y := float32((float64(i%heatmapCols)+0.5)*cw), float32((float64(i/heatmapCols)+0.5)*ch)
+		vector.StrokeLine(screen, x-deathMarker, y-deathMarker, x+deathMarker, y+deathMarker, 2, color.White, true)
+		vector.StrokeLine(screen, x-deathMarker, y+deathMarker, x+deathMarker, y-deathMarker, 2, color.White, true)
+	}
+}
+
+// recordHeatmap folds a finished run's heatmap into the profile's lifetime
+// one.
+func (p *Profile) recordHeatmap(h *heatmap) {
+	if p.Heatmap == nil {
+		p.Heatmap = &heatmap{}
+	}
+	p.Heatmap.add(h)
+}
+
+// drawRunHeatmap shows the run just finished as a heatmap, in place of the
+// results.
+func (g *Game) drawRunHeatmap(screen *ebiten.Image) {
+	g.runHeat.draw(screen)
+	drawCentered(screen, tr("heatmap.title"), g.hud().top(0))
+	drawCentered(screen, tr("heatmap.help"), g.hud().bottom(0))
+}
+
+func (g *Game) updateProfileStats() {
+	if g.readMenuInput(nil).cancel {
+		g.screen = screenTitle
+	}
+}
+
+// drawProfileStats lists the profile's stats over its lifetime heatmap.
+func (g *Game) drawProfileStats(screen *ebiten.Image) {
+	hud := g.hud()
+	if h := g.profile.Heatmap; h != nil {
+		h.draw(screen)
+	}
+	drawCentered(screen, tr("stats.title"), hud.top(0))
+	s := g.profile.Stats
+	for i, line := range []string{
+		trf("stats.games", s.GamesPlayed),
+		trf("stats.best", s.BestScore),
+		trf("stats.total", s.TotalScore),
+		trf("stats.destroyed", s.AsteroidsDestroyed),
+	} {
+		hud.topLeft(screen, line, i+2)
+	}
+	if g.profile.Heatmap == nil {
+		drawCentered(screen, tr("stats.no_heatmap"), screenHeight/2)
+	} else {
+		drawCentered(screen, tr("stats.heatmap"), hud.bottom(1))
+	}
+	drawCentered(screen, tr("stats.help"), hud.bottom(0))
+}
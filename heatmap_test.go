@@ -0,0 +1,59 @@
+package main
+
+import "testing"
+
+func TestHeatmapCell(t *testing.T) {
+	w := testWorld(1)
+	p := &w.Player
+	tests := []struct {
+		name string
+		x, y float64 // The ship's middle
+		want int
+	}{
+		{"top left", 1, 1, 0},
+		{"bottom right", 639, 479, heatmapCells - 1},
+		{"middle", 320, 240, 12*heatmapCols + 16},
+		{"off the left", -50, 100, 5 * heatmapCols},
+		{"off the bottom right", 700, 500, heatmapCells - 1},
+	}
+	for _, tt := range tests {
+		p.X, p.Y = tt.x-p.Width/2, tt.y-p.Height/2
+		if got := heatmapCell(w); got != tt.want {
+			t.Errorf("%s: cell %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestHeatmapCountsTheRun(t *testing.T) {
+	g, in := newTestGame(t, WithContinues(0))
+	in.state.moveX = -1 // Against the wall, where asteroids will find it
+	for !g.world.GameOver && g.world.Time < 60*600 {
+		updates(t, g, 1)
+	}
+	if !g.world.GameOver {
+		t.Fatal("the ship was never hit")
+	}
+	visits, hits := 0, 0
+	for i := range g.runHeat.Visits {
+		visits += g.runHeat.Visits[i]
+		hits += g.runHeat.Hits[i]
+	}
+	if visits != g.world.Time || hits != 1 || g.runHeat.Hits[heatmapCell(g.world)] != 1 {
+		t.Errorf("%d visits over %d ticks, %d hits, want one where the ship is", visits, g.world.Time, hits)
+	}
+	if h := g.profile.Heatmap; h == nil || *h != g.runHeat {
+		t.Error("the run wasn't added to the lifetime heatmap")
+	}
+
+	// A second run adds to the first
+	first := g.runHeat
+	g.reset()
+	for !g.world.GameOver && g.world.Time < 60*600 {
+		updates(t, g, 1)
+	}
+	for i := range first.Visits {
+		if got, want := g.profile.Heatmap.Visits[i], first.Visits[i]+g.runHeat.Visits[i]; got != want {
+			t.Fatalf("cell %d of the lifetime heatmap has %d visits, want %d", i, got, want)
+		}
+	}
+}
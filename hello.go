@@ -1,237 +1,1203 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
+	"image"
 	"image/color"
+	"log/slog"
+	"math"
 	"math/rand"
+	"os"
 	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"example/hello/core"
+)
+
+// The logical screen size. It starts at the profile's size and extend mode
+// widens it to match the window between runs.
+var (
+	screenWidth  = landscapeWidth
+	screenHeight = landscapeHeight
 )
 
 const (
-	screenWidth  = 640
-	screenHeight = 480
-	playerSpeed  = 5
-	bulletSpeed  = 7
-	asteroidSpeed = 7
+	defaultTPS = 60 // Simulation ticks per second
+
+	wideWorldWidth = 1280 // Playfield width in wide-field mode
+	cameraLerp     = 0.1  // Fraction of the distance to its target the camera moves per 1/60 s
+	cameraMargin   = 200  // Distance from a screen edge at which the camera starts following
+	lookAheadTime  = 0.2  // Seconds of the ship's motion the camera leads by
+	lookAheadMax   = 32   // Largest lead in pixels
+	lookAheadLerp  = 0.04 // Like cameraLerp, for the lead; slower so it doesn't jitter
+
+	continueWindow = 5.0 // Seconds a game over waits for the player to continue
+	retryLockout   = 1.0 // Seconds after a run ends before input can start another
+	shipCockpit    = 5   // Pixels the cockpit sticks out above the ship's hitbox
+	shieldDim      = 0.5 // Opacity of a shielded ship under reduced motion, in place of blinking
+	lethalShadow   = 3   // Pixels a lethal thing's drop shadow falls down and right
+
+	// Background color stops (0xRRGGBB), blended as progress goes from 0 to 1
+	backgroundStart  = 0x000014 // Deep blue
+	backgroundMid    = 0x1a0a2e // Purple
+	backgroundEnd    = 0x2e0a0a // Red
+	maxProgressScore = 500      // Score at which progress reaches 1
 )
 
+// Game adapts the core simulation to Ebiten: it gathers input, steps the
+// world, and draws it along with the menus and HUD.
 type Game struct {
-	player      Player
-	bullets     []Bullet
-	asteroids   []Asteroid
-	gameOver    bool
-	score       int
-	spawnTimer  int
-}
+	world      *core.World
+	paused     bool
+	worldWidth float64    // Wide-field playfield width; 0 plays on a field as wide as the screen
+	aspect     aspectMode // How the screen fits the window
+	camera     Camera
+	settings   Settings
 
-type Player struct {
-	x      float64
-	y      float64
-	width  float64
-	height float64
+	maxAsteroids int
+	maxBullets   int
+	tuning       core.Tuning // Balance values for every run this session
+	broadPhase   core.BroadPhase
+	spawnPolicy  core.SpawnPolicy // Hardcore always spawns uniformly
+
+	daily         string // Date of the daily challenge being played; empty for a normal run
+	practice      int    // Stage wave being practised, counted from 1; 0 for a normal run
+	continues     int    // Continues each run starts with
+	continuesLeft int
+	continueTimer int // Updates left to accept a continue; 0 when none is on offer
+
+	aimX, aimY float64     // Direction the ship last aimed in a twin-stick run
+	presses    pressBuffer // Fire and pause presses not yet acted on
+	ticker     eventTicker
+	toasts     []toast // Oldest first
+	tutorial   tutorial
+
+	ghost        *ghostTrace  // Best run to race against, if any
+	recording    []float32    // This run's player positions, x, y every recordStride ticks
+	recordStride int          // Ticks between recorded positions; doubles as a long run fills the recording
+	restored     bool         // Run was loaded from a save, so recording is incomplete
+	trace        *core.Replay // This run's inputs, to export; nil if it can't be replayed
+	playback     *runFile     // Shared run being watched, if any
+	runsMenu     runsMenu
+
+	screen       screenID
+	profile      *Profile
+	menuPointer  image.Point // Where the mouse was last tick, so only moving it takes menu focus
+	profileMenu  profileMenu
+	titleMenu    menuList
+	optionsMenu  menuList
+	assistsMenu  menuList
+	mutatorsMenu menuList
+	practiceMenu menuList
+	mutators     mutatorSet // Chosen for endless runs this session
+	controlsMenu controlsMenu
+	shipMenu     menuList   // Its cursor is the ship highlighted on the selection screen
+	shipLocked   bool       // Tried to pick a locked ship; its requirement is shown
+	wrapOverride *bool      // Set from the command line; beats the profile setting
+	modeOverride *core.Mode // Likewise for the game mode
+	seed         *int64     // Every run uses this seed; nil for a fresh one each run
+	saveErr      error
+
+	confirmingQuit bool // Window close was requested mid-run
+
+	inputs     inputSource // Where the player's input comes from; every device unless replaced
+	input      inputState  // This tick's input, read from inputs once per update
+	lastDevice inputDevice // What the player last touched, for naming buttons in prompts
+	retryLock  int         // Ticks left before a finished run accepts input
+	sprites    sprites
+	loading    *assetLoad // Assets still loading behind the splash; nil once they're in
+
+	renderPasses [layerCount][]renderPass
+	assetsDir    string      // Asset overrides from the command line, if any
+	stress       *stressTest // Set for a -stress run
+
+	lastTick time.Time // When the simulation last advanced, for interpolation
+	lag      lagGuard
+	lagPause bool // The run paused itself after a stall
+	debug    bool // Show the debug overlay
+
+	tuningPath string       // Tuning file given on the command line, if any
+	watcher    *fileWatcher // Dev builds with -dev: files to reload when they change
+	devErr     error        // Why the last reload failed
+	console    console      // Dev builds: the command console
+	cheated    bool         // The console changed this run, so it isn't recorded
+
+	frameGraph     frameGraph
+	showFrameGraph bool
+
+	fx                *rand.Rand // Cosmetic randomness; never the world's RNG
+	trails            bulletTrails
+	runHeat           heatmap        // Where the ship has been this run
+	showHeatmap       bool           // The results give way to runHeat
+	shapes            shapeBatch     // Shared by the passes that batch their drawing, one at a time
+	damageNumbers     []damageNumber // Debug overlay: recent hits
+	muzzleFlashes     []muzzleFlash
+	deathShot         deathShot
+	screenshotPending bool // F12 was pressed; save the next frame drawn
+	dps               dpsMeter
+
+	events   eventBus
+	presence *richPresence
+	versus   *versusMatch // LAN match in progress, if any
+
+	broadcaster *broadcaster   // Streams each tick to spectators; nil when not broadcasting
+	spectator   *spectatorLink // Set while watching someone else's game
 }
 
-type Bullet struct {
-	x      float64
-	y      float64
-	active bool
+type Settings struct {
+	Wrap   bool `json:"wrap"`   // Ship leaves one side of the world and reappears on the other
+	Chunky bool `json:"chunky"` // Draw positions as of the last tick instead of interpolating
+
+	HideTicker bool   `json:"hideTicker"` // Don't list recent events under the score
+	Language   string `json:"language"`   // UI language code; empty means English
+	HideGhost  bool   `json:"hideGhost"`  // Don't show the best run's ghost ship
+
+	DiscordPresence bool `json:"discordPresence"` // Show what we're doing on Discord
+	NoVibration     bool `json:"noVibration"`     // Don't rumble gamepads
+	TwinStick       bool `json:"twinStick"`       // Aim and fire with the gamepad's right stick
+	NoLookAhead     bool `json:"noLookAhead"`     // Don't lead the ship with the camera
+	PixelSnap       bool `json:"pixelSnap"`       // Draw everything at whole-pixel positions
+	ReducedMotion   bool `json:"reducedMotion"`   // No flashes, blinking, trails or camera lead; play is unchanged
+
+	PadLayouts map[string]padLayout `json:"padLayouts,omitempty"` // Gamepad bindings by device GUID
+
+	CPUSkill int `json:"cpuSkill"` // Index into core.BotSkills for versus CPU
+	Ship     int `json:"ship"`     // Index into core.Ships
+
+	Mode core.Mode `json:"mode"` // Endless, a finite stage, or hardcore
+
+	// Multipliers over the base speeds; read them through speedScale
+	PlayerSpeed float64 `json:"playerSpeed,omitempty"`
+	BulletSpeed float64 `json:"bulletSpeed,omitempty"`
+
+	HUDMargin int `json:"hudMargin,omitempty"` // Inset of the HUD from the screen edges; read it through hudMargin
+
+	NoDodgeScore bool `json:"noDodgeScore"` // Points come only from kills, not from asteroids dodged
+	Inertia      bool `json:"inertia"`      // The ship speeds up and slows down instead of snapping to full speed
+
+	// Assists; runs played with any on keep their own leaderboard
+	GameSpeed   float64 `json:"gameSpeed,omitempty"` // Multiple of normal speed, an assist only below 1; read it through gameSpeed
+	SmallHitbox bool    `json:"smallHitbox"`         // Only the middle of the ship can be hit
+	AutoFire    bool    `json:"autoFire"`            // Keep firing without holding the button
 }
 
-type Asteroid struct {
+// Camera is the top-left corner of the visible window in world space.
+type Camera struct {
 	x      float64
-	y      float64
-	width  float64
-	height float64
-	active bool
+	prevX  float64
+	follow float64 // Where it would be just keeping the ship inside the margins
+	look   float64 // How far it leads the ship in the direction it is moving
 }
 
 func (g *Game) Update() error {
-	if g.gameOver {
-		if inpututil.IsKeyJustPressed(ebiten.KeyR) {
+	g.input = g.inputs.read()
+	if g.loading != nil {
+		return g.updateLoading()
+	}
+	if g.stress != nil {
+		return g.updateStress()
+	}
+	return g.update()
+}
+
+func (g *Game) update() error {
+	// Stress runs are slow on purpose
+	stalled := g.lag.check(time.Duration(g.tickSeconds()*float64(time.Second))) && g.stress == nil
+	g.updateInputDevice()
+	if g.input.justPressed(inputDebug) {
+		g.debug = !g.debug
+		g.frameGraph.last = time.Time{} // Don't count the time spent hidden as a frame
+	}
+	if g.debug && g.input.justPressed(inputFrameGraph) {
+		g.showFrameGraph = !g.showFrameGraph
+	}
+	if g.input.justPressed(inputScreenshot) {
+		g.screenshotPending = true
+	}
+	g.updatePresence()
+	g.updateDevReload()
+	if g.updateConsole() {
+		return nil
+	}
+
+	if ebiten.IsWindowBeingClosed() {
+		if !g.runActive() {
+			return g.quit()
+		}
+		g.confirmingQuit = true
+	}
+	if g.confirmingQuit {
+		return g.updateQuitConfirm()
+	}
+
+	switch g.screen {
+	case screenProfiles:
+		g.updateProfiles()
+		return nil
+	case screenTitle:
+		g.updateTitle()
+		return nil
+	case screenOptions:
+		g.updateOptions()
+		return nil
+	case screenControls:
+		g.updateControls()
+		return nil
+	case screenShips:
+		g.updateShips()
+		return nil
+	case screenVersus:
+		g.updateVersus()
+		return nil
+	case screenSpectate:
+		g.updateSpectate()
+		return nil
+	case screenRuns:
+		g.updateRuns()
+		return nil
+	case screenAssists:
+		g.updateAssists()
+		return nil
+	case screenMutators:
+		g.updateMutators()
+		return nil
+	case screenPractice:
+		g.updatePractice()
+		return nil
+	case screenStats:
+		g.updateProfileStats()
+		return nil
+	}
+	if g.playback != nil {
+		return g.updatePlayback()
+	}
+	g.presses.record(g.actionJustPressed)
+
+	if g.world.GameOver {
+		if g.continueTimer > 0 {
+			g.continueTimer--
+			switch {
+			case g.input.justPressed(inputContinue):
+				g.continueRun()
+			case g.continueTimer == 0:
+				g.endRun()
+			}
+			return nil
+		}
+		if g.retryLock > 0 {
+			g.retryLock--
+			return nil
+		}
+		switch {
+		case g.retryPressed():
 			g.reset()
+		case g.input.justPressed(inputHeatmap):
+			g.showHeatmap = !g.showHeatmap
+		case g.input.justPressed(inputExport) && g.canExportRun():
+			if path, err := g.exportRun(); err != nil {
+				slog.Error("run export failed", "err", err)
+				g.pushToast(tr("event.export_failed"))
+			} else {
+				slog.Info("run exported", "path", path)
+				g.pushToast(tr("event.exported"))
+				g.trace = nil // Once is enough
+			}
+		case g.input.justPressed(inputMenu):
+			g.daily = ""
+			g.practice = 0
+			g.screen = screenTitle
 		}
 		return nil
 	}
 
-	// Player movement
-	if ebiten.IsKeyPressed(ebiten.KeyLeft) && g.player.x > 0 {
-		g.player.x -= playerSpeed
+	if g.presses.take(actionPause) {
+		g.paused = !g.paused
+		g.lagPause = false
+	}
+	if stalled && !g.paused {
+		// Sit out the catch-up ticks rather than let the field jump ahead
+		g.paused, g.lagPause = true, true
+	}
+	if g.input.justPressed(inputSaveState) {
+		if err := g.SaveState(); err != nil {
+			g.pushToast(tr("event.save_failed"))
+		} else {
+			g.pushToast(tr("event.saved"))
+		}
+	}
+	if g.input.justPressed(inputLoadState) {
+		if err := g.LoadState(); err != nil {
+			g.pushToast(tr("event.load_failed"))
+		} else {
+			g.pushToast(tr("event.loaded"))
+		}
 	}
-	if ebiten.IsKeyPressed(ebiten.KeyRight) && g.player.x < screenWidth-g.player.width {
-		g.player.x += playerSpeed
+	if g.practice > 0 && g.input.justPressed(inputRestart) {
+		g.reset() // Practice restarts at once, even mid-wave
+		return nil
 	}
-	if ebiten.IsKeyPressed(ebiten.KeyUp) && g.player.y > 0 {
-		g.player.y -= playerSpeed
+	if g.paused {
+		return nil
 	}
-	if ebiten.IsKeyPressed(ebiten.KeyDown) && g.player.y < screenHeight-g.player.height {
-		g.player.y += playerSpeed
+	g.camera.prevX = g.camera.x
+	g.lastTick = time.Now()
+
+	in := g.frameInput()
+	if g.world.Config.Mode == core.ModeTwinStick {
+		in = g.twinStickInput()
 	}
+	g.tick(in)
+	return nil
+}
 
-	// Shoot bullets
-	if inpututil.IsKeyJustPressed(ebiten.KeySpace) {
-		g.bullets = append(g.bullets, Bullet{
-			x:      g.player.x + g.player.width/2 - 2,
-			y:      g.player.y,
-			active: true,
-		})
+// tick steps the run with in and brings everything that follows the
+// world along: events, trails, the tutorial, the ghost and the camera.
+func (g *Game) tick(in core.FrameInput) {
+	if g.trace != nil {
+		g.trace.Record(g.world, in)
 	}
+	events := g.world.Step(in)
+	g.runHeat.visit(g.world) // Before the events, which can end the run and record it
+	for _, e := range events {
+		g.Publish(e)
+	}
+	g.trails.update(g.world.Bullets)
+	g.updateTutorial()
+	g.recordGhostSample()
+	g.updateCamera()
+	if g.broadcaster != nil {
+		g.broadcaster.send(newSnapshot(g.world, g.camera.x))
+	}
+}
 
-	// Update bullets
-	for i := range g.bullets {
-		if g.bullets[i].active {
-			g.bullets[i].y -= bulletSpeed
-			if g.bullets[i].y < 0 {
-				g.bullets[i].active = false
-			}
+// frameInput gathers this tick's input for the simulation.
+func (g *Game) frameInput() core.FrameInput {
+	var in core.FrameInput
+	in.MoveX, in.MoveY = g.input.moveX, g.input.moveY
+	in.FirePressed = g.presses.take(actionFire)
+	in.FireHeld = g.input.held[inputFire]
+	if g.settings.TwinStick {
+		in.AimX, in.AimY, in.Aiming = g.input.aimX, g.input.aimY, g.input.aiming
+	}
+	return in
+}
+
+// getReady counts down the grace period at the start of a run.
+func getReady(w *core.World) string {
+	tps := w.Config.TPS
+	return trf("hud.get_ready", (w.GraceUntil-w.Time+tps-1)/tps)
+}
+
+// tickSeconds is the simulated duration of one tick.
+func (g *Game) tickSeconds() float64 {
+	return 1 / float64(ebiten.TPS())
+}
+
+// interpolation returns how far Draw is between the last tick and the next,
+// from 0 to 1.
+func (g *Game) interpolation() float64 {
+	if g.settings.Chunky || g.paused || g.world.GameOver {
+		return 1
+	}
+	t := time.Since(g.lastTick).Seconds() * float64(ebiten.TPS())
+	return math.Max(0, math.Min(t, 1))
+}
+
+// lerpPos interpolates a coordinate between ticks for drawing. Jumps of
+// more than half the world, such as wrapping around the seam, are drawn
+// without easing.
+func (g *Game) lerpPos(prev, cur, t float64) float64 {
+	if math.Abs(cur-prev) > g.world.Config.Width/2 {
+		return g.snap(cur)
+	}
+	return g.snap(prev + (cur-prev)*t)
+}
+
+// snap rounds a drawn coordinate to a whole pixel if the player asked for
+// crisp pixels. The simulation keeps its own fractional positions.
+func (g *Game) snap(v float64) float64 {
+	if g.settings.PixelSnap {
+		return math.Round(v)
+	}
+	return v
+}
+
+// loadTuningFile reads and validates a tuning override for a playfield
+// worldWidth wide.
+func loadTuningFile(path string, worldWidth float64) (core.Tuning, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return core.Tuning{}, err
+	}
+	t, err := core.LoadTuning(data)
+	if err == nil {
+		err = t.Validate(worldWidth, float64(screenHeight))
+	}
+	if err != nil {
+		slog.Error("tuning file rejected", "path", path, "err", err)
+		return core.Tuning{}, err
+	}
+	slog.Info("tuning loaded", "path", path, "checksum", t.Checksum())
+	return t, nil
+}
+
+// tuningSum is the checksum of the session's tuning if it was modified,
+// or empty under the defaults.
+func (g *Game) tuningSum() string {
+	if g.tuning.Equal(core.DefaultTuning) {
+		return ""
+	}
+	return g.tuning.Checksum()
+}
+
+// continueRun spends a continue to revive the ship mid-run.
+func (g *Game) continueRun() {
+	g.continuesLeft--
+	g.continueTimer = 0
+	if g.trace != nil {
+		g.trace.RecordRevive(g.world)
+	}
+	g.world.Revive()
+	g.pushEvent(tr("event.continued"))
+}
+
+// endRun records the finished run on the active profile.
+func (g *Game) endRun() {
+	if g.playback != nil {
+		return // Someone else's run
+	}
+	g.continueTimer = 0
+	g.retryLock = g.world.Ticks(retryLockout)
+	slog.Info("run ended", "score", g.world.Score, "destroyed", g.world.Destroyed, "dodged", g.world.Dodged, "cheated", g.cheated, "practice", g.practice)
+	if g.practice > 0 {
+		g.retryLock = 0 // Straight back to the wave
+		return
+	}
+	if g.cheated {
+		return
+	}
+	g.profile.recordHeatmap(&g.runHeat)
+	if g.daily != "" {
+		g.profile.recordDaily(g.daily, g.world.Score, g.world.Destroyed)
+		g.announceUnlocks(g.profile.unlockShips())
+		g.saveErr = g.profile.save()
+		return
+	}
+	key := boardKey(g.world.Config)
+	board := g.profile.Leaderboards[key]
+	if g.world.Score > 0 && (len(board) == 0 || g.world.Score > board[0].Score) {
+		g.pushToast(trf("toast.high_score", g.world.Score))
+	}
+	entry := leaderboardEntry{Score: g.world.Score, Tuning: g.tuningSum(), KillsOnly: g.world.Config.NoDodgeScore, Mutators: g.world.Config.Mutators}
+	g.profile.recordRun(key, entry, g.world.Destroyed)
+	g.announceUnlocks(g.profile.unlockShips())
+	g.saveErr = errors.Join(g.profile.save(), g.saveGhost())
+}
+
+// updateCamera eases the camera toward keeping the player inside the soft
+// margins. A world no wider than the screen stays centered in it.
+func (g *Game) updateCamera() {
+	c := &g.camera
+	sw := float64(screenWidth)
+	edge := g.world.Config.Width - sw
+	if edge <= 0 {
+		c.x, c.follow, c.look = edge/2, edge/2, 0
+		return
+	}
+	target := c.follow
+	px := g.world.Player.X + g.world.Player.Width/2
+	if px < c.follow+cameraMargin {
+		target = px - cameraMargin
+	} else if px > c.follow+sw-cameraMargin {
+		target = px - sw + cameraMargin
+	}
+	target = math.Max(0, math.Min(target, edge))
+	// Scale the easing so the camera feels the same at any tick rate
+	lerp := 1 - math.Pow(1-cameraLerp, 60*g.tickSeconds())
+	c.follow += (target - c.follow) * lerp
+
+	// Lead the ship a little in the direction it is moving. Wrapping across
+	// the seam isn't movement.
+	lead := 0.0
+	if p := &g.world.Player; !g.settings.NoLookAhead && !g.settings.ReducedMotion && math.Abs(p.X-p.PrevX) < g.world.Config.Width/2 {
+		lead = (p.X - p.PrevX) / g.tickSeconds() * lookAheadTime
+		lead = math.Max(-lookAheadMax, math.Min(lead, lookAheadMax))
+	}
+	c.look += (lead - c.look) * (1 - math.Pow(1-lookAheadLerp, 60*g.tickSeconds()))
+
+	// The lead never shows past the edges of the world
+	c.x = math.Max(0, math.Min(c.follow+c.look, edge))
+}
+
+// progress reports how far the run has advanced, from 0 to 1.
+func (g *Game) progress() float64 {
+	return math.Min(float64(g.world.Score)/maxProgressScore, 1)
+}
+
+func (g *Game) Draw(screen *ebiten.Image) {
+	frameDraws = drawCount{}
+	if g.stress != nil {
+		start := time.Now()
+		defer func() { g.stress.recordDraw(time.Since(start)) }()
+	}
+
+	// Toasts and then the console go over whatever screen is showing, and
+	// screenshots include them
+	defer g.takeScreenshot(screen)
+	defer g.drawConsole(screen)
+	defer g.drawToasts(screen)
+
+	if g.debug {
+		g.frameGraph.record(time.Now())
+	}
+
+	// Draw background, a vertical gradient slightly brighter at the bottom
+	bg := backgroundColor(g.progress())
+	drawGradient(screen, scaleColor(bg, 0.6), bg)
+
+	if g.loading != nil {
+		g.drawLoading(screen)
+		return
+	}
+
+	switch g.screen {
+	case screenProfiles:
+		g.drawProfiles(screen)
+		return
+	case screenTitle:
+		g.drawTitle(screen)
+		return
+	case screenOptions:
+		g.drawOptions(screen)
+		return
+	case screenControls:
+		g.drawControls(screen)
+		return
+	case screenShips:
+		g.drawShips(screen)
+		return
+	case screenVersus:
+		g.drawVersus(screen)
+		return
+	case screenSpectate:
+		g.drawSpectate(screen)
+		return
+	case screenRuns:
+		g.drawRuns(screen)
+		return
+	case screenAssists:
+		g.drawAssists(screen)
+		return
+	case screenMutators:
+		g.drawMutators(screen)
+		return
+	case screenPractice:
+		g.drawPractice(screen)
+		return
+	case screenStats:
+		g.drawProfileStats(screen)
+		return
+	}
+
+	g.drawPlaying(screen)
+}
+
+// drawPlaying draws a run's render passes, layer by layer.
+func (g *Game) drawPlaying(screen *ebiten.Image) {
+	// World-space drawing is shifted by the camera; the HUD is not.
+	// Positions are eased between the last two ticks.
+	t := g.interpolation()
+	v := renderView{ox: -g.lerpPos(g.camera.prevX, g.camera.x, t), t: t}
+	for _, passes := range g.renderPasses {
+		for _, p := range passes {
+			p.draw(screen, v)
 		}
 	}
+}
 
-	// Spawn asteroids
-	g.spawnTimer++
-	if g.spawnTimer >= 60 { // Spawn every second (60 frames)
-		g.spawnTimer = 0
-		width := float64(rand.Intn(30) + 20)
-		g.asteroids = append(g.asteroids, Asteroid{
-			x:      float64(rand.Intn(screenWidth - int(width))),
-			y:      -width,
-			width:  width,
-			height: width,
-			active: true,
-		})
-	}
-
-	// Update asteroids
-	for i := range g.asteroids {
-		if g.asteroids[i].active {
-			g.asteroids[i].y += asteroidSpeed
-			if g.asteroids[i].y > screenHeight {
-				g.asteroids[i].active = false
-				g.score++
-			}
+// drawHUD draws the score and everything else that stays put while the
+// camera moves.
+func (g *Game) drawHUD(screen *ebiten.Image) {
+	hud := g.hud()
+
+	// Draw score
+	hud.topLeft(screen, trf("hud.score", g.world.Score), 0)
+	weapon := trf("hud.weapon", g.world.WeaponLevel+1)
+	hud.topLeft(screen, weapon, 1)
+	if m := g.world.ScoreMultiplier(); m < 1 {
+		ebitenutil.DebugPrintAt(screen, trf("hud.multiplier", m), hud.left()+textWidth(weapon)+12, hud.top(1))
+	}
+	if g.daily != "" {
+		drawCentered(screen, trf("hud.daily", g.daily, g.world.RNG.Origin), hud.bottom(0))
+	}
+	switch g.world.Config.Mode {
+	case core.ModeStage:
+		if g.practice > 0 {
+			drawCentered(screen, trf("hud.practice", g.practice), hud.top(0))
+			break
 		}
+		drawCentered(screen, trf("hud.stage", min(g.world.Wave+1, core.StageWaves), core.StageWaves), hud.top(0))
+	case core.ModeHardcore:
+		label := tr("hud.hardcore")
+		w := textWidth(label) + 12
+		ebitenutil.DrawRect(screen, float64(centerX(screenWidth, w)), float64(hud.top(0)-4), float64(w), 20, color.RGBA{160, 0, 0, 255})
+		drawCentered(screen, label, hud.top(0)-2)
+	}
+	if !g.settings.HideTicker {
+		g.drawTicker(screen, hud.left(), hud.top(2)+6)
 	}
 
-	// Collision detection: bullets vs asteroids
-	for i := range g.bullets {
-		if !g.bullets[i].active {
+	g.drawTutorial(screen)
+
+	if g.cheated {
+		hud.bottomLeft(screen, tr("hud.cheated"), 1)
+	}
+	if g.playback != nil {
+		hud.topRight(screen, trf("hud.replay", g.playback.Profile), 0)
+	}
+	if s := gameSpeed(g.world.Config.TimeScale); s != 1 {
+		hud.topRight(screen, trf("hud.game_speed", int(math.Round(s*100))), 1)
+	}
+	if g.debug {
+		g.drawDebug(screen)
+	} else if g.practice > 0 {
+		g.drawDPS(screen, hud.right(debugWidth), hud.top(3)) // Where the debug overlay has it
+	}
+}
+
+// drawOverlays draws what covers the whole screen: the frozen moment of
+// death, pause and quit prompts, and the continue and results screens.
+func (g *Game) drawOverlays(screen *ebiten.Image, ox float64) {
+	hud := g.hud()
+
+	// Freeze the moment of the hit behind the continue prompt and results
+	g.updateDeathShot(screen, ox)
+	g.drawDeathShot(screen)
+
+	if g.confirmingQuit {
+		g.drawQuitConfirm(screen)
+	} else if g.paused {
+		drawCentered(screen, tr("hud.paused"), screenHeight/2)
+		if g.lagPause {
+			drawCentered(screen, tr("hud.lag_paused"), screenHeight/2+20)
+		}
+	} else if g.world.InGrace() {
+		drawCentered(screen, getReady(g.world), screenHeight/2)
+	}
+
+	if g.continueTimer > 0 {
+		secs := (g.continueTimer + ebiten.TPS() - 1) / ebiten.TPS()
+		drawCentered(screen, trf("continue.prompt", secs), screenHeight/2)
+		drawCentered(screen, trf("continue.left", g.continuesLeft), screenHeight/2+20)
+	} else if g.world.GameOver && g.showHeatmap {
+		g.drawRunHeatmap(screen)
+	} else if g.world.Cleared {
+		secs := g.world.Time / ebiten.TPS()
+		drawCentered(screen, tr("cleared.title"), screenHeight/2)
+		drawCentered(screen, trf("cleared.stats", secs/60, secs%60, g.world.Score), screenHeight/2-20)
+		if a := assistList(g.world.Config); a != "" {
+			drawCentered(screen, trf("gameover.assisted", a), screenHeight/2-40)
+		}
+		if g.retryLock == 0 {
+			drawCentered(screen, g.retryPrompt(), screenHeight/2+20)
+		}
+		if g.saveErr != nil {
+			hud.bottomLeft(screen, trf("save_failed", g.saveErr), 0)
+		}
+	} else if g.world.GameOver {
+		drawCentered(screen, tr("gameover.title"), screenHeight/2)
+		drawCentered(screen, trf("gameover.stats", g.world.Destroyed, g.world.Dodged), screenHeight/2-20)
+		if a := assistList(g.world.Config); a != "" {
+			drawCentered(screen, trf("gameover.assisted", a), screenHeight/2-40)
+		}
+		if g.retryLock == 0 {
+			drawCentered(screen, g.retryPrompt(), screenHeight/2+20)
+		}
+		if g.saveErr != nil {
+			hud.bottomLeft(screen, trf("save_failed", g.saveErr), 0)
+		}
+	}
+	if g.retryLock == 0 && g.canExportRun() {
+		drawCentered(screen, tr("gameover.export"), hud.bottom(1))
+	}
+	if g.retryLock == 0 && g.world.GameOver && g.continueTimer == 0 && g.playback == nil && !g.showHeatmap {
+		drawCentered(screen, tr("gameover.heatmap"), hud.bottom(2))
+	}
+}
+
+// drawWorld draws a world's asteroids, ship and bullets, in that order,
+// shifted by ox and eased t of the way from their previous positions.
+func (g *Game) drawWorld(screen *ebiten.Image, w *core.World, ox, t float64) {
+	g.drawAsteroids(screen, w, ox, t)
+	g.drawPlayer(screen, w, ox, t)
+	g.drawBullets(screen, w, ox, t)
+}
+
+// drawPlayer draws the ship, split across the seam when wrapping.
+func (g *Game) drawPlayer(screen *ebiten.Image, w *core.World, ox, t float64) {
+	playerX := g.lerpPos(w.Player.PrevX, w.Player.X, t)
+	playerY := g.lerpPos(w.Player.PrevY, w.Player.Y, t)
+	// Blink while shielded after a continue, or just dim with reduced motion
+	alpha := 1.0
+	if w.Shielded() && g.settings.ReducedMotion {
+		alpha = shieldDim
+	} else if w.Shielded() && w.Time/6%2 == 0 {
+		return
+	}
+	for _, px := range w.PlayerCopies(playerX) {
+		if ship := g.sprites.ship; ship != nil {
+			// The sprite includes the cockpit, which sticks out above the hitbox
+			b := ship.Bounds()
+			op := &ebiten.DrawImageOptions{}
+			op.GeoM.Scale(w.Player.Width/float64(b.Dx()), (w.Player.Height+shipCockpit)/float64(b.Dy()))
+			op.GeoM.Translate(px+ox, playerY-shipCockpit)
+			op.ColorScale.ScaleAlpha(float32(alpha))
+			screen.DrawImage(ship, op)
 			continue
 		}
-		for j := range g.asteroids {
-			if !g.asteroids[j].active {
+		ebitenutil.DrawRect(screen, px+ox, playerY, w.Player.Width, w.Player.Height, color.NRGBA{0, 255, 0, uint8(255 * alpha)})
+		// Draw ship's cockpit
+		ebitenutil.DrawRect(screen, px+ox+w.Player.Width/2-2, playerY-shipCockpit, 4, shipCockpit, color.NRGBA{255, 255, 0, uint8(255 * alpha)})
+	}
+}
+
+func (g *Game) drawBullets(screen *ebiten.Image, w *core.World, ox, t float64) {
+	g.shapes.begin(screen, false)
+	for _, b := range w.Bullets {
+		if b.Active {
+			bx, by := g.lerpPos(b.PrevX, b.X, t), g.lerpPos(b.PrevY, b.Y, t)
+			fade := min(edgeFade(by+core.BulletHeight, core.BulletHeight), edgeFade(w.Config.Height-by, core.BulletHeight))
+			g.shapes.addRect(bx+ox, by, core.BulletWidth, core.BulletHeight, color.NRGBA{255, 255, 0, uint8(255 * fade)})
+		}
+	}
+	g.shapes.flush()
+}
+
+// drawAsteroids draws every asteroid's shadow, then every asteroid, so no
+// shadow falls across another rock. They all go in one batch.
+func (g *Game) drawAsteroids(screen *ebiten.Image, w *core.World, ox, t float64) {
+	g.shapes.begin(screen, true)
+	for _, shadows := range []bool{true, false} {
+		for _, a := range w.Asteroids {
+			if !a.Active {
 				continue
 			}
-			if isColliding(g.bullets[i].x, g.bullets[i].y, 4, 10,
-				g.asteroids[j].x, g.asteroids[j].y, g.asteroids[j].width, g.asteroids[j].height) {
-				g.bullets[i].active = false
-				g.asteroids[j].active = false
-				g.score += 5
+			a.X, a.Y = g.lerpPos(a.PrevX, a.X, t), g.lerpPos(a.PrevY, a.Y, t)
+			fade := asteroidFade(w, &a)
+			if shadows {
+				addAsteroidShadow(&g.shapes, a, ox, fade)
+			} else {
+				addAsteroid(&g.shapes, a, ox, color.NRGBA{150, 75, 0, uint8(255 * fade)})
 			}
 		}
 	}
+	g.shapes.flush()
+}
 
-	// Collision detection: player vs asteroids
-	for i := range g.asteroids {
-		if !g.asteroids[i].active {
-			continue
-		}
-		if isColliding(g.player.x, g.player.y, g.player.width, g.player.height,
-			g.asteroids[i].x, g.asteroids[i].y, g.asteroids[i].width, g.asteroids[i].height) {
-			g.gameOver = true
-		}
+// whitePixel is the source image for filled vector shapes.
+var whitePixel = func() *ebiten.Image {
+	img := ebiten.NewImage(3, 3)
+	img.Fill(color.White)
+	return img.SubImage(image.Rect(1, 1, 2, 2)).(*ebiten.Image)
+}()
+
+func backgroundColor(progress float64) color.RGBA {
+	if progress < 0.5 {
+		return lerpColor(hexColor(backgroundStart), hexColor(backgroundMid), progress*2)
 	}
+	return lerpColor(hexColor(backgroundMid), hexColor(backgroundEnd), progress*2-1)
+}
 
-	// Clean up inactive objects
-	g.cleanUpObjects()
+func hexColor(c uint32) color.RGBA {
+	return color.RGBA{uint8(c >> 16), uint8(c >> 8), uint8(c), 255}
+}
 
-	return nil
+func lerpColor(a, b color.RGBA, t float64) color.RGBA {
+	lerp := func(x, y uint8) uint8 { return uint8(float64(x) + (float64(y)-float64(x))*t) }
+	return color.RGBA{lerp(a.R, b.R), lerp(a.G, b.G), lerp(a.B, b.B), lerp(a.A, b.A)}
 }
 
-func isColliding(x1, y1, w1, h1, x2, y2, w2, h2 float64) bool {
-	return x1 < x2+w2 && x1+w1 > x2 && y1 < y2+h2 && y1+h1 > y2
+func scaleColor(c color.RGBA, f float64) color.RGBA {
+	return color.RGBA{uint8(float64(c.R) * f), uint8(float64(c.G) * f), uint8(float64(c.B) * f), c.A}
 }
 
-func (g *Game) cleanUpObjects() {
-	// Clean bullets
-	var activeBullets []Bullet
-	for _, b := range g.bullets {
-		if b.active {
-			activeBullets = append(activeBullets, b)
+// drawGradient fills the screen with a top-to-bottom color gradient.
+func drawGradient(screen *ebiten.Image, top, bottom color.RGBA) {
+	w, h := float32(screen.Bounds().Dx()), float32(screen.Bounds().Dy())
+	vs := []ebiten.Vertex{
+		{DstX: 0, DstY: 0}, {DstX: w, DstY: 0},
+		{DstX: 0, DstY: h}, {DstX: w, DstY: h},
+	}
+	for i := range vs {
+		c := top
+		if i >= 2 {
+			c = bottom
 		}
+		vs[i].SrcX, vs[i].SrcY = 1, 1
+		vs[i].ColorR = float32(c.R) / 255
+		vs[i].ColorG = float32(c.G) / 255
+		vs[i].ColorB = float32(c.B) / 255
+		vs[i].ColorA = 1
 	}
-	g.bullets = activeBullets
+	screen.DrawTriangles(vs, []uint16{0, 1, 2, 1, 2, 3}, whitePixel, nil)
+}
 
-	// Clean asteroids
-	var activeAsteroids []Asteroid
-	for _, a := range g.asteroids {
-		if a.active {
-			activeAsteroids = append(activeAsteroids, a)
-		}
+// edgeFade is how opaque to draw something of the given size with visible
+// pixels of it still inside the field, so it fades as it leaves rather than
+// popping out of existence.
+func edgeFade(visible, size float64) float64 {
+	return math.Max(0, math.Min(visible/size, 1))
+}
+
+// asteroidFade is how opaque to draw an asteroid. It only fades out through
+// the edge it leaves by; incoming rocks stay solid.
+func asteroidFade(w *core.World, a *core.Asteroid) float64 {
+	switch a.From {
+	case core.EdgeBottom:
+		return edgeFade(a.Y+a.Height, a.Height)
+	case core.EdgeLeft:
+		return edgeFade(w.Config.Width-a.X, a.Width)
+	case core.EdgeRight:
+		return edgeFade(a.X+a.Width, a.Width)
 	}
-	g.asteroids = activeAsteroids
+	return edgeFade(w.Config.Height-a.Y, a.Height)
 }
 
-func (g *Game) Draw(screen *ebiten.Image) {
-	// Draw background
-	screen.Fill(color.RGBA{0, 0, 20, 255})
+// addAsteroid adds an asteroid with a thin bright rim, which with its
+// shadow marks it as lethal.
+func addAsteroid(b *shapeBatch, a core.Asteroid, ox float64, clr color.NRGBA) {
+	path := asteroidPath(a, a.X+ox+a.Width/2, a.Y+a.Height/2)
+	b.addPath(path, 0, clr)
+	b.addPath(path, 1, color.NRGBA{255, 210, 150, clr.A})
+}
 
-	// Draw player (spaceship)
-	ebitenutil.DrawRect(screen, g.player.x, g.player.y, g.player.width, g.player.height, color.RGBA{0, 255, 0, 255})
-	// Draw ship's cockpit
-	ebitenutil.DrawRect(screen, g.player.x+g.player.width/2-2, g.player.y-5, 4, 5, color.RGBA{255, 255, 0, 255})
+// addAsteroidShadow adds the drop shadow under an asteroid, opacity scaled
+// by fade.
+func addAsteroidShadow(b *shapeBatch, a core.Asteroid, ox, fade float64) {
+	path := asteroidPath(a, a.X+ox+a.Width/2+lethalShadow, a.Y+a.Height/2+lethalShadow)
+	b.addPath(path, 0, color.NRGBA{0, 0, 0, uint8(128 * fade)})
+}
 
-	// Draw bullets
-	for _, b := range g.bullets {
-		if b.active {
-			ebitenutil.DrawRect(screen, b.x, b.y, 4, 10, color.RGBA{255, 255, 0, 255})
+// asteroidPath is an asteroid's outline centered on (cx, cy).
+func asteroidPath(a core.Asteroid, cx, cy float64) *vector.Path {
+	var path vector.Path
+	for i, p := range a.Shape {
+		if i == 0 {
+			path.MoveTo(float32(cx+p.X), float32(cy+p.Y))
+		} else {
+			path.LineTo(float32(cx+p.X), float32(cy+p.Y))
 		}
 	}
+	path.Close()
+	return &path
+}
 
-	// Draw asteroids
-	for _, a := range g.asteroids {
-		if a.active {
-			ebitenutil.DrawRect(screen, a.x, a.y, a.width, a.height, color.RGBA{150, 75, 0, 255})
-		}
+func (g *Game) reset() {
+	switch {
+	case g.playback != nil:
+		g.resetPlayback()
+		return
+	case g.daily != "":
+		g.resetDaily()
+		return
 	}
+	seed := g.runSeed()
+	cfg := core.Config{
+		TPS:          ebiten.TPS(),
+		Width:        g.fieldWidth(),
+		Height:       float64(screenHeight),
+		SpawnScale:   g.spawnScale(),
+		Wrap:         g.settings.Wrap,
+		Mode:         g.settings.Mode,
+		IdleDecay:    g.settings.Mode != core.ModeEndless, // Endless stays casual
+		NoDodgeScore: g.settings.NoDodgeScore,
+		Inertia:      g.settings.Inertia,
+		MaxAsteroids: g.maxAsteroids,
+		MaxBullets:   g.maxBullets,
 
-	// Draw score
-	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Score: %d", g.score), 10, 10)
+		PlayerSpeedScale: speedScale(g.settings.PlayerSpeed),
+		BulletSpeedScale: speedScale(g.settings.BulletSpeed),
 
-	if g.gameOver {
-		ebitenutil.DebugPrintAt(screen, "GAME OVER - Press R to restart", screenWidth/2-100, screenHeight/2)
+		Tuning:      g.tuning,
+		Ship:        core.Ships[g.shipIndex()],
+		BroadPhase:  g.broadPhase,
+		SpawnPolicy: g.runSpawnPolicy(),
+
+		TimeScale:   gameSpeed(g.settings.GameSpeed),
+		SmallHitbox: g.settings.SmallHitbox,
+		AutoFire:    g.settings.AutoFire,
+	}
+	if g.practice > 0 {
+		cfg.Mode, cfg.IdleDecay, cfg.SpawnPolicy = core.ModeStage, true, g.spawnPolicy
+		cfg.PracticeWave = g.practice
+	}
+	g.applyMutators(&cfg)
+	g.world = core.NewWorld(cfg, seed)
+	g.resetRun()
+	g.trace = core.NewReplay(g.world.Config, seed)
+	if g.practice > 0 {
+		// A death goes straight back to the wave, and there's no best run
+		// to race
+		g.continuesLeft = 0
+		g.ghost = nil
+		return
+	}
+	// The tutorial teaches the classic controls
+	if g.profile != nil && !g.profile.TutorialDone && g.settings.Mode != core.ModeTwinStick {
+		g.startTutorial()
+	}
+	g.continuesLeft = g.continues
+	if g.settings.Mode == core.ModeHardcore {
+		g.continuesLeft = 0 // One life
+	}
+	g.startMutators()
+	if g.profile != nil {
+		g.ghost = g.loadGhost()
 	}
 }
 
-func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
-	return screenWidth, screenHeight
+// resetDaily starts the day's challenge over: no tutorial, ghost or
+// continues, so every attempt is comparable.
+func (g *Game) resetDaily() {
+	g.world = core.NewWorld(dailyConfig(), dailySeed(g.daily))
+	g.resetRun()
+	g.trace = core.NewReplay(g.world.Config, dailySeed(g.daily))
+	g.continuesLeft = 0
+	g.ghost = nil
 }
 
-func (g *Game) reset() {
-	g.player = Player{
-		x:      screenWidth/2 - 15,
-		y:      screenHeight - 40,
-		width:  30,
-		height: 30,
-	}
-	g.bullets = make([]Bullet, 0)
-	g.asteroids = make([]Asteroid, 0)
-	g.gameOver = false
-	g.score = 0
-	g.spawnTimer = 0
+// resetRun clears the per-run front-end state around a new world.
+func (g *Game) resetRun() {
+	g.ticker = eventTicker{}
+	g.trails.clear()
+	g.runHeat, g.showHeatmap = heatmap{}, false
+	g.tutorial = tutorial{}
+	g.paused, g.lagPause = false, false
+	g.presses = pressBuffer{} // The press that started the run isn't a shot
+	g.aimX, g.aimY = 0, -1
+	g.continueTimer = 0
+	g.camera = Camera{x: (g.world.Config.Width - float64(screenWidth)) / 2}
+	g.camera.prevX, g.camera.follow = g.camera.x, g.camera.x
+	g.recording = g.recording[:0]
+	g.recordStride = 1
+	g.restored = false
+	g.cheated = false
+	g.damageNumbers = g.damageNumbers[:0]
+	g.muzzleFlashes = g.muzzleFlashes[:0]
+	g.dps = dpsMeter{}
 }
 
 func main() {
-	rand.Seed(time.Now().UnixNano())
+	wide := flag.Bool("wide", false, "use a playfield wider than the window with a scrolling camera")
+	aspectName := flag.String("aspect", "fit", "how the screen fits the window: fit (letterbox) or extend (widen the field to the window's shape)")
+	portrait := flag.Bool("portrait", false, "use a tall 480x800 screen, for phones and narrow windows")
+	wrap := flag.Bool("wrap", false, "let the ship wrap around the left and right edges")
+	tps := flag.Int("tps", defaultTPS, "simulation ticks per second")
+	maxAsteroids := flag.Int("max-asteroids", core.DefaultMaxAsteroids, "cap on live asteroids")
+	maxBullets := flag.Int("max-bullets", core.DefaultMaxBullets, "cap on live bullets")
+	pprofAddr := flag.String("pprof", "", "serve net/http/pprof on this address, e.g. localhost:6060")
+	continues := flag.Int("continues", 1, "continues per run")
+	hostAddr := flag.String("host", "", "host a LAN versus match on this address, e.g. :7777")
+	joinAddr := flag.String("join", "", "join the LAN versus match hosted at this address, e.g. 192.168.1.5:7777")
+	broadcastAddr := flag.String("broadcast", "", "stream every tick to spectators connecting on this address, e.g. :7777")
+	verify := flag.String("verify", "", "replay a shared run file without opening a window, check its score and exit")
+	broadPhase := flag.String("broadphase", "none", "how collisions find nearby asteroids: none, grid or quadtree")
+	spawnPolicy := flag.String("spawn", "fair", "where asteroids spawn: fair keeps most of them off a ship pinned against a wall, uniform anywhere")
+	tuningPath := flag.String("tuning", "", "load balance values from this JSON file instead of the built-in ones")
+	dev := flag.Bool("dev", false, "reload the -tuning file and sprites whenever they change (builds with -tags dev only)")
+	spectateAddr := flag.String("spectate", "", "watch the game broadcasting at this address, e.g. 192.168.1.5:7777")
+	assetsDir := flag.String("assets", "", "load sprites from this directory in preference to the built-in ones; see -assets-help")
+	assetsHelp := flag.Bool("assets-help", false, "list the files an -assets directory can replace and exit")
+	logLevelName := flag.String("loglevel", "info", "least severe log messages to print: debug, info, warn or error")
+	stress := flag.Int("stress", 0, "keep this many asteroids and bullets in play with the ship invulnerable, time updates and draws and exit")
+	curveReport := flag.Bool("report-curve", false, "fly the bot at every skill through endless runs, print how long it lasts in each stretch of the difficulty curve and exit")
+	flag.Usage = usageHiding("stress")
+	flag.Parse()
 
-	game := &Game{}
-	game.reset()
+	if err := setupLogging(*logLevelName); err != nil {
+		fmt.Fprintf(os.Stderr, "-loglevel: %v\n", err)
+		os.Exit(2)
+	}
 
+	if *assetsHelp {
+		printAssetHelp(os.Stdout)
+		return
+	}
+	if *verify != "" {
+		ok, err := verifyRun(*verify, os.Stdout)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "-verify %s: %v\n", *verify, err)
+			os.Exit(2)
+		}
+		if !ok {
+			os.Exit(1)
+		}
+		return
+	}
+	if *assetsDir != "" {
+		if fi, err := os.Stat(*assetsDir); err != nil || !fi.IsDir() {
+			fmt.Fprintf(os.Stderr, "-assets %s is not a directory\n", *assetsDir)
+			os.Exit(2)
+		}
+	}
+
+	if *pprofAddr != "" {
+		startPprof(*pprofAddr)
+	}
+
+	if *tps < 1 {
+		fmt.Fprintln(os.Stderr, "-tps must be at least 1")
+		os.Exit(2)
+	}
+	if *maxAsteroids < 1 || *maxBullets < 1 {
+		fmt.Fprintln(os.Stderr, "-max-asteroids and -max-bullets must be at least 1")
+		os.Exit(2)
+	}
+	if *continues < 0 {
+		fmt.Fprintln(os.Stderr, "-continues can't be negative")
+		os.Exit(2)
+	}
+	if *hostAddr != "" && *joinAddr != "" {
+		fmt.Fprintln(os.Stderr, "-host and -join can't be used together")
+		os.Exit(2)
+	}
+	broadPhases := map[string]core.BroadPhase{"none": core.BroadPhaseNone, "grid": core.BroadPhaseGrid, "quadtree": core.BroadPhaseQuadtree}
+	if _, ok := broadPhases[*broadPhase]; !ok {
+		fmt.Fprintln(os.Stderr, "-broadphase must be none, grid or quadtree")
+		os.Exit(2)
+	}
+	spawnPolicies := map[string]core.SpawnPolicy{"uniform": core.SpawnUniform, "fair": core.SpawnFair}
+	if _, ok := spawnPolicies[*spawnPolicy]; !ok {
+		fmt.Fprintln(os.Stderr, "-spawn must be fair or uniform")
+		os.Exit(2)
+	}
+	if *dev && !devBuild {
+		fmt.Fprintln(os.Stderr, "-dev needs a build with -tags dev")
+		os.Exit(2)
+	}
+	if *stress < 0 {
+		fmt.Fprintln(os.Stderr, "-stress can't be negative")
+		os.Exit(2)
+	}
+	if *stress > 0 && (*hostAddr != "" || *joinAddr != "" || *spectateAddr != "") {
+		fmt.Fprintln(os.Stderr, "-stress can't be used with -host, -join or -spectate")
+		os.Exit(2)
+	}
+	if *curveReport && (*stress > 0 || *hostAddr != "" || *joinAddr != "" || *spectateAddr != "" || *broadcastAddr != "") {
+		fmt.Fprintln(os.Stderr, "-report-curve can't be used with -stress, -host, -join, -spectate or -broadcast")
+		os.Exit(2)
+	}
+	if *spectateAddr != "" && (*hostAddr != "" || *joinAddr != "" || *broadcastAddr != "") {
+		fmt.Fprintln(os.Stderr, "-spectate can't be used with -host, -join or -broadcast")
+		os.Exit(2)
+	}
+	ebiten.SetTPS(*tps)
+
+	if *portrait {
+		usePortrait()
+	}
+	aspect, err := parseAspectMode(*aspectName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "-aspect: %v\n", err)
+		os.Exit(2)
+	}
+	worldWidth := 0.0
+	if *wide {
+		worldWidth = wideWorldWidth
+	}
+	tuning := core.DefaultTuning
+	if *tuningPath != "" {
+		t, err := loadTuningFile(*tuningPath, max(worldWidth, float64(screenWidth)))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "-tuning %s:\n%v\n", *tuningPath, err)
+			os.Exit(2)
+		}
+		tuning = t
+	}
+	if *curveReport {
+		reportCurve(tuning, os.Stdout)
+		return
+	}
+	slog.Info("starting",
+		"tps", *tps,
+		"worldWidth", worldWidth,
+		"screen", fmt.Sprintf("%dx%d", screenWidth, screenHeight),
+		"aspect", *aspectName,
+		"maxAsteroids", *maxAsteroids,
+		"maxBullets", *maxBullets,
+		"broadphase", *broadPhase,
+		"spawn", *spawnPolicy,
+		"continues", *continues,
+		"tuning", tuning.Checksum(),
+		"assets", *assetsDir,
+		"dev", *dev)
+
+	opts := []Option{
+		WithWorldWidth(worldWidth),
+		WithAspect(aspect),
+		WithLimits(max(*maxAsteroids, *stress), max(*maxBullets, *stress)),
+		WithTuning(tuning),
+		WithBroadPhase(broadPhases[*broadPhase]),
+		WithSpawnPolicy(spawnPolicies[*spawnPolicy]),
+		WithContinues(*continues),
+		WithAssets(*assetsDir),
+	}
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "wrap" {
+			opts = append(opts, WithWrap(*wrap))
+		}
+	})
+	game := NewGame(opts...)
+	game.tuningPath = *tuningPath
+	if *dev {
+		paths := game.newAssetLoader().spritePaths()
+		if *tuningPath != "" {
+			paths = append(paths, *tuningPath)
+		}
+		game.watcher = startFileWatcher(paths)
+	}
+	if *broadcastAddr != "" {
+		game.broadcaster = startBroadcast(*broadcastAddr)
+	}
+
+	if *stress > 0 {
+		game.startStress(*stress, os.Stdout)
+		runGame(game)
+		return
+	}
+
+	// Jump straight to the title screen for whoever played last
+	game.openProfiles()
+	if last := readLastProfile(); last != "" {
+		if p, err := loadProfile(last); err == nil {
+			game.useProfile(p)
+		}
+	}
+	switch {
+	case *hostAddr != "":
+		game.startVersus(true, *hostAddr)
+	case *joinAddr != "":
+		game.startVersus(false, *joinAddr)
+	case *spectateAddr != "":
+		game.startSpectate(*spectateAddr)
+	}
+	runGame(game)
+}
+
+// runGame opens the window and runs the game until it quits.
+func runGame(game *Game) {
 	ebiten.SetWindowSize(screenWidth, screenHeight)
 	ebiten.SetWindowTitle("Space Dodger (Linux)")
-	if err := ebiten.RunGame(game); err != nil {
+	if game.aspect == aspectExtend {
+		ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
+	}
+	ebiten.SetWindowClosingHandled(true)
+	if err := ebiten.RunGame(game); err != nil && !errors.Is(err, errQuit) {
 		panic(err)
 	}
 }
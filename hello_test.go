@@ -0,0 +1,178 @@
+package main
+
+import (
+	"math"
+	"testing"
+
+	"example/hello/core"
+)
+
+// scriptedInput stands in for the devices. Presses, keys, menu steps and
+// clicks last for the one read after they are made; steering, the pointer
+// and held buttons stay until changed.
+type scriptedInput struct {
+	state inputState
+}
+
+func (s *scriptedInput) read() inputState {
+	st := s.state
+	s.state = inputState{
+		moveX: st.moveX, moveY: st.moveY,
+		aimX: st.aimX, aimY: st.aimY, aiming: st.aiming,
+		pointer: st.pointer, pointing: st.pointing,
+		held: st.held,
+		used: st.used, device: st.device,
+	}
+	return st
+}
+
+func (s *scriptedInput) press(a inputAction) {
+	s.state.pressed[a] = true
+}
+
+// newTestGame returns a seeded game on the play screen with its assets in,
+// reading input from the returned script. Its profile and saves go to a
+// temporary directory. Its clock stands still, so updates never stall
+// unless the test passes a clock of its own and moves it.
+func newTestGame(t *testing.T, opts ...Option) (*Game, *scriptedInput) {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	in := &scriptedInput{}
+	g := NewGame(append([]Option{
+		WithSeed(1),
+		WithClock(newTestClock().now),
+		WithInputs(in),
+		WithProfile(&Profile{Name: "test", TutorialDone: true}),
+	}, opts...)...)
+	<-g.loading.done
+	updates(t, g, 1) // Takes the assets and leaves the splash screen
+	return g, in
+}
+
+// updates runs n updates, failing the test on an error.
+func updates(t *testing.T, g *Game, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		if err := g.Update(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestPauseStopsTheClock(t *testing.T) {
+	g, in := newTestGame(t)
+	in.state.moveX = 1
+	updates(t, g, 200)
+	if g.world.Time != 200 {
+		t.Fatalf("world time is %d after 200 updates, want 200", g.world.Time)
+	}
+
+	in.press(inputPause)
+	updates(t, g, 1)
+	if !g.paused {
+		t.Fatal("pause press didn't pause")
+	}
+	at, hash := g.world.Time, g.world.Hash()
+	updates(t, g, 300)
+	if g.world.Time != at || g.world.Hash() != hash {
+		t.Errorf("world moved on while paused: time %d, want %d", g.world.Time, at)
+	}
+
+	in.press(inputPause)
+	updates(t, g, 10)
+	if g.paused {
+		t.Fatal("second pause press didn't unpause")
+	}
+	if g.world.Time != at+10 {
+		t.Errorf("world time is %d ten updates after unpausing, want %d", g.world.Time, at+10)
+	}
+}
+
+func TestGameOverStopsTheClock(t *testing.T) {
+	g, _ := newTestGame(t, WithContinues(0))
+	g.world.GameOver = true
+	at := g.world.Time
+	updates(t, g, 100)
+	if g.world.Time != at {
+		t.Errorf("world time moved from %d to %d after the run ended", at, g.world.Time)
+	}
+}
+
+func TestTimersCountGameTicks(t *testing.T) {
+	// Timers are in ticks of game time, so at half speed they take twice
+	// the updates
+	for _, scale := range []float64{0.5, 1} {
+		w := core.NewWorld(core.Config{TPS: 60, Width: 640, Height: 480, TimeScale: scale,
+			MaxAsteroids: core.DefaultMaxAsteroids, MaxBullets: core.DefaultMaxBullets}, 1)
+		if want := int(math.Round(core.DefaultTuning.StartGrace * 60 / scale)); w.GraceUntil != want {
+			t.Errorf("at %gx the start grace lasts %d ticks, want %d", scale, w.GraceUntil, want)
+		}
+	}
+}
+
+func TestEffectsDontChangePlay(t *testing.T) {
+	// One game shows every effect, drawing on the cosmetic RNG for damage
+	// numbers; the other shows as few as it can
+	plain, plainIn := newTestGame(t)
+	plain.settings.ReducedMotion = true
+	fancy, fancyIn := newTestGame(t)
+	fancy.debug = true
+
+	for i := 0; i < 1800 && !plain.world.GameOver; i++ {
+		for _, in := range []*scriptedInput{plainIn, fancyIn} {
+			in.state.moveX = float64(i/90%2*2 - 1)
+			if i%15 == 0 {
+				in.press(inputFire)
+			}
+		}
+		updates(t, plain, 1)
+		updates(t, fancy, 1)
+		if plain.world.Hash() != fancy.world.Hash() {
+			t.Fatalf("the runs diverged at tick %d", plain.world.Time)
+		}
+	}
+	if fancy.world.Destroyed == 0 {
+		t.Fatal("nothing was hit, so the test proves nothing")
+	}
+	if plain.world.Score != fancy.world.Score {
+		t.Errorf("scores were %d and %d", plain.world.Score, fancy.world.Score)
+	}
+}
+
+func TestAsteroidFade(t *testing.T) {
+	w := testWorld(1)
+	height := w.Config.Height
+	tests := []struct {
+		name string
+		from core.Edge
+		x, y float64
+		want float64
+	}{
+		{"falling, wholly inside", core.EdgeTop, 100, height - 40, 1},
+		{"falling, half out", core.EdgeTop, 100, height - 20, 0.5},
+		{"falling, top edge on the bottom", core.EdgeTop, 100, height, 0},
+		{"falling, still coming in", core.EdgeTop, 100, -20, 1},
+		{"rising, half out", core.EdgeBottom, 100, -20, 0.5},
+		{"heading right, a quarter left", core.EdgeLeft, w.Config.Width - 10, 100, 0.25},
+		{"heading left, gone", core.EdgeRight, -40, 100, 0},
+	}
+	for _, tt := range tests {
+		a := core.Asteroid{X: tt.x, Y: tt.y, Width: 40, Height: 40, From: tt.from}
+		if got := asteroidFade(w, &a); got != tt.want {
+			t.Errorf("%s: fade is %g, want %g", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestHardcoreSpawnsUniformly(t *testing.T) {
+	for _, mode := range []core.Mode{core.ModeEndless, core.ModeStage, core.ModeHardcore} {
+		g := &Game{spawnPolicy: core.SpawnFair, settings: Settings{Mode: mode}}
+		want := core.SpawnFair
+		if mode == core.ModeHardcore {
+			want = core.SpawnUniform
+		}
+		if got := g.runSpawnPolicy(); got != want {
+			t.Errorf("mode %v spawns with policy %v, want %v", mode, got, want)
+		}
+	}
+}
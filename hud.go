@@ -0,0 +1,71 @@
+package main
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+const (
+	defaultHUDMargin = 10
+	maxHUDMargin     = 40
+	hudMarginStep    = 2
+	hudLineHeight    = 16 // A line of the debug font
+)
+
+// hudMargin reads the HUD margin setting, clamped to the allowed range.
+// Zero is an unset setting and means the default margin.
+func hudMargin(v int) int {
+	if v == 0 {
+		return defaultHUDMargin
+	}
+	return max(hudMarginStep, min(v, maxHUDMargin))
+}
+
+// hudLayout places HUD text against the edges of the logical screen, inset
+// by the margin, so it stays on screen whatever size the screen is.
+type hudLayout struct {
+	margin int
+}
+
+func (g *Game) hud() hudLayout {
+	return hudLayout{margin: hudMargin(g.settings.HUDMargin)}
+}
+
+// left is the x of text against the left edge.
+func (h hudLayout) left() int {
+	return h.margin
+}
+
+// right is the x of something width wide against the right edge.
+func (h hudLayout) right(width int) int {
+	return screenWidth - h.margin - width
+}
+
+// top is the y of the line'th line down from the top edge.
+func (h hudLayout) top(line int) int {
+	return h.margin + line*hudLineHeight
+}
+
+// bottom is the y of the line'th line up from the bottom edge, 0 being the
+// lowest.
+func (h hudLayout) bottom(line int) int {
+	return screenHeight - h.margin - (line+1)*hudLineHeight
+}
+
+// topLeft draws text on the line'th line from the top, against the left
+// edge.
+func (h hudLayout) topLeft(screen *ebiten.Image, text string, line int) {
+	ebitenutil.DebugPrintAt(screen, text, h.left(), h.top(line))
+}
+
+// topRight draws text on the line'th line from the top, against the right
+// edge.
+func (h hudLayout) topRight(screen *ebiten.Image, text string, line int) {
+	ebitenutil.DebugPrintAt(screen, text, h.right(textWidth(text)), h.top(line))
+}
+
+// bottomLeft draws text on the line'th line from the bottom, against the
+// left edge. Text of several lines grows upward.
+func (h hudLayout) bottomLeft(screen *ebiten.Image, text string, line int) {
+	ebitenutil.DebugPrintAt(screen, text, h.left(), h.bottom(line+textLines(text)-1))
+}
@@ -0,0 +1,70 @@
+package main
+
+import "testing"
+
+func TestCenterX(t *testing.T) {
+	tests := []struct {
+		span, width, want int
+	}{
+		{640, 60, 290},
+		{640, 0, 320},
+		{640, 640, 0},
+		{640, 61, 290}, // Odd widths lean left by the half pixel
+		{480, 120, 180},
+		{1137, 60, 538},
+	}
+	for _, tt := range tests {
+		if got := centerX(tt.span, tt.width); got != tt.want {
+			t.Errorf("centerX(%d, %d) = %d, want %d", tt.span, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestTextMeasures(t *testing.T) {
+	tests := []struct {
+		text         string
+		width, lines int
+	}{
+		{"", 0, 1},
+		{"Score: 10", 54, 1},
+		{"Pausa ñ", 42, 1}, // Runes, not bytes
+		{"short\nmuch longer", 66, 2},
+	}
+	for _, tt := range tests {
+		if got := textWidth(tt.text); got != tt.width {
+			t.Errorf("textWidth(%q) = %d, want %d", tt.text, got, tt.width)
+		}
+		if got := textLines(tt.text); got != tt.lines {
+			t.Errorf("textLines(%q) = %d, want %d", tt.text, got, tt.lines)
+		}
+	}
+}
+
+func TestHUDLayout(t *testing.T) {
+	keepScreenSize(t)
+	screenWidth, screenHeight = 800, 600
+	tests := []struct {
+		setting int
+		margin  int
+	}{
+		{0, defaultHUDMargin},
+		{1, hudMarginStep},
+		{24, 24},
+		{500, maxHUDMargin},
+	}
+	for _, tt := range tests {
+		h := (&Game{settings: Settings{HUDMargin: tt.setting}}).hud()
+		m := tt.margin
+		if h.left() != m || h.top(0) != m || h.top(2) != m+2*hudLineHeight {
+			t.Errorf("margin %d: left %d, top lines at %d and %d", tt.setting, h.left(), h.top(0), h.top(2))
+		}
+		// The right edge of text 60 wide, and the bottom of the lowest line,
+		// are a margin in from the screen's
+		if got := h.right(60) + 60; got != 800-m {
+			t.Errorf("margin %d: right-aligned text ends at %d, want %d", tt.setting, got, 800-m)
+		}
+		if got := h.bottom(0) + hudLineHeight; got != 600-m {
+			t.Errorf("margin %d: the bottom line ends at %d, want %d", tt.setting, got, 600-m)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+const defaultLanguage = "en"
+
+// languages lists the codes of the bundled string tables, in the order the
+// language setting cycles through them.
+var languages = []string{"en", "es"}
+
+//go:embed lang/*.json
+var langFiles embed.FS
+
+var (
+	fallbackStrings = mustLoadStrings(defaultLanguage)
+	currentStrings  = fallbackStrings
+)
+
+func loadStrings(code string) (map[string]string, error) {
+	data, err := langFiles.ReadFile("lang/" + code + ".json")
+	if err != nil {
+		return nil, err
+	}
+	var table map[string]string
+	if err := json.Unmarshal(data, &table); err != nil {
+		return nil, fmt.Errorf("lang/%s.json: %w", code, err)
+	}
+	return table, nil
+}
+
+func mustLoadStrings(code string) map[string]string {
+	table, err := loadStrings(code)
+	if err != nil {
+		panic(err)
+	}
+	return table
+}
+
+// setLanguage switches the UI language, falling back to English for an
+// unknown code.
+func setLanguage(code string) {
+	table, err := loadStrings(code)
+	if err != nil {
+		table = fallbackStrings
+	}
+	currentStrings = table
+}
+
+// tr looks up a UI string in the current language, then in English, and
+// finally falls back to the key itself.
+func tr(key string) string {
+	if s, ok := currentStrings[key]; ok {
+		return s
+	}
+	if s, ok := fallbackStrings[key]; ok {
+		return s
+	}
+	return key
+}
+
+// trf formats a translated string.
+func trf(key string, args ...any) string {
+	return fmt.Sprintf(tr(key), args...)
+}
+
+// nextLanguage returns the language after code in the settings cycle.
+func nextLanguage(code string) string {
+	for i, l := range languages {
+		if l == code {
+			return languages[(i+1)%len(languages)]
+		}
+	}
+	return languages[0]
+}
+
+// languageName returns a language's name in that language.
+func languageName(code string) string {
+	table, err := loadStrings(code)
+	if err != nil {
+		return code
+	}
+	return table["language.name"]
+}
+
+// textWidth is the width of text in the debug font, which is 6px per rune.
+// Text of several lines is as wide as its widest line.
+func textWidth(text string) int {
+	w := 0
+	for _, line := range strings.Split(text, "\n") {
+		w = max(w, utf8.RuneCountInString(line)*6)
+	}
+	return w
+}
+
+// textLines is how many lines text takes.
+func textLines(text string) int {
+	return strings.Count(text, "\n") + 1
+}
+
+// centerX is the x that centers something width wide across a span.
+func centerX(span, width int) int {
+	return span/2 - width/2
+}
+
+func drawCentered(screen *ebiten.Image, text string, y int) {
+	ebitenutil.DebugPrintAt(screen, text, centerX(screenWidth, textWidth(text)), y)
+}
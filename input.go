@@ -0,0 +1,227 @@
+package main
+
+import (
+	"image"
+	"math"
+	"slices"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+
+	"example/hello/core"
+)
+
+// inputAction is something the player does with a button, whichever device
+// the button is on.
+type inputAction int
+
+const (
+	inputFire inputAction = iota
+	inputPause
+	inputContinue
+	inputRestart // Play again once a run is over
+	inputMenu    // Leave for the title screen
+	inputExport  // Save a finished run to share
+	inputSaveState
+	inputLoadState
+	inputDebug      // Show or hide the debug overlay
+	inputFrameGraph // Show or hide the frame graph under the overlay
+	inputScreenshot
+	inputHeatmap // Switch between a finished run's results and its heatmap
+	inputActionCount
+)
+
+// inputState is one tick of the player's input from every device at once.
+// Update reads input only through it, so anything that can produce one
+// can stand in for the devices.
+type inputState struct {
+	moveX, moveY float64 // Steering, each axis from -1 to 1
+	aimX, aimY   float64 // Unit direction to fire in, while aiming
+	aiming       bool
+	pointer      image.Point // Mouse position
+	pointing     bool        // The left mouse button is held
+
+	held    [inputActionCount]bool
+	pressed [inputActionCount]bool // Went down this tick
+
+	// Menus and text fields read the raw presses
+	keys         []ebiten.Key // Went down this tick
+	chars        []rune       // Typed this tick
+	buttons      []padPress   // Went down this tick
+	stepX, stepY int          // -1, 0 or 1 from the arrows or d-pad, repeating while held
+	clicked      bool         // The left mouse button went down this tick
+	rightClicked bool
+	tapped       bool // The screen was touched this tick, at tap
+	tap          image.Point
+
+	used   bool        // A device was used this tick
+	device inputDevice // Which one, when used
+}
+
+// padPress is a button going down on one gamepad.
+type padPress struct {
+	id     ebiten.GamepadID
+	button ebiten.StandardGamepadButton
+}
+
+// justPressed reports whether a went down this tick.
+func (s *inputState) justPressed(a inputAction) bool {
+	return s.pressed[a]
+}
+
+// keyPressed reports whether k went down this tick.
+func (s *inputState) keyPressed(k ebiten.Key) bool {
+	return slices.Contains(s.keys, k)
+}
+
+// padPressed reports whether b went down this tick on any gamepad.
+func (s *inputState) padPressed(b ebiten.StandardGamepadButton) bool {
+	return slices.ContainsFunc(s.buttons, func(p padPress) bool { return p.button == b })
+}
+
+// merge adds another source's tick to s. Buttons held or pressed on
+// either count; steering, aim, menu steps, the pointer and the device come
+// from s if it has them.
+func (s *inputState) merge(o inputState) {
+	if s.moveX == 0 && s.moveY == 0 {
+		s.moveX, s.moveY = o.moveX, o.moveY
+	}
+	if !s.aiming {
+		s.aimX, s.aimY, s.aiming = o.aimX, o.aimY, o.aiming
+	}
+	if s.pointer == (image.Point{}) {
+		s.pointer = o.pointer
+	}
+	s.pointing = s.pointing || o.pointing
+	for a := range s.held {
+		s.held[a] = s.held[a] || o.held[a]
+		s.pressed[a] = s.pressed[a] || o.pressed[a]
+	}
+	s.keys = append(s.keys, o.keys...)
+	s.chars = append(s.chars, o.chars...)
+	s.buttons = append(s.buttons, o.buttons...)
+	if s.stepX == 0 && s.stepY == 0 {
+		s.stepX, s.stepY = o.stepX, o.stepY
+	}
+	s.clicked = s.clicked || o.clicked
+	s.rightClicked = s.rightClicked || o.rightClicked
+	if !s.tapped {
+		s.tapped, s.tap = o.tapped, o.tap
+	}
+	if !s.used {
+		s.used, s.device = o.used, o.device
+	}
+}
+
+// inputSource produces the player's input, one tick per call.
+type inputSource interface {
+	read() inputState
+}
+
+// mergedInput reads several sources as one, so any of them can act. The
+// first source steering or aiming wins over the rest.
+type mergedInput []inputSource
+
+func (m mergedInput) read() inputState {
+	var s inputState
+	for _, src := range m {
+		s.merge(src.read())
+	}
+	return s
+}
+
+// deviceInput is every device the game reads. A gamepad comes first, so
+// it wins over the keys while it is steering.
+func (g *Game) deviceInput() inputSource {
+	return mergedInput{&gamepadSource{g: g}, keyboardSource{g: g}, touchSource{}}
+}
+
+// actionKeys are the keyboard's bindings.
+var actionKeys = [inputActionCount]ebiten.Key{
+	inputFire:       ebiten.KeySpace,
+	inputPause:      ebiten.KeyP,
+	inputContinue:   ebiten.KeyC,
+	inputRestart:    ebiten.KeyR,
+	inputMenu:       ebiten.KeyEscape,
+	inputExport:     ebiten.KeyX,
+	inputSaveState:  ebiten.KeyF5,
+	inputLoadState:  ebiten.KeyF9,
+	inputDebug:      ebiten.KeyF3,
+	inputFrameGraph: ebiten.KeyF4,
+	inputScreenshot: ebiten.KeyF12,
+	inputHeatmap:    ebiten.KeyH,
+}
+
+// keyboardSource reads the keyboard and mouse. The arrow keys steer,
+// except in a twin-stick run, where WASD steers and the arrows aim.
+type keyboardSource struct {
+	g *Game
+}
+
+func (k keyboardSource) read() inputState {
+	var s inputState
+	for a, key := range actionKeys {
+		s.held[a] = ebiten.IsKeyPressed(key)
+		s.pressed[a] = inpututil.IsKeyJustPressed(key)
+	}
+	arrowX, arrowY := keyAxis(ebiten.KeyLeft, ebiten.KeyRight), keyAxis(ebiten.KeyUp, ebiten.KeyDown)
+	if k.g.world.Config.Mode == core.ModeTwinStick {
+		s.moveX, s.moveY = keyAxis(ebiten.KeyA, ebiten.KeyD), keyAxis(ebiten.KeyW, ebiten.KeyS)
+		if arrowX != 0 || arrowY != 0 {
+			mag := math.Hypot(arrowX, arrowY)
+			s.aimX, s.aimY, s.aiming = arrowX/mag, arrowY/mag, true
+		}
+	} else {
+		s.moveX, s.moveY = arrowX, arrowY
+	}
+	s.keys = inpututil.AppendJustPressedKeys(nil)
+	s.chars = ebiten.AppendInputChars(nil)
+	s.stepX, s.stepY = keyStep(ebiten.KeyLeft, ebiten.KeyRight), keyStep(ebiten.KeyUp, ebiten.KeyDown)
+	s.pointer = image.Pt(ebiten.CursorPosition())
+	s.pointing = ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft)
+	s.clicked = inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft)
+	s.rightClicked = inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight)
+	if len(s.keys) > 0 {
+		s.used, s.device = true, deviceKeyboard
+	}
+	return s
+}
+
+// keyStep is a menu step of -1 from neg or 1 from pos, repeating while
+// the key is held.
+func keyStep(neg, pos ebiten.Key) int {
+	switch {
+	case repeats(inpututil.KeyPressDuration(neg), ebiten.TPS()):
+		return -1
+	case repeats(inpututil.KeyPressDuration(pos), ebiten.TPS()):
+		return 1
+	}
+	return 0
+}
+
+// keyAxis is -1 while neg is held, 1 while pos is, and 0 for both or
+// neither.
+func keyAxis(neg, pos ebiten.Key) float64 {
+	v := 0.0
+	if ebiten.IsKeyPressed(neg) {
+		v--
+	}
+	if ebiten.IsKeyPressed(pos) {
+		v++
+	}
+	return v
+}
+
+// touchSource reads the touch screen. A tap plays again once a run is
+// over.
+type touchSource struct{}
+
+func (touchSource) read() inputState {
+	var s inputState
+	if ids := inpututil.AppendJustPressedTouchIDs(nil); len(ids) > 0 {
+		s.pressed[inputRestart] = true
+		s.tapped, s.tap = true, image.Pt(ebiten.TouchPosition(ids[0]))
+		s.used, s.device = true, deviceTouch
+	}
+	return s
+}
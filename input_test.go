@@ -0,0 +1,47 @@
+package main
+
+import (
+	"image"
+	"reflect"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestMergedInputCombinesSources(t *testing.T) {
+	pad := &scriptedInput{state: inputState{
+		stepY:   1,
+		buttons: []padPress{{id: 0, button: ebiten.StandardGamepadButtonRightBottom}},
+	}}
+	keys := &scriptedInput{state: inputState{
+		stepX:   -1,
+		keys:    []ebiten.Key{ebiten.KeyY},
+		chars:   []rune("y"),
+		pointer: image.Pt(40, 30),
+		clicked: true,
+	}}
+	touch := &scriptedInput{state: inputState{tapped: true, tap: image.Pt(5, 6)}}
+	touch.press(inputRestart)
+
+	s := mergedInput{pad, keys, touch}.read()
+	if s.stepX != 0 || s.stepY != 1 {
+		t.Errorf("menu step (%d, %d), want the pad's (0, 1)", s.stepX, s.stepY)
+	}
+	if !s.padPressed(ebiten.StandardGamepadButtonRightBottom) || !s.keyPressed(ebiten.KeyY) {
+		t.Errorf("lost a press: buttons %v, keys %v", s.buttons, s.keys)
+	}
+	if !reflect.DeepEqual(s.chars, []rune("y")) {
+		t.Errorf("chars %q, want \"y\"", string(s.chars))
+	}
+	if s.pointer != image.Pt(40, 30) || !s.clicked {
+		t.Errorf("pointer %v clicked %v, want the mouse's (40,30) clicked", s.pointer, s.clicked)
+	}
+	if !s.tapped || s.tap != image.Pt(5, 6) || !s.justPressed(inputRestart) {
+		t.Errorf("tap %v at %v, restart %v; want the touch screen's", s.tapped, s.tap, s.justPressed(inputRestart))
+	}
+
+	s = mergedInput{pad, keys, touch}.read()
+	if s.keyPressed(ebiten.KeyY) || s.clicked || s.tapped || s.stepY != 0 {
+		t.Error("this tick's presses carried over to the next")
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+// inputDevice is a kind of device the player can play with.
+type inputDevice int
+
+const (
+	deviceKeyboard inputDevice = iota
+	deviceGamepad
+	deviceTouch
+)
+
+// updateInputDevice notes which kind of device was used last, so prompts
+// can name the buttons the player is holding.
+func (g *Game) updateInputDevice() {
+	if g.input.used {
+		g.lastDevice = g.input.device
+	}
+}
+
+// retryPressed reports whether any device asked to play again: R or
+// Space, the bound Fire or Restart button, or a tap.
+func (g *Game) retryPressed() bool {
+	return g.input.justPressed(inputFire) || g.input.justPressed(inputRestart)
+}
+
+// retryPrompt tells the player how to play again or leave, in terms of the
+// device they last used.
+func (g *Game) retryPrompt() string {
+	switch g.lastDevice {
+	case deviceTouch:
+		return tr("gameover.retry_touch")
+	case deviceGamepad:
+		if pads := standardPads(); len(pads) > 0 {
+			l := g.settings.padLayout(pads[0])
+			return trf("gameover.retry", l.binding(padFire).label(), l.binding(padMenu).label())
+		}
+	}
+	return trf("gameover.retry", tr("key.space"), tr("key.esc"))
+}
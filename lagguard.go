@@ -0,0 +1,32 @@
+package main
+
+import (
+	"log/slog"
+	"time"
+)
+
+const lagPauseTicks = 15 // A gap between updates this many ticks long pauses the run
+
+// lagGuard watches the wall-clock time between updates. After a long stall
+// Ebiten runs a burst of catch-up ticks the player never gets to see, so
+// asteroids would seem to jump through the ship.
+type lagGuard struct {
+	clock func() time.Time // Tells the time; time.Now if nil
+	last  time.Time
+}
+
+// check notes an update and reports whether the time since the previous
+// one was over the limit for a tick this long.
+func (l *lagGuard) check(tick time.Duration) bool {
+	now := time.Now()
+	if l.clock != nil {
+		now = l.clock()
+	}
+	gap := now.Sub(l.last)
+	stalled := !l.last.IsZero() && gap > lagPauseTicks*tick
+	l.last = now
+	if stalled {
+		slog.Warn("update stalled; pausing the run", "gap", gap, "budget", tick)
+	}
+	return stalled
+}
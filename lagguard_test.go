@@ -0,0 +1,78 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// testClock is a clock that only moves when a test moves it.
+type testClock struct {
+	t time.Time
+}
+
+func newTestClock() *testClock {
+	return &testClock{t: time.Unix(1000, 0)}
+}
+
+func (c *testClock) now() time.Time {
+	return c.t
+}
+
+func (c *testClock) advance(d time.Duration) {
+	c.t = c.t.Add(d)
+}
+
+func TestLagGuardCheck(t *testing.T) {
+	const tick = time.Second / 60
+	tests := []struct {
+		name    string
+		gap     time.Duration
+		stalled bool
+	}{
+		{"no time at all", 0, false},
+		{"one tick", tick, false},
+		{"at the limit", lagPauseTicks * tick, false},
+		{"just over the limit", lagPauseTicks*tick + 1, true},
+		{"a second", time.Second, true},
+	}
+	for _, tt := range tests {
+		clock := newTestClock()
+		l := lagGuard{clock: clock.now}
+		if l.check(tick) {
+			t.Fatal("the first update counted as a stall")
+		}
+		clock.advance(tt.gap)
+		if got := l.check(tick); got != tt.stalled {
+			t.Errorf("%s: stalled is %v, want %v", tt.name, got, tt.stalled)
+		}
+	}
+}
+
+func TestStallPausesTheRun(t *testing.T) {
+	clock := newTestClock()
+	g, in := newTestGame(t, WithClock(clock.now))
+	for range 30 {
+		clock.advance(time.Second / 60)
+		updates(t, g, 1)
+	}
+	at, hash := g.world.Time, g.world.Hash()
+
+	// A long frame: the catch-up updates that follow must not move the world
+	clock.advance(time.Second)
+	updates(t, g, 10)
+	if !g.paused || !g.lagPause {
+		t.Fatal("a one-second stall didn't pause the run")
+	}
+	if g.world.Time != at || g.world.Hash() != hash {
+		t.Errorf("the world moved on from tick %d to %d after the stall", at, g.world.Time)
+	}
+
+	in.press(inputPause)
+	updates(t, g, 5)
+	if g.paused || g.lagPause {
+		t.Fatal("pause didn't resume the run")
+	}
+	if g.world.Time != at+5 {
+		t.Errorf("world time is %d five updates after resuming, want %d", g.world.Time, at+5)
+	}
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"example/hello/core"
+)
+
+const (
+	lanProtocol        = 2 // Bump whenever the messages change
+	lanDialTimeout     = 10 * time.Second
+	lanReadTimeout     = 5 * time.Second  // Silence after which a connection counts as dropped
+	lanRedialInterval  = time.Second      // Between attempts to rejoin after a drop
+	lanReconnectWindow = 15 * time.Second // How long a match waits for a dropped player before it is forfeited
+)
+
+// lanMessage is one line of the versus protocol. Which fields are set
+// depends on Type.
+type lanMessage struct {
+	Type string `json:"type"` // "hello", "input" or "hash"
+
+	// hello, sent first by both sides on every connection
+	Protocol  int    `json:"protocol,omitempty"` // lanProtocol
+	Version   string `json:"version,omitempty"`  // gameVersion
+	Seed      int64  `json:"seed,omitempty"`     // The match's seed, chosen by the host; 0 from a joiner with no match yet
+	Tuning    int    `json:"tuning,omitempty"`   // core.TuningVersion
+	TuningSum string `json:"tuningSum,omitempty"`
+	TPS       int    `json:"tps,omitempty"`
+
+	Tick  int              `json:"tick,omitempty"`
+	Input *core.FrameInput `json:"input,omitempty"` // input: the sender's input for Tick, numbered in sequence from 1
+	Hash  uint64           `json:"hash,omitempty"`  // hash: the sender's state hash after Tick
+	Ack   int              `json:"ack,omitempty"`   // Every message: the sender has all our inputs up to this tick
+}
+
+// inputLog is our inputs on their way to the other player. Each stays
+// until the other side acknowledges it, so after a dropped connection
+// the ones that may have been lost can be sent again.
+type inputLog struct {
+	acked   int               // Last tick the other side has confirmed
+	pending []core.FrameInput // Inputs for the ticks after acked, in order
+}
+
+func (l *inputLog) add(in core.FrameInput) {
+	l.pending = append(l.pending, in)
+}
+
+// ack drops the inputs up to tick, which the other side now has.
+func (l *inputLog) ack(tick int) {
+	if n := min(tick-l.acked, len(l.pending)); n > 0 {
+		l.pending = append(l.pending[:0], l.pending[n:]...)
+		l.acked += n
+	}
+}
+
+// gameVersion identifies the build, so players on different builds can
+// be told apart. It is the commit Go stamped into the binary, if any.
+func gameVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	version, modified := info.Main.Version, false
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			version = s.Value[:min(len(s.Value), 12)]
+		case "vcs.modified":
+			modified = s.Value == "true"
+		}
+	}
+	if modified {
+		version += "+dirty"
+	}
+	return version
+}
+
+// lanConn exchanges messages with the other player. Reading and writing
+// happen on their own goroutines so the game loop never blocks on the
+// network.
+type lanConn struct {
+	conn net.Conn
+	in   chan lanMessage // Closed when the connection drops
+	out  chan lanMessage
+	done chan struct{} // Closed by close
+	once sync.Once
+}
+
+func newLANConn(conn net.Conn) *lanConn {
+	c := &lanConn{
+		conn: conn,
+		in:   make(chan lanMessage, 256),
+		out:  make(chan lanMessage, 256),
+		done: make(chan struct{}),
+	}
+	go c.read()
+	go c.write()
+	return c
+}
+
+func (c *lanConn) read() {
+	defer close(c.in)
+	dec := json.NewDecoder(bufio.NewReader(c.conn))
+	for {
+		// A match sends every tick, so a quiet connection is a dead one
+		c.conn.SetReadDeadline(time.Now().Add(lanReadTimeout))
+		var m lanMessage
+		if err := dec.Decode(&m); err != nil {
+			return
+		}
+		// Once the match is over nothing takes messages any more
+		select {
+		case c.in <- m:
+		case <-c.done:
+			return
+		}
+	}
+}
+
+func (c *lanConn) write() {
+	w := bufio.NewWriter(c.conn)
+	enc := json.NewEncoder(w)
+	for {
+		select {
+		case m := <-c.out:
+			if enc.Encode(m) != nil {
+				c.close()
+				return
+			}
+			// Batch up whatever else is queued before paying for a flush
+			if len(c.out) == 0 && w.Flush() != nil {
+				c.close()
+				return
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
+
+func (c *lanConn) send(m lanMessage) {
+	select {
+	case c.out <- m:
+	default:
+		// The other side has stopped reading; give up on it
+		c.close()
+	}
+}
+
+func (c *lanConn) close() {
+	c.once.Do(func() {
+		close(c.done)
+		c.conn.Close()
+	})
+}
+
+// lanResult is the outcome of hosting or joining.
+type lanResult struct {
+	conn *lanConn
+	err  error
+}
+
+// hostLAN listens on addr in the background and passes on every player
+// who connects until stop is closed, so a player who drops can come
+// back.
+func hostLAN(addr string, stop <-chan struct{}) <-chan lanResult {
+	ch := make(chan lanResult)
+	go func() {
+		ln, err := net.Listen("tcp", addr)
+		if err != nil {
+			deliver(ch, lanResult{err: err}, stop)
+			return
+		}
+		go func() {
+			<-stop
+			ln.Close()
+		}()
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				deliver(ch, lanResult{err: err}, stop)
+				return
+			}
+			deliver(ch, lanResult{conn: newLANConn(conn)}, stop)
+		}
+	}()
+	return ch
+}
+
+// joinLAN connects in the background to a host at addr, trying again
+// every lanRedialInterval until until has passed. The zero time tries
+// once.
+func joinLAN(addr string, until time.Time, stop <-chan struct{}) <-chan lanResult {
+	ch := make(chan lanResult)
+	go func() {
+		for {
+			conn, err := net.DialTimeout("tcp", addr, lanDialTimeout)
+			if err == nil {
+				deliver(ch, lanResult{conn: newLANConn(conn)}, stop)
+				return
+			}
+			if time.Now().Add(lanRedialInterval).After(until) {
+				deliver(ch, lanResult{err: err}, stop)
+				return
+			}
+			select {
+			case <-time.After(lanRedialInterval):
+			case <-stop:
+				return
+			}
+		}
+	}()
+	return ch
+}
+
+// deliver passes r on unless stop is closed first, closing a connection
+// nobody is left to take.
+func deliver(ch chan<- lanResult, r lanResult, stop <-chan struct{}) {
+	select {
+	case ch <- r:
+	case <-stop:
+		if r.conn != nil {
+			r.conn.close()
+		}
+	}
+}
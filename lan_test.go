@@ -0,0 +1,178 @@
+package main
+
+import (
+	"math"
+	"net"
+	"testing"
+	"time"
+
+	"example/hello/core"
+)
+
+// pipeMatch is one side of a versus match whose connections are handed
+// over by the test, over in-memory pipes, rather than made on a network.
+type pipeMatch struct {
+	*versusMatch
+	arrivals chan lanResult
+}
+
+func newPipeMatch(host bool) *pipeMatch {
+	p := &pipeMatch{arrivals: make(chan lanResult, 1)}
+	p.versusMatch = &versusMatch{host: host, inputDelay: versusInputDelay, tuning: core.DefaultTuning, stop: make(chan struct{}), connecting: p.arrivals}
+	if host {
+		p.seed = 42
+	} else {
+		p.local = 1
+	}
+	return p
+}
+
+// connect joins two sides over a fresh pipe and returns the host's end,
+// which the test can close to drop the connection.
+func connect(host, joiner *pipeMatch) net.Conn {
+	a, b := net.Pipe()
+	host.arrivals <- lanResult{conn: newLANConn(a)}
+	joiner.arrivals <- lanResult{conn: newLANConn(b)}
+	joiner.connecting = joiner.arrivals // In place of redialing the host
+	return a
+}
+
+// scriptedVersusInput is the input a side plays on tick t: each ship
+// steers its own pattern and fires now and then.
+func scriptedVersusInput(side, t int) core.FrameInput {
+	return core.FrameInput{
+		MoveX:       math.Sin(float64(t) / float64(20+7*side)),
+		FirePressed: (t+side*5)%25 == 0,
+	}
+}
+
+func (p *pipeMatch) update() {
+	p.versusMatch.update(func() core.FrameInput { return scriptedVersusInput(p.local, p.nextInput) })
+}
+
+// pumpUntil updates both sides, each until it has simulated tick, and
+// fails if that takes longer than a few seconds.
+func pumpUntil(t *testing.T, tick int, sides ...*pipeMatch) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		done := true
+		for _, s := range sides {
+			if s.aborted != "" {
+				t.Fatalf("the match ended: %s", s.aborted)
+			}
+			if s.tick < tick {
+				s.update()
+				done = false
+			}
+		}
+		if done {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("stuck at ticks %d and %d on the way to %d", sides[0].tick, sides[1].tick, tick)
+		}
+		time.Sleep(100 * time.Microsecond)
+	}
+}
+
+// offlineWorlds plays both ships' scripts to tick with no network at all.
+func offlineWorlds(seed int64, tick int) [2]*core.World {
+	v := &versusMatch{inputDelay: versusInputDelay, tuning: core.DefaultTuning}
+	v.begin(seed)
+	for t := 1; t <= tick; t++ {
+		for side, w := range v.worlds {
+			in := core.FrameInput{}
+			if t > v.inputDelay {
+				in = scriptedVersusInput(side, t)
+			}
+			w.Step(in)
+		}
+	}
+	return v.worlds
+}
+
+func TestVersusSurvivesADroppedConnection(t *testing.T) {
+	host, joiner := newPipeMatch(true), newPipeMatch(false)
+	defer host.disconnect()
+	defer joiner.disconnect()
+
+	link := connect(host, joiner)
+	pumpUntil(t, 2*versusHashInterval+10, host, joiner)
+	if joiner.seed != host.seed {
+		t.Fatalf("the joiner plays seed %d, the host %d", joiner.seed, host.seed)
+	}
+
+	// Cut the link. Both sides notice and hold the match
+	link.Close()
+	deadline := time.Now().Add(5 * time.Second)
+	for host.lostAt.IsZero() || joiner.lostAt.IsZero() {
+		if time.Now().After(deadline) {
+			t.Fatal("neither side noticed the connection drop")
+		}
+		host.update()
+		joiner.update()
+		time.Sleep(100 * time.Microsecond)
+	}
+	held := max(host.tick, joiner.tick)
+	for range 50 {
+		host.update()
+		joiner.update()
+	}
+	if host.tick > held+versusInputDelay || joiner.tick > held+versusInputDelay {
+		t.Errorf("play ran on from tick %d to %d and %d with nobody connected", held, host.tick, joiner.tick)
+	}
+
+	// The joiner comes back; play resumes and catches up on every input
+	connect(host, joiner)
+	const end = 6 * versusHashInterval
+	pumpUntil(t, end, host, joiner)
+	if !host.lostAt.IsZero() || !joiner.lostAt.IsZero() {
+		t.Error("a side still thinks the connection is down")
+	}
+	want := offlineWorlds(host.seed, end)
+	for _, side := range []*pipeMatch{host, joiner} {
+		for i, w := range side.worlds {
+			if w.Time != end || w.Hash() != want[i].Hash() {
+				t.Errorf("host %v: ship %d's field at tick %d differs from an unbroken run", side.host, i, w.Time)
+			}
+		}
+	}
+}
+
+func TestVersusRefusesAMismatchedHello(t *testing.T) {
+	hello := func() lanMessage {
+		return lanMessage{Type: "hello", Protocol: lanProtocol, Version: gameVersion(), Seed: 42,
+			Tuning: core.TuningVersion, TuningSum: core.DefaultTuning.Checksum(), TPS: 60}
+	}
+	tests := []struct {
+		name  string
+		spoil func(*lanMessage)
+	}{
+		{"protocol", func(m *lanMessage) { m.Protocol-- }},
+		{"version", func(m *lanMessage) { m.Version += "+other" }},
+		{"tuning version", func(m *lanMessage) { m.Tuning++ }},
+		{"tuning values", func(m *lanMessage) { m.TuningSum = "other" }},
+		{"tick rate", func(m *lanMessage) { m.TPS = 30 }},
+	}
+	for _, tt := range tests {
+		joiner := newPipeMatch(false)
+		m := hello()
+		tt.spoil(&m)
+		joiner.handle(m)
+		if joiner.aborted == "" || joiner.worlds[0] != nil {
+			t.Errorf("a hello with a different %s started a match", tt.name)
+		}
+	}
+
+	joiner := newPipeMatch(false)
+	joiner.handle(lanMessage{Type: "input", Tick: 1, Input: &core.FrameInput{}})
+	if joiner.aborted == "" {
+		t.Error("a connection that skipped the hello was accepted")
+	}
+	joiner = newPipeMatch(false)
+	joiner.handle(hello())
+	if joiner.aborted != "" || joiner.seed != 42 {
+		t.Errorf("a matching hello was refused: %q", joiner.aborted)
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"image/color"
+	"log/slog"
+	"sync/atomic"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+// assetLoad is asset loading running in the background while the splash
+// shows.
+type assetLoad struct {
+	total  int
+	loaded atomic.Int32
+	done   chan struct{}
+
+	// Set before done closes
+	sprites sprites
+	err     error
+}
+
+// startLoading begins loading the game's assets; the splash shows until
+// they are in.
+func (g *Game) startLoading() {
+	l := g.newAssetLoader()
+	a := &assetLoad{total: len(spriteFiles), done: make(chan struct{})}
+	go func() {
+		defer close(a.done)
+		a.sprites, a.err = loadSprites(l, func() { a.loaded.Add(1) })
+	}()
+	g.loading = a
+}
+
+// progress is the fraction of assets loaded, from 0 to 1.
+func (a *assetLoad) progress() float64 {
+	if a.total == 0 {
+		return 1
+	}
+	return float64(a.loaded.Load()) / float64(a.total)
+}
+
+// finished reports whether loading is over, successfully or not.
+func (a *assetLoad) finished() bool {
+	select {
+	case <-a.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// updateLoading runs instead of the rest of Update until the assets are
+// in. A failed load waits for the player to retry, carry on without the
+// missing files, or quit.
+func (g *Game) updateLoading() error {
+	a := g.loading
+	if !a.finished() {
+		return nil
+	}
+	if a.err == nil {
+		g.sprites = a.sprites
+		g.loading = nil
+		return nil
+	}
+	in := g.readMenuInput(nil)
+	switch {
+	case g.input.keyPressed(ebiten.KeyR) || g.input.padPressed(ebiten.StandardGamepadButtonRightTop):
+		slog.Info("retrying asset load")
+		g.startLoading()
+	case in.confirm:
+		// Whatever did load is used; the rest is drawn as plain shapes
+		g.sprites = a.sprites
+		g.loading = nil
+	case in.cancel:
+		return errQuit
+	}
+	return nil
+}
+
+func (g *Game) drawLoading(screen *ebiten.Image) {
+	const width, height = 200, 10
+	a := g.loading
+	drawCentered(screen, tr("title.name"), screenHeight/2-60)
+	x, y := float64(screenWidth/2-width/2), float64(screenHeight/2-height/2)
+	ebitenutil.DrawRect(screen, x, y, width, height, color.RGBA{60, 60, 60, 255})
+	ebitenutil.DrawRect(screen, x, y, width*a.progress(), height, color.RGBA{0, 200, 0, 255})
+	if !a.finished() {
+		drawCentered(screen, tr("loading.title"), screenHeight/2+20)
+		return
+	}
+	if a.err != nil {
+		drawCentered(screen, trf("loading.failed", a.err), screenHeight/2+20)
+		drawCentered(screen, tr("loading.help"), screenHeight/2+60)
+	}
+}
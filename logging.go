@@ -0,0 +1,28 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+)
+
+// logLevel is the minimum level written to stderr, set by -loglevel.
+var logLevel = new(slog.LevelVar)
+
+// setupLogging installs a leveled text logger on stderr as the default
+// logger. Level is one of debug, info, warn or error.
+func setupLogging(level string) error {
+	levels := map[string]slog.Level{
+		"debug": slog.LevelDebug,
+		"info":  slog.LevelInfo,
+		"warn":  slog.LevelWarn,
+		"error": slog.LevelError,
+	}
+	l, ok := levels[level]
+	if !ok {
+		return fmt.Errorf("unknown log level %q", level)
+	}
+	logLevel.Set(l)
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: logLevel})))
+	return nil
+}
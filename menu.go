@@ -0,0 +1,337 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+
+	"example/hello/core"
+)
+
+type screenID int
+
+const assistedBoardRows = 5 // Assisted high scores shown under the mode's own on the title screen
+
+const (
+	screenPlaying screenID = iota
+	screenProfiles
+	screenTitle
+	screenOptions
+	screenControls
+	screenShips
+	screenVersus
+	screenSpectate
+	screenRuns
+	screenAssists
+	screenMutators
+	screenPractice
+	screenStats
+)
+
+// profileMenu is the state of the profile select/create screen.
+type profileMenu struct {
+	names    []string
+	list     menuList // Its cursor indexes names; len(names) is the "new profile" entry
+	naming   bool     // Typing the name of a new profile
+	name     string   // Name typed so far
+	deleting bool     // Waiting for the player to confirm deleting names[cursor]
+	err      string
+}
+
+func (g *Game) openProfiles() {
+	names, err := listProfiles()
+	g.profileMenu = profileMenu{names: names}
+	if err != nil {
+		g.profileMenu.err = err.Error()
+	}
+	g.screen = screenProfiles
+}
+
+// useProfile makes p the active profile and reloads everything it owns.
+func (g *Game) useProfile(p *Profile) {
+	g.profile = p
+	g.settings = p.Settings
+	p.unlockShips() // Stats from before ships could be unlocked still count
+	g.applyOverrides()
+	setLanguage(g.settings.Language)
+	g.saveErr = writeLastProfile(p.Name)
+	g.reset()
+	g.screen = screenTitle
+}
+
+func (g *Game) updateProfiles() {
+	m := &g.profileMenu
+
+	if m.naming {
+		for _, r := range g.input.chars {
+			if isProfileNameRune(r) && len(m.name) < maxProfileName {
+				m.name += string(r)
+			}
+		}
+		switch {
+		case g.input.keyPressed(ebiten.KeyBackspace) && len(m.name) > 0:
+			m.name = m.name[:len(m.name)-1]
+		case g.input.keyPressed(ebiten.KeyEscape):
+			m.naming = false
+		case g.input.keyPressed(ebiten.KeyEnter):
+			p, err := createProfile(m.name)
+			if err != nil {
+				m.err = err.Error()
+				return
+			}
+			g.useProfile(p)
+		}
+		return
+	}
+
+	in := g.readMenuInput(nil)
+	if m.deleting {
+		switch {
+		case g.input.keyPressed(ebiten.KeyY) || in.confirm:
+			if err := deleteProfile(m.names[m.list.cursor]); err != nil {
+				m.err = err.Error()
+			}
+			g.openProfiles()
+		case g.input.keyPressed(ebiten.KeyN) || in.cancel:
+			m.deleting = false
+		}
+		return
+	}
+
+	// The gamepad's X deletes, as Delete does
+	if (g.input.keyPressed(ebiten.KeyDelete) || g.input.padPressed(ebiten.StandardGamepadButtonRightLeft)) && m.list.cursor < len(m.names) {
+		m.deleting = true
+		return
+	}
+	m.list.handle(in, g.profileItems())
+}
+
+// profileItems are the saved profiles, then the entry that creates one.
+func (g *Game) profileItems() []menuItem {
+	m := &g.profileMenu
+	items := make([]menuItem, 0, len(m.names)+1)
+	for _, name := range m.names {
+		items = append(items, menuItem{label: name, activate: func() {
+			p, err := loadProfile(name)
+			if err != nil {
+				m.err = err.Error()
+				return
+			}
+			g.useProfile(p)
+		}})
+	}
+	return append(items, menuItem{label: tr("profiles.new"), activate: func() {
+		m.naming = true
+		m.name = ""
+		m.err = ""
+	}})
+}
+
+func (g *Game) drawProfiles(screen *ebiten.Image) {
+	m := &g.profileMenu
+	drawCentered(screen, tr("profiles.title"), 60)
+
+	y := m.list.draw(screen, g.profileItems(), menuLayout{x: screenWidth/2 - 100, y: 100, width: 220, row: 20})
+	y += 20
+	switch {
+	case m.naming:
+		ebitenutil.DebugPrintAt(screen, trf("profiles.name", m.name), screenWidth/2-100, y)
+		ebitenutil.DebugPrintAt(screen, tr("profiles.create_help"), screenWidth/2-100, y+20)
+	case m.deleting:
+		ebitenutil.DebugPrintAt(screen, trf("profiles.delete_confirm", m.names[m.list.cursor]), screenWidth/2-100, y)
+	default:
+		ebitenutil.DebugPrintAt(screen, tr("profiles.help"), screenWidth/2-100, y)
+	}
+	if m.err != "" {
+		ebitenutil.DebugPrintAt(screen, m.err, screenWidth/2-100, y+40)
+	}
+}
+
+// titleItems are the rows of the title menu, each with the key that
+// picks it from anywhere.
+func (g *Game) titleItems() []menuItem {
+	toggle := func(label string, on bool, key ebiten.Key, field func(*Settings) *bool) menuItem {
+		return menuItem{label: trf(label, onOff(on)), keys: []ebiten.Key{key}, adjust: func(int) { g.toggleSetting(field) }}
+	}
+	return []menuItem{
+		{label: tr("title.start"), activate: func() {
+			if g.settings.Mode == core.ModeEndless {
+				g.openMutators() // Which starts the run once they're chosen
+				return
+			}
+			g.startRun()
+		}},
+		{label: g.dailyRow(), keys: []ebiten.Key{ebiten.KeyY}, activate: g.startDaily},
+		{label: tr("title.practice"), keys: []ebiten.Key{ebiten.KeyA}, activate: g.openPractice},
+		{label: trf("title.mode", modeName(g.settings.Mode)), keys: []ebiten.Key{ebiten.KeyM}, adjust: func(dir int) {
+			g.profile.Settings.Mode = (g.settings.Mode + core.Mode(dir) + core.ModeCount) % core.ModeCount
+			g.settings.Mode = g.profile.Settings.Mode
+			g.saveErr = g.profile.save()
+		}},
+		{label: trf("title.ship", tr("ship."+core.Ships[g.shipIndex()].Name)), keys: []ebiten.Key{ebiten.KeyH}, activate: g.openShips},
+		toggle("title.wrap", g.settings.Wrap, ebiten.KeyW, func(s *Settings) *bool { return &s.Wrap }),
+		toggle("title.smooth", !g.settings.Chunky, ebiten.KeyS, func(s *Settings) *bool { return &s.Chunky }),
+		toggle("title.ticker", !g.settings.HideTicker, ebiten.KeyT, func(s *Settings) *bool { return &s.HideTicker }),
+		toggle("title.ghost", !g.settings.HideGhost, ebiten.KeyG, func(s *Settings) *bool { return &s.HideGhost }),
+		{label: trf("title.language", languageName(g.settings.Language)), keys: []ebiten.Key{ebiten.KeyL}, adjust: func(int) {
+			g.profile.Settings.Language = nextLanguage(g.settings.Language)
+			g.settings.Language = g.profile.Settings.Language
+			setLanguage(g.settings.Language)
+			g.saveErr = g.profile.save()
+		}},
+		toggle("title.twin_stick", g.settings.TwinStick, ebiten.KeyC, func(s *Settings) *bool { return &s.TwinStick }),
+		toggle("title.vibration", !g.settings.NoVibration, ebiten.KeyV, func(s *Settings) *bool { return &s.NoVibration }),
+		toggle("title.discord", g.settings.DiscordPresence, ebiten.KeyD, func(s *Settings) *bool { return &s.DiscordPresence }),
+		{label: tr("title.versus_cpu"), keys: []ebiten.Key{ebiten.KeyB}, activate: func() { g.startVersusCPU(core.BotSkills[g.cpuSkill()]) }},
+		{label: trf("title.cpu_skill", tr("skill."+core.BotSkills[g.cpuSkill()].Name)), keys: []ebiten.Key{ebiten.KeyK}, adjust: func(dir int) {
+			g.profile.Settings.CPUSkill = (g.cpuSkill() + dir + len(core.BotSkills)) % len(core.BotSkills)
+			g.settings.CPUSkill = g.profile.Settings.CPUSkill
+			g.saveErr = g.profile.save()
+		}},
+		{label: tr("title.options"), keys: []ebiten.Key{ebiten.KeyO}, activate: func() {
+			g.optionsMenu = menuList{}
+			g.screen = screenOptions
+		}},
+		{label: tr("title.runs"), keys: []ebiten.Key{ebiten.KeyI}, activate: g.openRuns},
+		{label: tr("title.stats"), keys: []ebiten.Key{ebiten.KeyJ}, activate: func() { g.screen = screenStats }},
+		{label: tr("title.switch_profile"), keys: []ebiten.Key{ebiten.KeyP}, activate: g.openProfiles},
+	}
+}
+
+// startRun starts a fresh run from the menus.
+func (g *Game) startRun() {
+	g.daily = ""
+	g.playback = nil
+	g.practice = 0
+	g.reset()
+	g.screen = screenPlaying
+}
+
+func (g *Game) updateTitle() {
+	items := g.titleItems()
+	g.titleMenu.handle(g.readMenuInput(items), items)
+}
+
+// cpuSkill is the chosen bot skill, falling back to the easiest if the
+// profile's choice is out of range.
+func (g *Game) cpuSkill() int {
+	if g.settings.CPUSkill < 0 || g.settings.CPUSkill >= len(core.BotSkills) {
+		return 0
+	}
+	return g.settings.CPUSkill
+}
+
+// toggleSetting flips a boolean setting on the profile, applies it to the
+// session and saves the profile.
+func (g *Game) toggleSetting(field func(*Settings) *bool) {
+	v := field(&g.profile.Settings)
+	*v = !*v
+	*field(&g.settings) = *v
+	g.saveErr = g.profile.save()
+}
+
+func (g *Game) drawTitle(screen *ebiten.Image) {
+	cx := 100
+	drawCentered(screen, tr("title.name"), 60)
+	ebitenutil.DebugPrintAt(screen, trf("title.profile", g.profile.Name), cx, 100)
+	ebitenutil.DebugPrintAt(screen, trf("title.games_played", g.profile.Stats.GamesPlayed), cx, 120)
+
+	y := g.titleMenu.draw(screen, g.titleItems(), menuLayout{x: cx, y: 160, width: 300, row: 18})
+
+	// High scores sit in a column to the right of the menu, or under it
+	// on a portrait screen
+	sx, sy := screenWidth-180, 160
+	if screenHeight > screenWidth {
+		sx, sy = cx, y+20
+	}
+	// The boards are those of the mutators chosen for the next run
+	set := g.runMutators()
+	key, name := modeKey(g.settings.Mode)+mutatorSuffix(set.keys()), modeName(g.settings.Mode)
+	if set != 0 {
+		name = trf("title.mutated", name, set.scoreScale())
+	}
+	board := g.profile.Leaderboards[key]
+	sy = drawBoard(screen, trf("title.high_scores", name), board, sx, sy)
+	// Assisted runs follow, as many as fit
+	assisted := g.profile.Leaderboards[key+"-assisted"]
+	assisted = assisted[:min(len(assisted), assistedBoardRows)]
+	if len(assisted) > 0 {
+		sy = drawBoard(screen, trf("title.assisted_scores", name), assisted, sx, sy+12)
+	}
+	for i, note := range boardNotes(board, assisted) {
+		ebitenutil.DebugPrintAt(screen, note, sx, sy+8+i*16)
+	}
+
+	if g.saveErr != nil {
+		g.hud().bottomLeft(screen, trf("save_failed", g.saveErr), 0)
+	}
+}
+
+// drawBoard lists a leaderboard under its title at (x, y), marking the
+// entries boardNotes explains. It returns the y just past the last entry.
+func drawBoard(screen *ebiten.Image, title string, board []leaderboardEntry, x, y int) int {
+	ebitenutil.DebugPrintAt(screen, title, x, y)
+	for i, e := range board {
+		line := fmt.Sprintf("%2d. %d", i+1, e.Score)
+		if e.Tuning != "" {
+			line += " *"
+		}
+		if e.KillsOnly {
+			line += " +"
+		}
+		ebitenutil.DebugPrintAt(screen, line, x, y+18+i*16)
+	}
+	return y + 18 + len(board)*16
+}
+
+// boardNotes explains the marks on the entries of the boards shown: a
+// modified tuning, or scoring without dodge points.
+func boardNotes(boards ...[]leaderboardEntry) []string {
+	var tuned, killsOnly bool
+	for _, board := range boards {
+		for _, e := range board {
+			tuned = tuned || e.Tuning != ""
+			killsOnly = killsOnly || e.KillsOnly
+		}
+	}
+	var notes []string
+	if tuned {
+		notes = append(notes, tr("title.tuned"))
+	}
+	if killsOnly {
+		notes = append(notes, tr("title.kills_only"))
+	}
+	return notes
+}
+
+// dailyRow offers today's challenge, with the best score if it has been
+// played.
+func (g *Game) dailyRow() string {
+	if best, ok := g.profile.Daily[today()]; ok {
+		return trf("title.daily_best", best)
+	}
+	return tr("title.daily")
+}
+
+// modeKeys name the modes in lang keys and saved leaderboards.
+var modeKeys = [core.ModeCount]string{"endless", "stage", "hardcore", "twin_stick"}
+
+// modeKey is a mode's name, falling back to endless for one out of range.
+func modeKey(m core.Mode) string {
+	if m < 0 || m >= core.ModeCount {
+		return modeKeys[core.ModeEndless]
+	}
+	return modeKeys[m]
+}
+
+func modeName(m core.Mode) string {
+	return tr("mode." + modeKey(m))
+}
+
+func onOff(b bool) string {
+	if b {
+		return tr("on")
+	}
+	return tr("off")
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"slices"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+const (
+	menuRepeatDelay = 0.4  // Seconds a direction is held before it starts repeating
+	menuRepeatEvery = 0.08 // Seconds between repeats after that
+	menuHighlight   = 18   // Pixel height of the focus highlight
+	menuBarWidth    = 130  // Room a value bar takes before the value printed after it
+)
+
+// menuItem is one entry of a menu. Screens build their items afresh every
+// tick, so labels and values always show the current state.
+type menuItem struct {
+	label string
+	value string       // Printed in the value column
+	bar   bool         // Draw a bar filled to fill in the value column, before value
+	fill  float64      // From 0 to 1
+	keys  []ebiten.Key // Shortcuts that activate the item wherever the focus is
+
+	activate func()        // Confirm, click or tap; nil falls back to adjust(1)
+	adjust   func(dir int) // Left/Right by -1 or 1; nil when there's nothing to change
+}
+
+// fire activates the item.
+func (it menuItem) fire() {
+	switch {
+	case it.activate != nil:
+		it.activate()
+	case it.adjust != nil:
+		it.adjust(1)
+	}
+}
+
+// menuInput is one tick of menu input from every device at once, so each
+// menu works the same from the keyboard, a gamepad, a mouse or a touch
+// screen.
+type menuInput struct {
+	vert, horz int         // -1, 0 or 1 from the arrows or d-pad, repeating while held
+	confirm    bool        // Enter or A
+	cancel     bool        // Esc, B or the right mouse button
+	pick       int         // Item whose shortcut was pressed, or -1
+	point      image.Point // Mouse or touch position
+	pointed    bool        // The mouse moved or the screen was touched, so focus follows point
+	click      bool        // Left click or tap at point
+}
+
+// menuList is the focus and layout of a list of menu items. Up/Down move
+// the focus, wrapping around at the ends, and Left/Right adjust the focused
+// item. An across list runs left to right, so the two swap.
+type menuList struct {
+	cursor int
+	across bool
+	first  int               // First item drawn when they don't all fit
+	rects  []image.Rectangle // Where each item was drawn last frame, by index; empty if it wasn't
+}
+
+// handle applies a tick of input to the list. It does at most one thing,
+// so a single press never both moves the focus and activates an item, nor
+// activates two.
+func (l *menuList) handle(in menuInput, items []menuItem) {
+	n := len(items)
+	if n == 0 {
+		l.cursor = 0
+		return
+	}
+	l.cursor = min(max(l.cursor, 0), n-1)
+	move, adjust := in.vert, in.horz
+	if l.across {
+		move, adjust = in.horz, in.vert
+	}
+	hit := l.at(in.point, n)
+	if in.pointed && hit >= 0 {
+		l.cursor = hit
+	}
+	switch {
+	case in.pick >= 0 && in.pick < n:
+		l.cursor = in.pick
+		items[in.pick].fire()
+	case in.click:
+		if hit >= 0 {
+			l.cursor = hit
+			items[hit].fire()
+		}
+	case move != 0:
+		l.cursor = (l.cursor + move + n) % n
+	case adjust != 0:
+		if it := items[l.cursor]; it.adjust != nil {
+			it.adjust(adjust)
+		}
+	case in.confirm:
+		items[l.cursor].fire()
+	}
+}
+
+// at returns the index of the item drawn under p, or -1.
+func (l *menuList) at(p image.Point, n int) int {
+	for i, r := range l.rects[:min(n, len(l.rects))] {
+		if p.In(r) {
+			return i
+		}
+	}
+	return -1
+}
+
+// place records where item i was drawn, for lists a screen lays out itself.
+func (l *menuList) place(i, n int, r image.Rectangle) {
+	if len(l.rects) != n {
+		l.rects = slices.Grow(l.rects[:0], n)[:n]
+		clear(l.rects)
+	}
+	l.rects[i] = r
+}
+
+// repeats reports whether a direction held for this many ticks steps the
+// focus: when first pressed, then regularly once held past the delay.
+func repeats(held, tps int) bool {
+	delay, every := int(menuRepeatDelay*float64(tps)), max(1, int(menuRepeatEvery*float64(tps)))
+	return held == 1 || held > delay && (held-delay)%every == 0
+}
+
+// menuLayout places a vertical list on screen.
+type menuLayout struct {
+	x, y   int // Where the first label is printed
+	width  int // Of the focus highlight
+	row    int // Pixels from one item to the next
+	valueX int // Offset of the value column from x
+	rows   int // Items shown at once, scrolling to keep the focus in view; 0 shows all
+}
+
+// draw draws the items down the screen with the focused one highlighted,
+// and returns the y just past the last.
+func (l *menuList) draw(screen *ebiten.Image, items []menuItem, lay menuLayout) int {
+	shown := len(items)
+	if lay.rows > 0 && shown > lay.rows {
+		l.first = min(max(l.first, l.cursor-lay.rows+1), l.cursor, shown-lay.rows)
+		shown = lay.rows
+	} else {
+		l.first = 0
+	}
+	clear(l.rects)
+	y := lay.y
+	for i := l.first; i < l.first+shown; i++ {
+		it := items[i]
+		r := image.Rect(lay.x-10, y-2, lay.x-10+lay.width, y-2+menuHighlight)
+		l.place(i, len(items), r)
+		if i == l.cursor {
+			ebitenutil.DrawRect(screen, float64(r.Min.X), float64(r.Min.Y), float64(r.Dx()), float64(r.Dy()), color.RGBA{0, 80, 0, 255})
+		}
+		ebitenutil.DebugPrintAt(screen, it.label, lay.x, y)
+		vx := lay.x + lay.valueX
+		if it.bar {
+			drawBar(screen, vx, y+4, it.fill)
+			vx += menuBarWidth
+		}
+		if it.value != "" {
+			ebitenutil.DebugPrintAt(screen, it.value, vx, y)
+		}
+		y += lay.row
+	}
+	return y
+}
+
+// drawBar draws a bar filled to fill, from 0 to 1.
+func drawBar(screen *ebiten.Image, x, y int, fill float64) {
+	const width, height = 120, 8
+	ebitenutil.DrawRect(screen, float64(x), float64(y), width, height, color.RGBA{60, 60, 60, 255})
+	ebitenutil.DrawRect(screen, float64(x), float64(y), fill*width, height, color.RGBA{0, 200, 0, 255})
+}
+
+// readMenuInput turns this tick's input into menu input. The items supply
+// the shortcuts.
+func (g *Game) readMenuInput(items []menuItem) menuInput {
+	s := &g.input
+	in := menuInput{vert: s.stepY, horz: s.stepX, pick: -1}
+	in.confirm = s.keyPressed(ebiten.KeyEnter) || s.keyPressed(ebiten.KeyNumpadEnter) ||
+		s.padPressed(ebiten.StandardGamepadButtonRightBottom)
+	in.cancel = s.keyPressed(ebiten.KeyEscape) || s.padPressed(ebiten.StandardGamepadButtonRightRight) ||
+		s.rightClicked
+	for i, it := range items {
+		if slices.ContainsFunc(it.keys, s.keyPressed) {
+			in.pick = i
+			break
+		}
+	}
+
+	in.point = s.pointer
+	in.pointed = in.point != g.menuPointer
+	g.menuPointer = in.point
+	in.click = s.clicked
+	if s.tapped {
+		in.point = s.tap
+		in.pointed, in.click = true, true
+	}
+	return in
+}
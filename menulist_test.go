@@ -0,0 +1,132 @@
+package main
+
+import (
+	"image"
+	"testing"
+)
+
+// countingItems returns n items that count their activations and
+// adjustments by index.
+func countingItems(n int) ([]menuItem, []int, []int) {
+	fired, adjusted := make([]int, n), make([]int, n)
+	items := make([]menuItem, n)
+	for i := range items {
+		items[i] = menuItem{
+			activate: func() { fired[i]++ },
+			adjust:   func(int) { adjusted[i]++ },
+		}
+	}
+	return items, fired, adjusted
+}
+
+// stackedRects lays n items out down the screen, 20 pixels apart.
+func stackedRects(n int) []image.Rectangle {
+	rects := make([]image.Rectangle, n)
+	for i := range rects {
+		rects[i] = image.Rect(0, 20*i, 100, 20*i+18)
+	}
+	return rects
+}
+
+func TestMenuFocusMovesAndWraps(t *testing.T) {
+	tests := []struct {
+		name   string
+		across bool
+		from   int
+		vert   int
+		horz   int
+		want   int
+	}{
+		{name: "down", from: 0, vert: 1, want: 1},
+		{name: "up", from: 2, vert: -1, want: 1},
+		{name: "down off the end wraps to the top", from: 3, vert: 1, want: 0},
+		{name: "up off the top wraps to the end", from: 0, vert: -1, want: 3},
+		{name: "sideways adjusts instead", from: 1, horz: 1, want: 1},
+		{name: "across moves sideways", across: true, from: 3, horz: 1, want: 0},
+		{name: "across adjusts up and down", across: true, from: 2, vert: -1, want: 2},
+		{name: "a focus past the end is pulled back first", from: 9, vert: 1, want: 0},
+	}
+	for _, tt := range tests {
+		items, fired, _ := countingItems(4)
+		l := menuList{cursor: tt.from, across: tt.across}
+		l.handle(menuInput{vert: tt.vert, horz: tt.horz, pick: -1}, items)
+		if l.cursor != tt.want {
+			t.Errorf("%s: focus went from %d to %d, want %d", tt.name, tt.from, l.cursor, tt.want)
+		}
+		for i, n := range fired {
+			if n != 0 {
+				t.Errorf("%s: moving activated item %d", tt.name, i)
+			}
+		}
+	}
+}
+
+func TestMenuSinglePressActivatesOneItem(t *testing.T) {
+	tests := []struct {
+		name  string
+		in    menuInput
+		fired int // Index of the one item activated, or -1
+		focus int
+	}{
+		{"confirm", menuInput{confirm: true, pick: -1}, 1, 1},
+		{"shortcut", menuInput{pick: 3}, 3, 3},
+		{"click", menuInput{click: true, point: image.Pt(10, 45), pick: -1}, 2, 2},
+		{"click between items", menuInput{click: true, point: image.Pt(10, 19), pick: -1}, -1, 1},
+		{"shortcut, click and confirm at once", menuInput{pick: 3, click: true, point: image.Pt(10, 5), confirm: true}, 3, 3},
+		{"click and confirm at once", menuInput{click: true, point: image.Pt(10, 5), confirm: true, pick: -1}, 0, 0},
+		{"move and confirm at once", menuInput{vert: 1, confirm: true, pick: -1}, -1, 2},
+		{"adjust and confirm at once", menuInput{horz: 1, confirm: true, pick: -1}, -1, 1},
+		{"hover and confirm", menuInput{pointed: true, point: image.Pt(10, 65), confirm: true, pick: -1}, 3, 3},
+	}
+	for _, tt := range tests {
+		items, fired, _ := countingItems(4)
+		l := menuList{cursor: 1, rects: stackedRects(4)}
+		l.handle(tt.in, items)
+		for i, n := range fired {
+			want := 0
+			if i == tt.fired {
+				want = 1
+			}
+			if n != want {
+				t.Errorf("%s: item %d was activated %d times, want %d", tt.name, i, n, want)
+			}
+		}
+		if l.cursor != tt.focus {
+			t.Errorf("%s: focus is on %d, want %d", tt.name, l.cursor, tt.focus)
+		}
+	}
+}
+
+func TestMenuAdjustsOnlyTheFocus(t *testing.T) {
+	items, fired, adjusted := countingItems(3)
+	items[2].activate = nil
+	l := menuList{cursor: 2}
+	l.handle(menuInput{horz: -1, pick: -1}, items)
+	l.handle(menuInput{confirm: true, pick: -1}, items) // Falls back to adjust(1)
+	if adjusted[2] != 2 || adjusted[0]+adjusted[1] != 0 {
+		t.Errorf("adjustments by item are %v, want only item 2's, twice", adjusted)
+	}
+	if fired[0]+fired[1]+fired[2] != 0 {
+		t.Errorf("activations by item are %v, want none", fired)
+	}
+}
+
+func TestMenuRepeats(t *testing.T) {
+	tps := 60
+	delay, every := int(menuRepeatDelay*float64(tps)), int(menuRepeatEvery*float64(tps))
+	var steps []int
+	for held := 0; held <= delay+3*every; held++ {
+		if repeats(held, tps) {
+			steps = append(steps, held)
+		}
+	}
+	want := []int{1, delay + every, delay + 2*every, delay + 3*every}
+	if len(steps) != len(want) {
+		t.Fatalf("holding a direction stepped at ticks %v, want %v", steps, want)
+	}
+	for i := range want {
+		if steps[i] != want[i] {
+			t.Fatalf("holding a direction stepped at ticks %v, want %v", steps, want)
+		}
+	}
+}
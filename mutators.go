@@ -0,0 +1,244 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+
+	"example/hello/core"
+)
+
+const tinyShipScale = 0.6 // The tiny ship mutator's hitbox, as a fraction of the ship's
+
+// mutator is a run modifier chosen before an endless run. Each one makes
+// the run harder through hooks into the run's setup or drawing, and adds
+// bonus to the score multiplier.
+type mutator struct {
+	key    string                              // Lang key under "mutators." and the name runs record it by
+	bonus  float64                             // Added to the score multiplier
+	config func(cfg *core.Config)              // Changes the run's rules before it starts; nil for none
+	start  func(g *Game)                       // Changes the front end's state once it has started; nil for none
+	draw   func(g *Game, screen *ebiten.Image) // Draws over the playfield; nil for none
+}
+
+var mutators = []mutator{
+	{key: "double_speed", bonus: 0.5, config: func(cfg *core.Config) { cfg.Tuning.AsteroidSpeed *= 2 }},
+	{key: "one_life", bonus: 1, start: func(g *Game) { g.continuesLeft = 0 }},
+	{key: "tiny_ship", bonus: 0.25, config: func(cfg *core.Config) { cfg.Ship.Size *= tinyShipScale }},
+	{key: "no_powerups", bonus: 0.25, config: func(cfg *core.Config) { cfg.NoWeaponUps = true }},
+	{key: "fog", bonus: 0.75, draw: (*Game).drawFog},
+}
+
+// mutatorSet is the chosen mutators, one bit per entry of mutators.
+type mutatorSet uint
+
+func (s mutatorSet) has(i int) bool {
+	return s&(1<<i) != 0
+}
+
+// keys names the chosen mutators, in table order.
+func (s mutatorSet) keys() []string {
+	var keys []string
+	for i, m := range mutators {
+		if s.has(i) {
+			keys = append(keys, m.key)
+		}
+	}
+	return keys
+}
+
+// scoreScale is the multiplier the chosen mutators earn together.
+func (s mutatorSet) scoreScale() float64 {
+	scale := 1.0
+	for i, m := range mutators {
+		if s.has(i) {
+			scale += m.bonus
+		}
+	}
+	return scale
+}
+
+// mutatorByKey finds a mutator by name, or returns nil for one this build
+// doesn't have.
+func mutatorByKey(key string) *mutator {
+	for i := range mutators {
+		if mutators[i].key == key {
+			return &mutators[i]
+		}
+	}
+	return nil
+}
+
+// runMutators is the mutators the next run is played with. Only endless
+// runs take them.
+func (g *Game) runMutators() mutatorSet {
+	if g.settings.Mode != core.ModeEndless || g.practice > 0 {
+		return 0
+	}
+	return g.mutators
+}
+
+// applyMutators records the run's mutators in its config and lets each
+// change the rules.
+func (g *Game) applyMutators(cfg *core.Config) {
+	set := g.runMutators()
+	if set == 0 {
+		return
+	}
+	cfg.Mutators = set.keys()
+	cfg.ScoreScale = set.scoreScale()
+	for i, m := range mutators {
+		if set.has(i) && m.config != nil {
+			m.config(cfg)
+		}
+	}
+}
+
+// startMutators runs the start hooks of the mutators the world was made
+// with.
+func (g *Game) startMutators() {
+	for _, key := range g.world.Config.Mutators {
+		if m := mutatorByKey(key); m != nil && m.start != nil {
+			m.start(g)
+		}
+	}
+}
+
+// drawMutatorEffects runs the draw hooks of the mutators the world was
+// made with, so a replay looks the way the run did.
+func (g *Game) drawMutatorEffects(screen *ebiten.Image, _ renderView) {
+	for _, key := range g.world.Config.Mutators {
+		if m := mutatorByKey(key); m != nil && m.draw != nil {
+			m.draw(g, screen)
+		}
+	}
+}
+
+// mutatorSuffix tells apart the leaderboards and ghosts of runs with
+// different mutators.
+func mutatorSuffix(keys []string) string {
+	if len(keys) == 0 {
+		return ""
+	}
+	return "+" + strings.Join(keys, "+")
+}
+
+func (g *Game) openMutators() {
+	g.mutatorsMenu = menuList{}
+	g.screen = screenMutators
+}
+
+// mutatorItems are a toggle for each mutator, then the row that starts
+// the run.
+func (g *Game) mutatorItems() []menuItem {
+	items := make([]menuItem, 0, len(mutators)+1)
+	for i, m := range mutators {
+		items = append(items, menuItem{
+			label:  trf("mutators.row", tr("mutators."+m.key), int(math.Round(m.bonus*100))),
+			value:  onOff(g.mutators.has(i)),
+			adjust: func(int) { g.mutators ^= 1 << i },
+		})
+	}
+	return append(items, menuItem{label: tr("mutators.start"), activate: g.startRun})
+}
+
+func (g *Game) updateMutators() {
+	items := g.mutatorItems()
+	in := g.readMenuInput(items)
+	if in.cancel {
+		g.screen = screenTitle
+		return
+	}
+	g.mutatorsMenu.handle(in, items)
+}
+
+func (g *Game) drawMutators(screen *ebiten.Image) {
+	cx := screenWidth/2 - 150
+	drawCentered(screen, tr("mutators.title"), 60)
+	y := g.mutatorsMenu.draw(screen, g.mutatorItems(), menuLayout{x: cx, y: 120, width: 320, row: 24, valueX: 260})
+	ebitenutil.DebugPrintAt(screen, trf("mutators.multiplier", g.mutators.scoreScale()), cx, y+20)
+	ebitenutil.DebugPrintAt(screen, tr("mutators.note"), cx, y+40)
+	ebitenutil.DebugPrintAt(screen, tr("mutators.help"), cx, y+60)
+}
+
+const (
+	fogTextureWidth  = 256  // Tiled across the screen
+	fogTextureHeight = 128  // Stretched over the fogged band
+	fogCell          = 32   // Pixels between the coarse noise's lattice points
+	fogEdge          = 0.35 // Fraction of the band, at its bottom, over which the fog thins out
+	fogDrift         = 12   // Pixels per second the fog drifts sideways
+)
+
+// fogTexture is the fog of war: wisps of noise, opaque at the top and
+// thinning raggedly toward the bottom so asteroids emerge from it. It
+// tiles left to right.
+var fogTexture = func() *ebiten.Image {
+	r := rand.New(rand.NewSource(1)) // The same fog every time
+	type octave struct {
+		cell    int
+		weight  float64
+		lattice [][]float64
+	}
+	octaves := []octave{{cell: fogCell, weight: 0.65}, {cell: fogCell / 2, weight: 0.35}}
+	for i := range octaves {
+		o := &octaves[i]
+		o.lattice = make([][]float64, fogTextureHeight/o.cell+2)
+		for j := range o.lattice {
+			o.lattice[j] = make([]float64, fogTextureWidth/o.cell)
+			for k := range o.lattice[j] {
+				o.lattice[j][k] = r.Float64()
+			}
+		}
+	}
+	smooth := func(t float64) float64 { return t * t * (3 - 2*t) }
+	noise := func(x, y int) float64 {
+		n := 0.0
+		for _, o := range octaves {
+			gx, gy := x/o.cell, y/o.cell
+			tx, ty := smooth(float64(x%o.cell)/float64(o.cell)), smooth(float64(y%o.cell)/float64(o.cell))
+			row, next := o.lattice[gy], o.lattice[gy+1]
+			right := (gx + 1) % len(row) // Wrap, so the texture tiles
+			top := row[gx] + (row[right]-row[gx])*tx
+			bottom := next[gx] + (next[right]-next[gx])*tx
+			n += o.weight * (top + (bottom-top)*ty)
+		}
+		return n
+	}
+
+	pix := make([]byte, 4*fogTextureWidth*fogTextureHeight)
+	for y := 0; y < fogTextureHeight; y++ {
+		thin := (1 - float64(y)/(fogTextureHeight-1)) / fogEdge // 1 where thinning starts, 0 at the bottom
+		for x := 0; x < fogTextureWidth; x++ {
+			n := noise(x, y)
+			a := math.Max(0, math.Min(thin+(n-0.5)*0.6, 1))
+			// Pixels are premultiplied by alpha
+			i := 4 * (y*fogTextureWidth + x)
+			pix[i] = byte((40 + 50*n) * a)
+			pix[i+1] = byte((45 + 55*n) * a)
+			pix[i+2] = byte((60 + 60*n) * a)
+			pix[i+3] = byte(255 * a)
+		}
+	}
+	img := ebiten.NewImage(fogTextureWidth, fogTextureHeight)
+	img.WritePixels(pix)
+	return img
+}()
+
+// drawFog covers the top third of the playfield in drifting fog.
+func (g *Game) drawFog(screen *ebiten.Image) {
+	band := float64(screenHeight) / 3
+	offset := 0.0
+	if !g.settings.ReducedMotion {
+		secs := float64(g.world.Time) / float64(g.world.Config.TPS)
+		offset = math.Mod(secs*fogDrift, fogTextureWidth)
+	}
+	for x := -offset; x < float64(screenWidth); x += fogTextureWidth {
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Scale(1, band/fogTextureHeight)
+		op.GeoM.Translate(x, 0)
+		screen.DrawImage(fogTexture, op)
+	}
+}
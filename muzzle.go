@@ -0,0 +1,45 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"example/hello/core"
+)
+
+const (
+	muzzleFlashTicks = 2 // Ticks a muzzle flash shows for
+	muzzleFlashSize  = 6 // Pixels across
+)
+
+// muzzleFlash is a brief bright quad where a bullet left the ship.
+type muzzleFlash struct {
+	x, y float64
+	at   int // World time of the shot
+}
+
+func (g *Game) onFired(e core.Event) {
+	now := g.world.Time
+	live := g.muzzleFlashes[:0]
+	for _, f := range g.muzzleFlashes {
+		if now-f.at < muzzleFlashTicks {
+			live = append(live, f)
+		}
+	}
+	g.muzzleFlashes = append(live, muzzleFlash{x: e.X, y: e.Y, at: now})
+}
+
+func (g *Game) drawMuzzleFlashes(screen *ebiten.Image, ox float64) {
+	if g.settings.ReducedMotion {
+		return
+	}
+	g.shapes.begin(screen, false)
+	for _, f := range g.muzzleFlashes {
+		if g.world.Time-f.at >= muzzleFlashTicks {
+			continue
+		}
+		g.shapes.addRect(g.snap(f.x)+ox-muzzleFlashSize/2, g.snap(f.y)-muzzleFlashSize/2, muzzleFlashSize, muzzleFlashSize, color.NRGBA{255, 255, 200, 255})
+	}
+	g.shapes.flush()
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"math/rand"
+	"time"
+
+	"example/hello/core"
+)
+
+// Option configures a Game built by NewGame.
+type Option func(*Game)
+
+// WithSeed plays every run from the same seed instead of a fresh one.
+func WithSeed(seed int64) Option {
+	return func(g *Game) { g.seed = &seed }
+}
+
+// WithTuning replaces the built-in balance values.
+func WithTuning(t core.Tuning) Option {
+	return func(g *Game) { g.tuning = t }
+}
+
+// WithMode forces a game mode, beating the profile setting.
+func WithMode(m core.Mode) Option {
+	return func(g *Game) { g.modeOverride = &m }
+}
+
+// WithWrap forces wrap-around on or off, beating the profile setting.
+func WithWrap(wrap bool) Option {
+	return func(g *Game) { g.wrapOverride = &wrap }
+}
+
+// WithWorldWidth sets the playfield width; wider than the screen scrolls.
+func WithWorldWidth(width float64) Option {
+	return func(g *Game) { g.worldWidth = width }
+}
+
+// WithAspect sets how the screen fits the window.
+func WithAspect(m aspectMode) Option {
+	return func(g *Game) { g.aspect = m }
+}
+
+// WithLimits caps the live asteroids and bullets.
+func WithLimits(maxAsteroids, maxBullets int) Option {
+	return func(g *Game) { g.maxAsteroids, g.maxBullets = maxAsteroids, maxBullets }
+}
+
+// WithBroadPhase picks how collisions find nearby asteroids.
+func WithBroadPhase(bp core.BroadPhase) Option {
+	return func(g *Game) { g.broadPhase = bp }
+}
+
+// WithSpawnPolicy picks where asteroids spawn outside hardcore.
+func WithSpawnPolicy(sp core.SpawnPolicy) Option {
+	return func(g *Game) { g.spawnPolicy = sp }
+}
+
+// WithContinues sets how many continues each run gets.
+func WithContinues(n int) Option {
+	return func(g *Game) { g.continues = n }
+}
+
+// WithAssets loads assets from dir in preference to the built-in ones.
+func WithAssets(dir string) Option {
+	return func(g *Game) { g.assetsDir = dir }
+}
+
+// WithProfile plays as p from the start, without asking who is playing.
+func WithProfile(p *Profile) Option {
+	return func(g *Game) { g.profile, g.settings = p, p.Settings }
+}
+
+// WithInputs reads the player's input from src instead of the devices.
+func WithInputs(src inputSource) Option {
+	return func(g *Game) { g.inputs = src }
+}
+
+// WithClock times updates by clock instead of the system clock, to tell
+// when they stall.
+func WithClock(clock func() time.Time) Option {
+	return func(g *Game) { g.lag.clock = clock }
+}
+
+// NewGame returns a game with its first run set up, ready to pass to
+// ebiten.RunGame. Anything not set by an option takes its default.
+func NewGame(opts ...Option) *Game {
+	g := &Game{
+		fx:           rand.New(rand.NewSource(time.Now().UnixNano())),
+		maxAsteroids: core.DefaultMaxAsteroids,
+		maxBullets:   core.DefaultMaxBullets,
+		tuning:       core.DefaultTuning,
+		spawnPolicy:  core.SpawnFair,
+		continues:    1,
+	}
+	g.inputs = g.deviceInput()
+	for _, opt := range opts {
+		opt(g)
+	}
+	g.applyOverrides()
+	g.presence = startRichPresence()
+	g.startLoading()
+	g.subscribeDefaults()
+	g.registerRenderPasses()
+	g.reset()
+	return g
+}
+
+// applyOverrides puts the options that beat profile settings into effect.
+func (g *Game) applyOverrides() {
+	if g.wrapOverride != nil {
+		g.settings.Wrap = *g.wrapOverride
+	}
+	if g.modeOverride != nil {
+		g.settings.Mode = *g.modeOverride
+	}
+}
+
+// runSeed is the seed for a new run: the fixed one if there is one,
+// otherwise a fresh one.
+func (g *Game) runSeed() int64 {
+	if g.seed != nil {
+		return *g.seed
+	}
+	return time.Now().UnixNano()
+}
+
+// runSpawnPolicy is the spawn policy for a new run. Hardcore gives no help.
+func (g *Game) runSpawnPolicy() core.SpawnPolicy {
+	if g.settings.Mode == core.ModeHardcore {
+		return core.SpawnUniform
+	}
+	return g.spawnPolicy
+}
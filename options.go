@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+const (
+	minSpeedScale  = 0.5
+	maxSpeedScale  = 2.0
+	speedScaleStep = 0.1
+)
+
+// speedScale reads a speed multiplier setting, clamped to the allowed range.
+// Zero is an unset setting and means normal speed.
+func speedScale(v float64) float64 {
+	if v == 0 {
+		return 1
+	}
+	return math.Max(minSpeedScale, math.Min(v, maxSpeedScale))
+}
+
+// optionItems are the rows of the options screen.
+func (g *Game) optionItems() []menuItem {
+	speed := func(label string, field *float64) menuItem {
+		v := speedScale(*field)
+		return menuItem{
+			label: label, bar: true, fill: (v - minSpeedScale) / (maxSpeedScale - minSpeedScale), value: fmt.Sprintf("%.1fx", v),
+			adjust: func(dir int) { g.setSpeedScale(field, v+float64(dir)*speedScaleStep) },
+		}
+	}
+	toggle := func(label string, on bool, field func(*Settings) *bool) menuItem {
+		return menuItem{label: label, value: onOff(on), adjust: func(int) { g.toggleSetting(field) }}
+	}
+	return []menuItem{
+		speed(tr("options.player_speed"), &g.profile.Settings.PlayerSpeed),
+		speed(tr("options.bullet_speed"), &g.profile.Settings.BulletSpeed),
+		{
+			label: tr("options.game_speed"), value: gameSpeedName(gameSpeed(g.settings.GameSpeed)),
+			adjust: func(dir int) { g.setGameSpeed(nextGameSpeed(gameSpeed(g.settings.GameSpeed), dir)) },
+		},
+		{
+			label: tr("options.movement"), value: movementName(g.settings.Inertia),
+			adjust: func(int) { g.toggleSetting(func(s *Settings) *bool { return &s.Inertia }) },
+		},
+		toggle(tr("options.dodge_points"), !g.settings.NoDodgeScore, func(s *Settings) *bool { return &s.NoDodgeScore }),
+		toggle(tr("options.look_ahead"), !g.settings.NoLookAhead, func(s *Settings) *bool { return &s.NoLookAhead }),
+		toggle(tr("options.pixel_snap"), g.settings.PixelSnap, func(s *Settings) *bool { return &s.PixelSnap }),
+		toggle(tr("options.reduced_motion"), g.settings.ReducedMotion, func(s *Settings) *bool { return &s.ReducedMotion }),
+		{
+			label: tr("options.hud_margin"), value: trf("options.pixels", hudMargin(g.settings.HUDMargin)),
+			adjust: func(dir int) { g.setHUDMargin(hudMargin(g.profile.Settings.HUDMargin) + dir*hudMarginStep) },
+		},
+		{label: tr("options.assists"), activate: func() {
+			g.assistsMenu = menuList{}
+			g.screen = screenAssists
+		}},
+		{label: tr("options.controls"), activate: func() {
+			g.controlsMenu = controlsMenu{}
+			g.screen = screenControls
+		}},
+		{label: tr("options.reset"), activate: func() {
+			g.profile.Settings.PlayerSpeed = 0
+			g.profile.Settings.BulletSpeed = 0
+			g.profile.Settings.HUDMargin = 0
+			g.applyOptions()
+		}},
+	}
+}
+
+func (g *Game) updateOptions() {
+	items := g.optionItems()
+	in := g.readMenuInput(items)
+	if in.cancel {
+		g.screen = screenTitle
+		return
+	}
+	g.optionsMenu.handle(in, items)
+}
+
+// movementName names how the ship moves, snappy or inertial.
+func movementName(inertia bool) string {
+	if inertia {
+		return tr("options.movement_inertial")
+	}
+	return tr("options.movement_snappy")
+}
+
+// setSpeedScale stores a multiplier on the profile, snapped to the slider's
+// steps and kept in range.
+func (g *Game) setSpeedScale(field *float64, v float64) {
+	v = math.Round(v/speedScaleStep) * speedScaleStep
+	*field = speedScale(v)
+	g.applyOptions()
+}
+
+// setHUDMargin stores the HUD margin on the profile, kept in range.
+func (g *Game) setHUDMargin(v int) {
+	g.profile.Settings.HUDMargin = hudMargin(v)
+	g.applyOptions()
+}
+
+// applyOptions copies the profile's options to the session and saves them.
+func (g *Game) applyOptions() {
+	g.settings.PlayerSpeed = g.profile.Settings.PlayerSpeed
+	g.settings.BulletSpeed = g.profile.Settings.BulletSpeed
+	g.settings.HUDMargin = g.profile.Settings.HUDMargin
+	g.saveErr = g.profile.save()
+}
+
+func (g *Game) drawOptions(screen *ebiten.Image) {
+	cx := screenWidth/2 - 150
+	drawCentered(screen, tr("options.title"), 60)
+	y := g.optionsMenu.draw(screen, g.optionItems(), menuLayout{x: cx, y: 120, width: 320, row: 24, valueX: 130})
+	ebitenutil.DebugPrintAt(screen, tr("options.help"), cx, y+20)
+
+	if g.saveErr != nil {
+		g.hud().bottomLeft(screen, trf("save_failed", g.saveErr), 0)
+	}
+}
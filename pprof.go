@@ -0,0 +1,24 @@
+package main
+
+import (
+	"log/slog"
+	"net"
+	"net/http"
+	_ "net/http/pprof"
+)
+
+// startPprof serves the net/http/pprof handlers on addr in the background.
+// A failure to bind is logged and otherwise ignored so the game still runs.
+func startPprof(addr string) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		slog.Error("pprof: can't listen", "addr", addr, "err", err)
+		return
+	}
+	slog.Info("pprof: serving", "url", "http://"+ln.Addr().String()+"/debug/pprof/")
+	go func() {
+		if err := http.Serve(ln, nil); err != nil {
+			slog.Error("pprof: server stopped", "err", err)
+		}
+	}()
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"example/hello/core"
+)
+
+// A practice run plays one stage wave over and over. Dying or pressing
+// Restart starts the wave again at once, and nothing about the run is
+// recorded: no leaderboard, stats, unlocks or ghost.
+
+func (g *Game) openPractice() {
+	g.practiceMenu = menuList{}
+	g.screen = screenPractice
+}
+
+// practiceItems are the waves to practise, one row each.
+func (g *Game) practiceItems() []menuItem {
+	items := make([]menuItem, 0, core.StageWaves)
+	for n := 1; n <= core.StageWaves; n++ {
+		items = append(items, menuItem{
+			label:    trf("practice.wave", n, core.WaveSize(n-1)),
+			activate: func() { g.startPractice(n) },
+		})
+	}
+	return items
+}
+
+// startPractice starts practising wave n, counted from 1.
+func (g *Game) startPractice(n int) {
+	g.daily = ""
+	g.playback = nil
+	g.practice = n
+	g.reset()
+	g.screen = screenPlaying
+}
+
+func (g *Game) updatePractice() {
+	items := g.practiceItems()
+	in := g.readMenuInput(items)
+	if in.cancel {
+		g.screen = screenTitle
+		return
+	}
+	g.practiceMenu.handle(in, items)
+}
+
+func (g *Game) drawPractice(screen *ebiten.Image) {
+	drawCentered(screen, tr("practice.title"), 60)
+	y := g.practiceMenu.draw(screen, g.practiceItems(), menuLayout{x: screenWidth/2 - 120, y: 120, width: 240, row: 24})
+	drawCentered(screen, tr("practice.note"), y+20)
+	drawCentered(screen, tr("practice.help"), y+40)
+}
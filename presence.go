@@ -0,0 +1,29 @@
+package main
+
+import (
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// presence is the activity shown to friends on Discord.
+type presence struct {
+	details string
+	state   string
+	start   time.Time // When the run started; zero in menus
+}
+
+// updatePresence publishes what the player is doing, if they opted in.
+func (g *Game) updatePresence() {
+	if g.presence == nil || g.profile == nil || !g.settings.DiscordPresence {
+		return
+	}
+	p := presence{details: tr("presence.menus")}
+	if g.screen == screenPlaying && !g.world.GameOver {
+		p.details = tr("presence.playing")
+		p.state = trf("presence.score", g.world.Score)
+		elapsed := time.Duration(g.world.Time/ebiten.TPS()) * time.Second
+		p.start = time.Now().Add(-elapsed).Truncate(time.Second)
+	}
+	g.presence.set(p)
+}
@@ -0,0 +1,53 @@
+package main
+
+const pressBufferTicks = 6 // Updates a press is remembered for when it can't act yet
+
+// bufferedAction is a press that still counts if it comes a little early.
+type bufferedAction int
+
+const (
+	actionFire bufferedAction = iota
+	actionPause
+	actionCount
+)
+
+// pressBuffer remembers when each action was last pressed. A press on an
+// update that can't act on it, such as the one that accepts a continue or
+// closes the console, is still there for the next few updates.
+type pressBuffer struct {
+	now     int              // Updates counted so far
+	pressed [actionCount]int // Update of each action's last unused press; 0 for none
+}
+
+// record notes this update's presses.
+func (b *pressBuffer) record(justPressed func(bufferedAction) bool) {
+	b.now++
+	for a := range b.pressed {
+		if justPressed(bufferedAction(a)) {
+			b.pressed[a] = b.now
+		}
+	}
+}
+
+// take reports whether a was pressed in the last pressBufferTicks updates,
+// and uses the press up so it acts only once.
+func (b *pressBuffer) take(a bufferedAction) bool {
+	at := b.pressed[a]
+	if at == 0 || b.now-at >= pressBufferTicks {
+		return false
+	}
+	b.pressed[a] = 0
+	return true
+}
+
+// actionJustPressed reports whether any device pressed an action this
+// update.
+func (g *Game) actionJustPressed(a bufferedAction) bool {
+	switch a {
+	case actionFire:
+		return g.input.justPressed(inputFire)
+	case actionPause:
+		return g.input.justPressed(inputPause)
+	}
+	return false
+}
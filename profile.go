@@ -0,0 +1,263 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	maxProfileName        = 16
+	maxLeaderboardEntries = 10
+)
+
+var profileSchema = schema{
+	version: 5,
+	migrations: map[int]migration{
+		// v1 profiles predate the version field. Fill in stats for files
+		// that only ever recorded a leaderboard.
+		1: func(doc map[string]any) error {
+			if _, ok := doc["stats"]; ok {
+				return nil
+			}
+			best := 0.0
+			if lb, ok := doc["leaderboard"].([]any); ok && len(lb) > 0 {
+				best, _ = lb[0].(float64)
+			}
+			doc["stats"] = map[string]any{"bestScore": best}
+			return nil
+		},
+		// v3 adds the first-run tutorial. Don't show it to anyone who
+		// has already played.
+		2: func(doc map[string]any) error {
+			stats, _ := doc["stats"].(map[string]any)
+			played, _ := stats["gamesPlayed"].(float64)
+			doc["tutorialDone"] = played > 0
+			return nil
+		},
+		// v4 leaderboard entries note the tuning of modified-tuning runs.
+		// Bare scores were all played under the defaults.
+		3: func(doc map[string]any) error {
+			lb, _ := doc["leaderboard"].([]any)
+			for i, score := range lb {
+				lb[i] = map[string]any{"score": score}
+			}
+			return nil
+		},
+		// v5 keeps a leaderboard per mode. Older entries didn't note
+		// their mode; endless is the default, so they go there.
+		4: func(doc map[string]any) error {
+			if lb, ok := doc["leaderboard"].([]any); ok && len(lb) > 0 {
+				doc["leaderboards"] = map[string]any{"endless": lb}
+			}
+			delete(doc, "leaderboard")
+			return nil
+		},
+	},
+}
+
+// appState is machine-wide state that belongs to no profile.
+type appState struct {
+	LastProfile string `json:"lastProfile"`
+}
+
+var appStateSchema = schema{version: 1}
+
+// Profile is everything that belongs to one player on this machine.
+type Profile struct {
+	Name         string                        `json:"name"`
+	Settings     Settings                      `json:"settings"`
+	Stats        Stats                         `json:"stats"`
+	Leaderboards map[string][]leaderboardEntry `json:"leaderboards"`       // Best scores by mode, highest first
+	Daily        map[string]int                `json:"daily,omitempty"`    // Best daily challenge score by date
+	Unlocked     []string                      `json:"unlocked,omitempty"` // Ships unlocked so far, by name
+	Heatmap      *heatmap                      `json:"heatmap,omitempty"`  // Every recorded run's heatmap added up; nil until the first
+
+	TutorialDone bool `json:"tutorialDone"` // Completed or skipped the first-run tutorial
+}
+
+type leaderboardEntry struct {
+	Score     int    `json:"score"`
+	Tuning    string `json:"tuning,omitempty"`    // Checksum of a modified tuning; empty for the defaults
+	KillsOnly bool   `json:"killsOnly,omitempty"` // Played without dodge points
+
+	Mutators []string `json:"mutators,omitempty"` // Names of the mutators played with
+}
+
+type Stats struct {
+	GamesPlayed        int `json:"gamesPlayed"`
+	BestScore          int `json:"bestScore"`
+	TotalScore         int `json:"totalScore"`
+	AsteroidsDestroyed int `json:"asteroidsDestroyed"`
+}
+
+// dataDir is where all persistent game files live.
+func dataDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "."
+	}
+	return filepath.Join(dir, "space-dodger")
+}
+
+func profilesDir() string {
+	return filepath.Join(dataDir(), "profiles")
+}
+
+func profilePath(name string) string {
+	return filepath.Join(profilesDir(), name+".json")
+}
+
+func appStatePath() string {
+	return filepath.Join(dataDir(), "state.json")
+}
+
+// reservedNames are device names Windows won't let a file take, in any
+// case.
+var reservedNames = []string{
+	"CON", "PRN", "AUX", "NUL",
+	"COM1", "COM2", "COM3", "COM4", "COM5", "COM6", "COM7", "COM8", "COM9",
+	"LPT1", "LPT2", "LPT3", "LPT4", "LPT5", "LPT6", "LPT7", "LPT8", "LPT9",
+}
+
+// validProfileName reports whether name is safe to use as a file name.
+func validProfileName(name string) bool {
+	if name == "" || len(name) > maxProfileName {
+		return false
+	}
+	for _, r := range name {
+		if !isProfileNameRune(r) {
+			return false
+		}
+	}
+	for _, r := range reservedNames {
+		if strings.EqualFold(name, r) {
+			return false
+		}
+	}
+	return true
+}
+
+func isProfileNameRune(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_'
+}
+
+// listProfiles returns the names of all saved profiles in sorted order.
+func listProfiles() ([]string, error) {
+	entries, err := os.ReadDir(profilesDir())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), ".json")
+		if ok && !e.IsDir() && validProfileName(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// loadProfile reads a saved profile. A corrupt profile is replaced with a
+// fresh one of the same name.
+func loadProfile(name string) (*Profile, error) {
+	var p Profile
+	err := loadFile(profilePath(name), profileSchema, &p)
+	if errors.Is(err, errCorrupt) {
+		p = Profile{Name: name}
+		return &p, p.save()
+	}
+	if err != nil {
+		return nil, err
+	}
+	p.Name = name
+	return &p, nil
+}
+
+func createProfile(name string) (*Profile, error) {
+	if !validProfileName(name) {
+		return nil, fmt.Errorf("invalid profile name %q", name)
+	}
+	if _, err := os.Stat(profilePath(name)); err == nil {
+		return nil, fmt.Errorf("profile %s already exists", name)
+	}
+	p := &Profile{Name: name}
+	return p, p.save()
+}
+
+func (p *Profile) save() error {
+	return saveFile(profilePath(p.Name), profileSchema, p)
+}
+
+func deleteProfile(name string) error {
+	if readLastProfile() == name {
+		writeLastProfile("")
+	}
+	err := os.Remove(savePath(name))
+	if errors.Is(err, os.ErrNotExist) {
+		err = nil
+	}
+	return errors.Join(os.Remove(profilePath(name)), deleteGhosts(name), err)
+}
+
+func readLastProfile() string {
+	var st appState
+	if err := loadFile(appStatePath(), appStateSchema, &st); err != nil {
+		return ""
+	}
+	return st.LastProfile
+}
+
+func writeLastProfile(name string) error {
+	return saveFile(appStatePath(), appStateSchema, appState{LastProfile: name})
+}
+
+// recordDaily folds a finished daily challenge into the profile's stats and
+// that day's best score. Daily runs stay off the main leaderboard.
+func (p *Profile) recordDaily(date string, score, destroyed int) {
+	p.Stats.GamesPlayed++
+	p.Stats.TotalScore += score
+	p.Stats.AsteroidsDestroyed += destroyed
+	if score > p.Stats.BestScore {
+		p.Stats.BestScore = score
+	}
+	if p.Daily == nil {
+		p.Daily = make(map[string]int)
+	}
+	if best, ok := p.Daily[date]; !ok || score > best {
+		p.Daily[date] = score
+	}
+}
+
+// recordRun folds a finished run into the profile's stats and the mode's
+// leaderboard. entry is the run's score and how it was played, and
+// destroyed is how many asteroids it destroyed.
+func (p *Profile) recordRun(mode string, entry leaderboardEntry, destroyed int) {
+	score := entry.Score
+	p.Stats.GamesPlayed++
+	p.Stats.TotalScore += score
+	p.Stats.AsteroidsDestroyed += destroyed
+	if score > p.Stats.BestScore {
+		p.Stats.BestScore = score
+	}
+
+	lb := p.Leaderboards[mode]
+	i := sort.Search(len(lb), func(i int) bool { return lb[i].Score < score })
+	lb = append(lb, leaderboardEntry{})
+	copy(lb[i+1:], lb[i:])
+	lb[i] = entry
+	if len(lb) > maxLeaderboardEntries {
+		lb = lb[:maxLeaderboardEntries]
+	}
+	if p.Leaderboards == nil {
+		p.Leaderboards = make(map[string][]leaderboardEntry)
+	}
+	p.Leaderboards[mode] = lb
+}
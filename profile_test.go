@@ -0,0 +1,136 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestProfileMigrations(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want Profile
+	}{
+		{
+			name: "v1 leaderboard only",
+			data: `{"name": "ann", "leaderboard": [120, 80]}`,
+			want: Profile{
+				Name:         "ann",
+				Stats:        Stats{BestScore: 120},
+				Leaderboards: map[string][]leaderboardEntry{"endless": {{Score: 120}, {Score: 80}}},
+			},
+		},
+		{
+			name: "v1 empty",
+			data: `{"name": "ann"}`,
+			want: Profile{Name: "ann"},
+		},
+		{
+			name: "v2 played",
+			data: `{"version": 2, "name": "ann", "stats": {"gamesPlayed": 3, "bestScore": 50}, "leaderboard": [50]}`,
+			want: Profile{
+				Name:         "ann",
+				Stats:        Stats{GamesPlayed: 3, BestScore: 50},
+				Leaderboards: map[string][]leaderboardEntry{"endless": {{Score: 50}}},
+				TutorialDone: true,
+			},
+		},
+		{
+			name: "v3 bare scores",
+			data: `{"version": 3, "name": "ann", "leaderboard": [9, 4], "tutorialDone": true}`,
+			want: Profile{
+				Name:         "ann",
+				Leaderboards: map[string][]leaderboardEntry{"endless": {{Score: 9}, {Score: 4}}},
+				TutorialDone: true,
+			},
+		},
+		{
+			name: "v4 entries",
+			data: `{"version": 4, "name": "ann", "leaderboard": [{"score": 9, "tuning": "abc"}]}`,
+			want: Profile{
+				Name:         "ann",
+				Leaderboards: map[string][]leaderboardEntry{"endless": {{Score: 9, Tuning: "abc"}}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Profile
+			loadDoc(t, profileSchema, tt.data, &got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("loaded\n %+v\nwant\n %+v", got, tt.want)
+			}
+			resave(t, profileSchema, &got)
+		})
+	}
+}
+
+func TestRecordRunKeepsLeaderboardSorted(t *testing.T) {
+	var p Profile
+	for _, score := range []int{5, 30, 10, 30, 1} {
+		p.recordRun("endless", leaderboardEntry{Score: score}, 2)
+	}
+	var scores []int
+	for _, e := range p.Leaderboards["endless"] {
+		scores = append(scores, e.Score)
+	}
+	if want := []int{30, 30, 10, 5, 1}; !reflect.DeepEqual(scores, want) {
+		t.Errorf("leaderboard is %v, want %v", scores, want)
+	}
+	if p.Stats != (Stats{GamesPlayed: 5, BestScore: 30, TotalScore: 76, AsteroidsDestroyed: 10}) {
+		t.Errorf("stats are %+v", p.Stats)
+	}
+
+	for i := 0; i < maxLeaderboardEntries; i++ {
+		p.recordRun("endless", leaderboardEntry{Score: 100}, 0)
+	}
+	if n := len(p.Leaderboards["endless"]); n != maxLeaderboardEntries {
+		t.Errorf("leaderboard holds %d entries, want %d", n, maxLeaderboardEntries)
+	}
+}
+
+func TestValidProfileName(t *testing.T) {
+	tests := []struct {
+		name  string
+		valid bool
+	}{
+		{"ann", true},
+		{"Player_2", true},
+		{"", false},
+		{"much-too-long-a-name", false},
+		{"ann smith", false},
+		{"../ann", false},
+		{"con", false}, // Windows device names, in any case
+		{"NUL", false},
+		{"Com1", false},
+		{"lpt9", false},
+		{"console", true},
+		{"com10", true},
+	}
+	for _, tt := range tests {
+		if got := validProfileName(tt.name); got != tt.valid {
+			t.Errorf("validProfileName(%q) = %v, want %v", tt.name, got, tt.valid)
+		}
+	}
+}
+
+func TestNamingAProfileReadsTheInputs(t *testing.T) {
+	g, in := newTestGame(t)
+	g.openProfiles()
+	in.state.keys = []ebiten.Key{ebiten.KeyEnter} // The only item makes a new profile
+	updates(t, g, 1)
+	if !g.profileMenu.naming {
+		t.Fatal("Enter on the new profile item didn't start naming one")
+	}
+	in.state.chars = []rune("annx")
+	updates(t, g, 1)
+	in.state.keys = []ebiten.Key{ebiten.KeyBackspace}
+	updates(t, g, 1)
+	in.state.keys = []ebiten.Key{ebiten.KeyEnter}
+	updates(t, g, 1)
+	if g.screen != screenTitle || g.profile.Name != "ann" {
+		t.Errorf("on screen %v as %q, want the title screen as \"ann\"", g.screen, g.profile.Name)
+	}
+}
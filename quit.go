@@ -0,0 +1,54 @@
+package main
+
+import (
+	"errors"
+	"log/slog"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// errQuit is returned from Update to end the game normally.
+var errQuit = errors.New("quit")
+
+// runActive reports whether closing now would interrupt a run.
+func (g *Game) runActive() bool {
+	return g.screen == screenPlaying && !g.world.GameOver
+}
+
+// updateQuitConfirm runs instead of the rest of Update while the player is
+// asked whether to close the window mid-run.
+func (g *Game) updateQuitConfirm() error {
+	in := g.readMenuInput(nil)
+	switch {
+	case in.confirm:
+		return g.quit()
+	case in.cancel:
+		g.confirmingQuit = false
+	}
+	return nil
+}
+
+// quit saves everything worth keeping, including a run in progress so it
+// can be picked up again with F9, and stops the game.
+func (g *Game) quit() error {
+	if g.continueTimer > 0 {
+		// Walking away from the continue prompt ends the run
+		g.endRun()
+	}
+	if g.profile != nil {
+		var runErr error
+		if g.runActive() {
+			runErr = g.SaveState()
+		}
+		if err := errors.Join(runErr, g.profile.save()); err != nil {
+			slog.Error("saving on quit failed", "err", err)
+		}
+	}
+	return errQuit
+}
+
+func (g *Game) drawQuitConfirm(screen *ebiten.Image) {
+	drawCentered(screen, tr("quit.title"), screenHeight/2-20)
+	drawCentered(screen, tr("quit.saved"), screenHeight/2)
+	drawCentered(screen, tr("quit.help"), screenHeight/2+20)
+}
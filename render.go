@@ -0,0 +1,83 @@
+package main
+
+import (
+	"log/slog"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// renderLayer is a depth in the playing view. Layers draw in order, back to
+// front, so anything lethal is never hidden under something harmless.
+type renderLayer int
+
+const (
+	layerBackground renderLayer = iota // Behind everything; the gradient is drawn before any layer
+	layerPickups                       // Harmless things to collect
+	layerLethal                        // Anything that ends the run on contact
+	layerPlayer                        // The ship and its ghost
+	layerShots                         // The player's bullets and their trails
+	layerEffects                       // Flashes and popups
+	layerHUD                           // Text that stays put while the camera moves
+	layerOverlay                       // Prompts and results over the whole screen
+	layerCount
+)
+
+// renderView is what a pass needs to place things: the camera offset and
+// how far drawing is between the last tick and the next.
+type renderView struct {
+	ox, t float64
+}
+
+// renderPass draws one kind of thing into its layer.
+type renderPass struct {
+	name string // For the render order logged at startup
+	draw func(screen *ebiten.Image, v renderView)
+}
+
+// addRenderPass registers a pass into a layer. Passes in the same layer
+// draw in the order they were added.
+func (g *Game) addRenderPass(l renderLayer, name string, draw func(*ebiten.Image, renderView)) {
+	g.renderPasses[l] = append(g.renderPasses[l], renderPass{name, draw})
+}
+
+// registerRenderPasses sets up the playing view's passes.
+func (g *Game) registerRenderPasses() {
+	g.addRenderPass(layerBackground, "threat lines", func(screen *ebiten.Image, v renderView) {
+		if telegraphs(g.world.Config) {
+			drawThreatLines(screen, &g.shapes, g.world, v.ox, func(prev, cur float64) float64 { return g.lerpPos(prev, cur, v.t) })
+		}
+	})
+	g.addRenderPass(layerLethal, "asteroids", func(screen *ebiten.Image, v renderView) {
+		g.drawAsteroids(screen, g.world, v.ox, v.t)
+	})
+	g.addRenderPass(layerPlayer, "ghost", func(screen *ebiten.Image, v renderView) { g.drawGhost(screen, v.ox) })
+	g.addRenderPass(layerPlayer, "ship", func(screen *ebiten.Image, v renderView) {
+		g.drawPlayer(screen, g.world, v.ox, v.t)
+	})
+	g.addRenderPass(layerPlayer, "aim", func(screen *ebiten.Image, v renderView) { g.drawAim(screen, v.ox, v.t) })
+	g.addRenderPass(layerShots, "trails", func(screen *ebiten.Image, v renderView) {
+		if !g.settings.ReducedMotion {
+			g.trails.draw(screen, &g.shapes, g.world.Config.Width, v.ox, g.snap)
+		}
+	})
+	g.addRenderPass(layerShots, "bullets", func(screen *ebiten.Image, v renderView) {
+		g.drawBullets(screen, g.world, v.ox, v.t)
+	})
+	g.addRenderPass(layerEffects, "mutators", g.drawMutatorEffects)
+	g.addRenderPass(layerEffects, "muzzle flashes", func(screen *ebiten.Image, v renderView) { g.drawMuzzleFlashes(screen, v.ox) })
+	g.addRenderPass(layerEffects, "damage numbers", func(screen *ebiten.Image, v renderView) { g.drawDamageNumbers(screen, v.ox) })
+	g.addRenderPass(layerHUD, "hud", func(screen *ebiten.Image, _ renderView) { g.drawHUD(screen) })
+	g.addRenderPass(layerOverlay, "overlays", func(screen *ebiten.Image, v renderView) { g.drawOverlays(screen, v.ox) })
+	slog.Debug("render order", "passes", g.renderOrder())
+}
+
+// renderOrder lists the passes by name in the order they draw.
+func (g *Game) renderOrder() []string {
+	var names []string
+	for _, passes := range g.renderPasses {
+		for _, p := range passes {
+			names = append(names, p.name)
+		}
+	}
+	return names
+}
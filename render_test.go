@@ -0,0 +1,65 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// recordPasses swaps every pass's drawing for a note of its name, so a
+// frame lists what it would have drawn, in order.
+func recordPasses(g *Game) *[]string {
+	var drawn []string
+	for l := range g.renderPasses {
+		for i := range g.renderPasses[l] {
+			p := &g.renderPasses[l][i]
+			name := p.name
+			p.draw = func(*ebiten.Image, renderView) { drawn = append(drawn, name) }
+		}
+	}
+	return &drawn
+}
+
+func TestRenderOrder(t *testing.T) {
+	g, _ := newTestGame(t)
+	drawn := recordPasses(g)
+	g.drawPlaying(nil)
+	want := []string{
+		"threat lines",
+		"asteroids",
+		"ghost", "ship", "aim",
+		"trails", "bullets",
+		"mutators", "muzzle flashes", "damage numbers",
+		"hud",
+		"overlays",
+	}
+	if !reflect.DeepEqual(*drawn, want) {
+		t.Errorf("a frame drew\n %q\nwant\n %q", *drawn, want)
+	}
+}
+
+func TestRenderLayersBeatRegistrationOrder(t *testing.T) {
+	// A pass added late still draws at its layer's depth
+	g, _ := newTestGame(t)
+	g.addRenderPass(layerPickups, "pickups", nil)
+	g.addRenderPass(layerLethal, "enemy bullets", nil)
+	drawn := recordPasses(g)
+	g.drawPlaying(nil)
+	want := []string{
+		"threat lines",
+		"pickups",
+		"asteroids", "enemy bullets",
+		"ghost", "ship", "aim",
+		"trails", "bullets",
+		"mutators", "muzzle flashes", "damage numbers",
+		"hud",
+		"overlays",
+	}
+	if !reflect.DeepEqual(*drawn, want) {
+		t.Errorf("a frame drew\n %q\nwant\n %q", *drawn, want)
+	}
+	if !reflect.DeepEqual(g.renderOrder(), want) {
+		t.Errorf("renderOrder is %q, want %q", g.renderOrder(), want)
+	}
+}
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+
+	"example/hello/core"
+)
+
+const runFileVersion = 1
+
+// runFile is a finished run bundled up to share: the replay and the
+// result it claims. The hash catches files that were edited or cut short;
+// it is no proof against forgery, which is what replaying the trace is for.
+type runFile struct {
+	Version       int         `json:"version"`
+	TuningVersion int         `json:"tuningVersion"`
+	TuningSum     string      `json:"tuningSum"` // Checksum of the replay's tuning
+	Mode          string      `json:"mode"`
+	Daily         string      `json:"daily,omitempty"` // Date of the daily challenge, if it was one
+	Profile       string      `json:"profile"`
+	Score         int         `json:"score"`
+	Replay        core.Replay `json:"replay"`
+	Hash          string      `json:"hash"` // SHA-256 of the file with this field empty
+}
+
+func runsDir() string {
+	return filepath.Join(dataDir(), "runs")
+}
+
+// sum is the hash the file should carry.
+func (f runFile) sum() string {
+	f.Hash = ""
+	data, err := json.Marshal(f)
+	if err != nil {
+		panic(err) // Plain data always marshals
+	}
+	h := sha256.Sum256(data)
+	return hex.EncodeToString(h[:])
+}
+
+// readRunFile reads a shared run and checks it is whole and from this
+// version of the game. It does not replay it.
+func readRunFile(path string) (*runFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f runFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("not a complete run file: %w", err)
+	}
+	switch {
+	case f.Version != runFileVersion:
+		return nil, fmt.Errorf("run file version %d is not supported; this game reads version %d", f.Version, runFileVersion)
+	case f.Hash != f.sum():
+		return nil, errors.New("run file was changed or damaged after it was exported; its hash doesn't match")
+	case f.TuningVersion != core.TuningVersion:
+		return nil, fmt.Errorf("run is from tuning version %d; this game is version %d", f.TuningVersion, core.TuningVersion)
+	case f.TuningSum != f.Replay.Config.Tuning.Checksum():
+		return nil, fmt.Errorf("tuning checksum mismatch: the file says %s but its tuning sums to %s", f.TuningSum, f.Replay.Config.Tuning.Checksum())
+	}
+	return &f, nil
+}
+
+// verifyRun replays a shared run and reports to out whether it scores what
+// it claims. err is for files that can't be judged at all.
+func verifyRun(path string, out io.Writer) (ok bool, err error) {
+	f, err := readRunFile(path)
+	if err != nil {
+		return false, err
+	}
+	w, err := f.Replay.Play()
+	if err != nil {
+		return false, err
+	}
+	if w.Score != f.Score {
+		fmt.Fprintf(out, "MISMATCH: %s claims %d points but the replay scores %d\n", path, f.Score, w.Score)
+		return false, nil
+	}
+	fmt.Fprintf(out, "OK: %s scores %d in %s mode over %d ticks\n", path, w.Score, f.Mode, w.Time)
+	if !f.Replay.Config.Tuning.Equal(core.DefaultTuning) {
+		fmt.Fprintf(out, "note: played with modified tuning %s\n", f.TuningSum)
+	}
+	return true, nil
+}
+
+// canExportRun reports whether the run just finished has a trace worth
+// sharing.
+func (g *Game) canExportRun() bool {
+	return g.trace != nil && !g.trace.Truncated && !g.cheated && g.playback == nil && g.world.GameOver && g.continueTimer == 0
+}
+
+// exportRun writes the run just finished to the runs folder.
+func (g *Game) exportRun() (string, error) {
+	f := runFile{
+		Version:       runFileVersion,
+		TuningVersion: core.TuningVersion,
+		TuningSum:     g.world.Config.Tuning.Checksum(),
+		Mode:          modeKey(g.world.Config.Mode),
+		Daily:         g.daily,
+		Profile:       g.profile.Name,
+		Score:         g.world.Score,
+		Replay:        *g.trace,
+	}
+	f.Hash = f.sum()
+	data, err := json.Marshal(f)
+	if err != nil {
+		return "", err
+	}
+	name := fmt.Sprintf("%s-%s-%d.json", g.profile.Name, time.Now().Format("20060102-150405"), f.Score)
+	path := filepath.Join(runsDir(), name)
+	return path, writeFileAtomic(path, data)
+}
+
+// runsMenu is the state of the shared runs screen.
+type runsMenu struct {
+	files []string // Newest first
+	list  menuList
+	err   string
+}
+
+func (g *Game) openRuns() {
+	m := runsMenu{}
+	paths, _ := filepath.Glob(filepath.Join(runsDir(), "*.json"))
+	sort.Slice(paths, func(i, j int) bool { return modTime(paths[i]).After(modTime(paths[j])) })
+	m.files = paths
+	g.runsMenu = m
+	g.screen = screenRuns
+}
+
+func modTime(path string) time.Time {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return fi.ModTime()
+}
+
+// runItems are the run files, each watched when picked.
+func (g *Game) runItems() []menuItem {
+	m := &g.runsMenu
+	items := make([]menuItem, len(m.files))
+	for i, path := range m.files {
+		items[i] = menuItem{label: strings.TrimSuffix(filepath.Base(path), ".json"), activate: func() {
+			f, err := readRunFile(path)
+			if err != nil {
+				m.err = err.Error()
+				return
+			}
+			g.startPlayback(f)
+		}}
+	}
+	return items
+}
+
+func (g *Game) updateRuns() {
+	items := g.runItems()
+	in := g.readMenuInput(items)
+	if in.cancel {
+		g.screen = screenTitle
+		return
+	}
+	g.runsMenu.list.handle(in, items)
+}
+
+func (g *Game) drawRuns(screen *ebiten.Image) {
+	drawCentered(screen, tr("runs.title"), 60)
+	drawCentered(screen, trf("runs.folder", runsDir()), 90)
+	cx := 100
+	y := 130
+	if len(g.runsMenu.files) == 0 {
+		ebitenutil.DebugPrintAt(screen, tr("runs.none"), cx, y)
+	}
+	g.runsMenu.list.draw(screen, g.runItems(), menuLayout{x: cx, y: y, width: screenWidth - 2*cx + 20, row: 20, rows: (screenHeight-80-y)/20 + 1})
+	if g.runsMenu.err != "" {
+		drawCentered(screen, g.runsMenu.err, screenHeight-70)
+	}
+	drawCentered(screen, tr("runs.help"), screenHeight-40)
+}
+
+// startPlayback shows a shared run from the start.
+func (g *Game) startPlayback(f *runFile) {
+	g.daily = ""
+	g.practice = 0
+	g.playback = f
+	g.reset()
+	g.screen = screenPlaying
+}
+
+// resetPlayback starts the shared run being watched over.
+func (g *Game) resetPlayback() {
+	r := &g.playback.Replay
+	g.world = core.NewWorld(r.Config, r.Seed)
+	g.resetRun()
+	g.trace = nil
+	g.continuesLeft = 0
+	g.ghost = nil
+}
+
+// updatePlayback runs instead of the rest of Update while a shared run
+// plays. The trace drives the ship; the player can only pause, watch
+// again or leave.
+func (g *Game) updatePlayback() error {
+	switch {
+	case g.input.justPressed(inputMenu):
+		g.playback = nil
+		g.screen = screenTitle
+		return nil
+	case g.input.justPressed(inputPause):
+		g.paused = !g.paused
+	case g.world.GameOver && g.retryPressed():
+		g.reset()
+		return nil
+	}
+	if g.paused {
+		return nil
+	}
+
+	over := g.world.GameOver
+	in, ok := g.playback.Replay.Next(g.world)
+	if !ok {
+		return nil
+	}
+	if over {
+		g.pushEvent(tr("event.continued"))
+	}
+	g.camera.prevX = g.camera.x
+	g.lastTick = time.Now()
+	for _, e := range g.world.Step(in) {
+		g.Publish(e)
+	}
+	g.trails.update(g.world.Bullets)
+	g.updateCamera()
+	return nil
+}
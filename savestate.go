@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"example/hello/core"
+)
+
+// savedGame is the on-disk form of an in-progress run.
+type savedGame struct {
+	TuningVersion int         `json:"tuningVersion"`
+	Variant       string      `json:"variant"`
+	TPS           int         `json:"tps"`
+	World         *core.World `json:"world"`
+	CameraX       float64     `json:"cameraX"`
+	Cheated       bool        `json:"cheated,omitempty"` // The console changed the run
+}
+
+var savedGameSchema = schema{
+	version: 3,
+	migrations: map[int]migration{
+		// v2 bullets can fly in any direction. Older ones all went up.
+		1: func(doc map[string]any) error {
+			bullets, _ := doc["bullets"].([]any)
+			for _, b := range bullets {
+				if b, ok := b.(map[string]any); ok {
+					b["vy"] = -420.0 // The bullet speed when v1 was current
+				}
+			}
+			return nil
+		},
+		// v3 stores the simulation's own state instead of a copy of it.
+		2: func(doc map[string]any) error {
+			world := map[string]any{
+				"rng":  map[string]any{"seed": doc["seed"], "draws": doc["draws"]},
+				"time": doc["gameTime"],
+			}
+			for _, k := range []string{"seed", "draws", "gameTime"} {
+				delete(doc, k)
+			}
+			for _, k := range []string{"player", "bullets", "asteroids", "score", "destroyed",
+				"dodged", "nextSpawn", "weaponLevel", "killsTowardNext", "fireReadyAt"} {
+				world[k] = doc[k]
+				delete(doc, k)
+			}
+			if p, ok := world["player"].(map[string]any); ok {
+				p["width"], p["height"] = 30, 30
+			}
+			bullets, _ := world["bullets"].([]any)
+			for _, b := range bullets {
+				if b, ok := b.(map[string]any); ok {
+					b["active"] = true
+				}
+			}
+			asteroids, _ := world["asteroids"].([]any)
+			for _, a := range asteroids {
+				a, ok := a.(map[string]any)
+				if !ok {
+					continue
+				}
+				a["width"], a["height"], a["active"] = a["w"], a["h"], true
+				delete(a, "w")
+				delete(a, "h")
+				flat, _ := a["shape"].([]any)
+				var shape []any
+				for i := 0; i+1 < len(flat); i += 2 {
+					shape = append(shape, map[string]any{"x": flat[i], "y": flat[i+1]})
+				}
+				a["shape"] = shape
+			}
+			doc["world"] = world
+			return nil
+		},
+	},
+}
+
+func savePath(profile string) string {
+	return filepath.Join(dataDir(), "saves", profile+".json")
+}
+
+// SaveState writes the current run to the profile's save slot.
+func (g *Game) SaveState() error {
+	s := savedGame{
+		TuningVersion: core.TuningVersion,
+		Variant:       g.runVariant(),
+		TPS:           ebiten.TPS(),
+		World:         g.world,
+		CameraX:       g.camera.x,
+		Cheated:       g.cheated,
+	}
+	return saveFile(savePath(g.profile.Name), savedGameSchema, s)
+}
+
+// LoadState replaces the current run with the profile's saved one. On any
+// error, including a save from another version or playfield, the current
+// run is left untouched.
+func (g *Game) LoadState() error {
+	var s savedGame
+	if err := loadFile(savePath(g.profile.Name), savedGameSchema, &s); err != nil {
+		return err
+	}
+	if s.TuningVersion != core.TuningVersion {
+		return fmt.Errorf("save is from tuning version %d, not %d", s.TuningVersion, core.TuningVersion)
+	}
+	if s.Variant != g.runVariant() {
+		return fmt.Errorf("save is for the %s playfield, not %s", s.Variant, g.runVariant())
+	}
+	if s.TPS != ebiten.TPS() {
+		return fmt.Errorf("save was made at %d ticks per second, not %d", s.TPS, ebiten.TPS())
+	}
+	if s.World == nil || s.World.WeaponLevel < 0 || s.World.WeaponLevel >= core.WeaponLevels() {
+		return fmt.Errorf("save has no valid run")
+	}
+
+	g.reset()
+	cfg := g.world.Config
+	g.restored = true
+	g.trace = nil // The inputs before the save are gone
+	g.cheated = s.Cheated
+	g.world = s.World
+	// Caps come from this session's flags, not the saving one's
+	g.world.Config = cfg
+	g.world.HoldSpawns = false
+	g.world.Invulnerable = false
+	g.tutorial = tutorial{}
+	g.camera = Camera{x: s.CameraX, prevX: s.CameraX, follow: s.CameraX}
+	return nil
+}
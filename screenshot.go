@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func screenshotsDir() string {
+	return filepath.Join(dataDir(), "screenshots")
+}
+
+// saveScreenshot writes img as a PNG named for the current time.
+func saveScreenshot(img *ebiten.Image) (string, error) {
+	b := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	img.ReadPixels(rgba.Pix)
+
+	if err := os.MkdirAll(screenshotsDir(), 0o755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(screenshotsDir(), fmt.Sprintf("space-dodger-%s.png", time.Now().Format("20060102-150405.000")))
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	if err := png.Encode(f, rgba); err != nil {
+		f.Close()
+		return "", err
+	}
+	return path, f.Close()
+}
+
+// takeScreenshot saves the frame just drawn if one was asked for. On the
+// results screen it saves the frame the run ended on instead.
+func (g *Game) takeScreenshot(screen *ebiten.Image) {
+	if !g.screenshotPending {
+		return
+	}
+	g.screenshotPending = false
+	img := screen
+	if g.screen == screenPlaying && g.world.Killer != nil && g.deathShot.img != nil {
+		img = ebiten.NewImage(screen.Bounds().Dx(), screen.Bounds().Dy())
+		g.drawDeathShot(img)
+	}
+	path, err := saveScreenshot(img)
+	if err != nil {
+		slog.Error("screenshot failed", "err", err)
+		g.pushToast(tr("event.screenshot_failed"))
+		return
+	}
+	slog.Info("screenshot saved", "path", path)
+	g.pushToast(tr("event.screenshot_saved"))
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+
+	"example/hello/core"
+)
+
+const shipPreviewScale = 2 // The selection screen draws ships this much larger than in play
+
+// shipIndex is the chosen ship, falling back to the default if the
+// profile's choice is out of range or still locked.
+func (g *Game) shipIndex() int {
+	if g.settings.Ship < 0 || g.settings.Ship >= len(core.Ships) || !g.profile.shipUnlocked(core.Ships[g.settings.Ship].Name) {
+		return defaultShipIndex()
+	}
+	return g.settings.Ship
+}
+
+func defaultShipIndex() int {
+	for i, s := range core.Ships {
+		if s == core.DefaultShip {
+			return i
+		}
+	}
+	return 0
+}
+
+func (g *Game) openShips() {
+	g.shipMenu = menuList{cursor: g.shipIndex(), across: true}
+	g.shipLocked = false
+	g.screen = screenShips
+}
+
+// shipItems are the ships on the selection screen, left to right.
+func (g *Game) shipItems() []menuItem {
+	items := make([]menuItem, len(core.Ships))
+	for i, s := range core.Ships {
+		items[i].activate = func() {
+			if !g.profile.shipUnlocked(s.Name) {
+				g.shipLocked = true
+				return
+			}
+			g.profile.Settings.Ship = i
+			g.settings.Ship = i
+			g.saveErr = g.profile.save()
+			g.reset()
+			g.screen = screenTitle
+		}
+	}
+	return items
+}
+
+func (g *Game) updateShips() {
+	items := g.shipItems()
+	in := g.readMenuInput(items)
+	if in.cancel {
+		g.screen = screenTitle
+		return
+	}
+	cursor := g.shipMenu.cursor
+	g.shipMenu.handle(in, items)
+	if g.shipMenu.cursor != cursor {
+		g.shipLocked = false
+	}
+}
+
+func (g *Game) drawShips(screen *ebiten.Image) {
+	drawCentered(screen, tr("ships.title"), 60)
+	slot := screenWidth / len(core.Ships)
+	for i, s := range core.Ships {
+		cx := slot*i + slot/2
+		r := image.Rect(cx-slot/2+10, 100, cx+slot/2-10, 350)
+		g.shipMenu.place(i, len(core.Ships), r)
+		if i == g.shipMenu.cursor {
+			ebitenutil.DrawRect(screen, float64(r.Min.X), float64(r.Min.Y), float64(r.Dx()), float64(r.Dy()), color.RGBA{0, 80, 0, 255})
+		}
+		locked := !g.profile.shipUnlocked(s.Name)
+		g.drawShipPreview(screen, s, float64(cx), 190, locked)
+
+		name := tr("ship." + s.Name)
+		if locked {
+			name = trf("ships.locked", name)
+		}
+		ebitenutil.DebugPrintAt(screen, name, cx-textWidth(name)/2, 230)
+		x := cx - 70
+		drawStatBar(screen, tr("ships.speed"), x, 260, shipStat(s, func(s core.Ship) float64 { return s.Speed }))
+		drawStatBar(screen, tr("ships.fire_rate"), x, 285, shipStat(s, func(s core.Ship) float64 { return s.FireRate }))
+		drawStatBar(screen, tr("ships.size"), x, 310, shipStat(s, func(s core.Ship) float64 { return s.Size }))
+	}
+	cur := core.Ships[g.shipMenu.cursor]
+	switch {
+	case g.shipLocked:
+		drawCentered(screen, trf("ships.requires", shipUnlocks[cur.Name].hint()), 370)
+	case !g.profile.shipUnlocked(cur.Name):
+		drawCentered(screen, shipUnlocks[cur.Name].hint(), 370)
+	default:
+		drawCentered(screen, tr("ship."+cur.Name+".about"), 370)
+	}
+	drawCentered(screen, tr("ships.help"), screenHeight-40)
+}
+
+// drawShipPreview draws a ship the way it looks in play, enlarged and
+// centered on (cx, bottom) along its bottom edge. Locked ships are grey.
+func (g *Game) drawShipPreview(screen *ebiten.Image, s core.Ship, cx, bottom float64, locked bool) {
+	size := s.Size * shipPreviewScale
+	cockpit := shipCockpit * shipPreviewScale
+	x, y := cx-size/2, bottom-size
+	if ship := g.sprites.ship; ship != nil {
+		b := ship.Bounds()
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Scale(size/float64(b.Dx()), (size+float64(cockpit))/float64(b.Dy()))
+		op.GeoM.Translate(x, y-float64(cockpit))
+		if locked {
+			op.ColorScale.Scale(0.3, 0.3, 0.3, 1)
+		}
+		screen.DrawImage(ship, op)
+		return
+	}
+	hull, cockpitColor := color.RGBA{0, 255, 0, 255}, color.RGBA{255, 255, 0, 255}
+	if locked {
+		hull, cockpitColor = color.RGBA{70, 70, 70, 255}, color.RGBA{100, 100, 100, 255}
+	}
+	ebitenutil.DrawRect(screen, x, y, size, size, hull)
+	ebitenutil.DrawRect(screen, cx-2*shipPreviewScale, y-float64(cockpit), 4*shipPreviewScale, float64(cockpit), cockpitColor)
+}
+
+// shipStat places a ship's stat between the lowest and highest of any
+// ship, from 0 to 1, with a sliver left so the lowest still shows.
+func shipStat(s core.Ship, stat func(core.Ship) float64) float64 {
+	lo, hi := stat(core.Ships[0]), stat(core.Ships[0])
+	for _, o := range core.Ships[1:] {
+		lo, hi = min(lo, stat(o)), max(hi, stat(o))
+	}
+	if hi == lo {
+		return 1
+	}
+	return 0.15 + 0.85*(stat(s)-lo)/(hi-lo)
+}
+
+// drawStatBar draws a labeled bar filled to v, from 0 to 1.
+func drawStatBar(screen *ebiten.Image, label string, x, y int, v float64) {
+	const width, height = 80, 8
+	ebitenutil.DebugPrintAt(screen, label, x, y-4)
+	ebitenutil.DrawRect(screen, float64(x+60), float64(y), width, height, color.RGBA{60, 60, 60, 255})
+	ebitenutil.DrawRect(screen, float64(x+60), float64(y), v*width, height, color.RGBA{0, 200, 0, 255})
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"runtime"
+	"testing"
+
+	"example/hello/core"
+)
+
+const (
+	soakTicks      = 1_000_000 // Over four hours at 60 TPS, well past core.MaxReplayInputs
+	soakWarmup     = 60 * 60   // Ticks run before the heap is measured, so slices reach their working size
+	soakHeapBudget = 4 << 20   // Bytes the live heap may grow by after warmup
+)
+
+// TestSoak flies the hard bot through a long invulnerable run with every
+// effect system live and the replay trace recording, and checks the live
+// heap stays within soakHeapBudget of where it was after warmup.
+func TestSoak(t *testing.T) {
+	if testing.Short() {
+		t.Skip("the soak takes a while")
+	}
+	g, _ := newTestGame(t)
+	if g.trace == nil {
+		t.Fatal("the run isn't recording a trace")
+	}
+	g.world.Invulnerable = true
+	g.debug = true // Damage numbers and the DPS meter only run with the overlay up
+	skill := core.BotSkills[len(core.BotSkills)-1]
+
+	var base uint64
+	for i := 0; i < soakTicks; i++ {
+		if i == soakWarmup {
+			base = liveHeap()
+		}
+		g.tick(core.BotInput(g.world, skill))
+	}
+	end := liveHeap()
+	growth := int64(end) - int64(base)
+	t.Logf("%d ticks, score %d, %d asteroids and %d bullets live", soakTicks, g.world.Score, len(g.world.Asteroids), len(g.world.Bullets))
+	t.Logf("heap after warmup %d KiB, at end %d KiB, growth %d KiB", base>>10, end>>10, growth>>10)
+	if growth > soakHeapBudget {
+		t.Errorf("the live heap grew by %d KiB, over the %d KiB budget", growth>>10, soakHeapBudget>>10)
+	}
+	if !g.trace.Truncated || len(g.trace.Inputs) != 0 {
+		t.Errorf("after %d ticks the trace holds %d inputs and is truncated: %v", soakTicks, len(g.trace.Inputs), g.trace.Truncated)
+	}
+}
+
+// liveHeap collects garbage and returns the bytes still in use.
+func liveHeap() uint64 {
+	runtime.GC()
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return m.HeapAlloc
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+)
+
+// errCorrupt is returned by loadFile when a file could not be read back.
+// The file has already been moved aside, so callers should fall back to
+// defaults.
+var errCorrupt = errors.New("corrupt save file")
+
+// migration upgrades a decoded document by exactly one version.
+type migration func(doc map[string]any) error
+
+// schema describes one kind of save file. Documents are JSON objects with a
+// top-level "version" field; files without one are version 1.
+type schema struct {
+	version    int
+	migrations map[int]migration // Keyed by the version they upgrade from
+}
+
+// saveFile writes v as a versioned document, atomically replacing path.
+func saveFile(path string, s schema, v any) error {
+	if err := writeVersioned(path, s, v); err != nil {
+		slog.Error("save failed", "path", path, "err", err)
+		return err
+	}
+	slog.Info("saved", "path", path)
+	return nil
+}
+
+func writeVersioned(path string, s schema, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("%s: save data must be a JSON object: %w", path, err)
+	}
+	doc["version"] = s.version
+	data, err = json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data)
+}
+
+// loadFile reads a document written by saveFile into v, migrating it to the
+// current schema version first. A missing file reports os.ErrNotExist.
+func loadFile(path string, s schema, v any) error {
+	err := readVersioned(path, s, v)
+	switch {
+	case err == nil:
+		slog.Info("loaded", "path", path)
+	case errors.Is(err, os.ErrNotExist):
+		slog.Debug("nothing to load", "path", path)
+	case errors.Is(err, errCorrupt):
+		slog.Warn("load failed; file moved aside", "path", path, "err", err)
+	default:
+		slog.Error("load failed", "path", path, "err", err)
+	}
+	return err
+}
+
+func readVersioned(path string, s schema, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil || doc == nil {
+		return quarantine(path, err)
+	}
+
+	version := 1
+	if raw, ok := doc["version"]; ok {
+		f, ok := raw.(float64)
+		if !ok || f < 1 || f != float64(int(f)) {
+			return quarantine(path, fmt.Errorf("bad version %v", raw))
+		}
+		version = int(f)
+	}
+	if version > s.version {
+		return fmt.Errorf("%s: version %d is newer than supported version %d", path, version, s.version)
+	}
+	if version < s.version {
+		slog.Info("migrating", "path", path, "from", version, "to", s.version)
+	}
+	for ; version < s.version; version++ {
+		m, ok := s.migrations[version]
+		if !ok {
+			return fmt.Errorf("%s: no migration from version %d", path, version)
+		}
+		if err := m(doc); err != nil {
+			return quarantine(path, fmt.Errorf("migrating from version %d: %w", version, err))
+		}
+	}
+
+	delete(doc, "version")
+	data, err = json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return quarantine(path, err)
+	}
+	return nil
+}
+
+// quarantine moves an unreadable file to path.corrupt so the next save
+// doesn't destroy it.
+func quarantine(path string, cause error) error {
+	if err := os.Rename(path, path+".corrupt"); err != nil {
+		return fmt.Errorf("%s: %w (%v), and could not move it aside: %v", path, errCorrupt, cause, err)
+	}
+	return fmt.Errorf("%s: %w (%v)", path, errCorrupt, cause)
+}
+
+// writeFileAtomic writes data to a temporary file, syncs it, and renames it
+// over path, so a crash leaves either the old or the new file intact.
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	// Make the rename itself durable where the platform allows it
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+type testDoc struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// testSchema is at version 3: v2 renamed "n" to "count", and v3 doubled it.
+var testSchema = schema{
+	version: 3,
+	migrations: map[int]migration{
+		1: func(doc map[string]any) error {
+			doc["count"] = doc["n"]
+			delete(doc, "n")
+			return nil
+		},
+		2: func(doc map[string]any) error {
+			n, ok := doc["count"].(float64)
+			if !ok {
+				return errors.New("count is not a number")
+			}
+			doc["count"] = n * 2
+			return nil
+		},
+	},
+}
+
+func writeTestFile(t *testing.T, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "doc.json")
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestSaveFileRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "doc.json")
+	want := testDoc{Name: "ann", Count: 7}
+	if err := saveFile(path, testSchema, want); err != nil {
+		t.Fatal(err)
+	}
+	var got testDoc
+	if err := loadFile(path, testSchema, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("loaded %+v, want %+v", got, want)
+	}
+
+	// Nothing but the file itself is left behind
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("directory holds %d entries after saving, want 1", len(entries))
+	}
+}
+
+func TestSaveFileReplaces(t *testing.T) {
+	path := writeTestFile(t, `{"version": 3, "name": "old", "count": 1}`)
+	if err := saveFile(path, testSchema, testDoc{Name: "new", Count: 2}); err != nil {
+		t.Fatal(err)
+	}
+	var got testDoc
+	if err := loadFile(path, testSchema, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "new" {
+		t.Errorf("loaded %+v after overwriting, want the new document", got)
+	}
+}
+
+func TestLoadFileMigrates(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want testDoc
+	}{
+		{"unversioned is v1", `{"name": "a", "n": 5}`, testDoc{"a", 10}},
+		{"v1", `{"version": 1, "name": "a", "n": 5}`, testDoc{"a", 10}},
+		{"v2", `{"version": 2, "name": "a", "count": 5}`, testDoc{"a", 10}},
+		{"current", `{"version": 3, "name": "a", "count": 5}`, testDoc{"a", 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeTestFile(t, tt.data)
+			var got testDoc
+			if err := loadFile(path, testSchema, &got); err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("loaded %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadFileCorrupt(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"truncated", `{"version": 3, "name": "a", "cou`},
+		{"empty", ``},
+		{"null", `null`},
+		{"not an object", `[1, 2, 3]`},
+		{"bad version", `{"version": "three", "name": "a"}`},
+		{"fractional version", `{"version": 2.5, "name": "a"}`},
+		{"zero version", `{"version": 0, "name": "a"}`},
+		{"failed migration", `{"version": 2, "name": "a", "count": "lots"}`},
+		{"wrong field type", `{"version": 3, "name": 12}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeTestFile(t, tt.data)
+			var got testDoc
+			err := loadFile(path, testSchema, &got)
+			if !errors.Is(err, errCorrupt) {
+				t.Fatalf("loadFile = %v, want errCorrupt", err)
+			}
+			if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+				t.Errorf("corrupt file was left in place")
+			}
+			moved, err := os.ReadFile(path + ".corrupt")
+			if err != nil {
+				t.Fatalf("corrupt file was not moved aside: %v", err)
+			}
+			if string(moved) != tt.data {
+				t.Errorf("moved-aside file holds %q, want the original %q", moved, tt.data)
+			}
+		})
+	}
+}
+
+func TestLoadFileNotQuarantined(t *testing.T) {
+	tests := []struct {
+		name string
+		s    schema
+		data string
+	}{
+		// A newer game wrote it; it is fine, just not for us
+		{"newer version", testSchema, `{"version": 4, "name": "a", "count": 1}`},
+		{"missing migration", schema{version: 2}, `{"version": 1, "name": "a"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeTestFile(t, tt.data)
+			var got testDoc
+			err := loadFile(path, tt.s, &got)
+			if err == nil || errors.Is(err, errCorrupt) {
+				t.Fatalf("loadFile = %v, want an error other than errCorrupt", err)
+			}
+			if data, err := os.ReadFile(path); err != nil || string(data) != tt.data {
+				t.Errorf("file was changed or moved: %q, %v", data, err)
+			}
+		})
+	}
+}
+
+func TestLoadFileMissing(t *testing.T) {
+	var got testDoc
+	err := loadFile(filepath.Join(t.TempDir(), "none.json"), testSchema, &got)
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("loadFile = %v, want os.ErrNotExist", err)
+	}
+}
+
+func TestWriteVersionedNeedsObject(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "doc.json")
+	if err := writeVersioned(path, testSchema, []int{1, 2}); err == nil {
+		t.Error("writeVersioned accepted a JSON array")
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Error("a failed save left a file behind")
+	}
+}
+
+func TestSchemasHaveEveryMigration(t *testing.T) {
+	schemas := map[string]schema{
+		"profile":   profileSchema,
+		"appState":  appStateSchema,
+		"ghost":     ghostSchema,
+		"savedGame": savedGameSchema,
+	}
+	for name, s := range schemas {
+		for v := 1; v < s.version; v++ {
+			if s.migrations[v] == nil {
+				t.Errorf("%s schema has no migration from version %d", name, v)
+			}
+		}
+		for v := range s.migrations {
+			if v < 1 || v >= s.version {
+				t.Errorf("%s schema has a migration from version %d, outside 1 to %d", name, v, s.version-1)
+			}
+		}
+	}
+}
+
+// loadDoc writes data to a file and loads it with s.
+func loadDoc(t *testing.T, s schema, data string, v any) {
+	t.Helper()
+	if err := loadFile(writeTestFile(t, data), s, v); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// resave saves v and loads it back into a fresh value of the same type.
+func resave[T any](t *testing.T, s schema, v *T) *T {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "resaved.json")
+	if err := saveFile(path, s, v); err != nil {
+		t.Fatal(err)
+	}
+	var out T
+	if err := loadFile(path, s, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(v, &out) {
+		t.Errorf("saving and loading changed the document:\n got %+v\nwant %+v", out, *v)
+	}
+	return &out
+}
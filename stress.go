@@ -0,0 +1,113 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"slices"
+	"time"
+
+	"example/hello/core"
+)
+
+const stressTicks = 1800 // Ticks timed by a -stress run
+
+// stressTest keeps the field loaded with asteroids and bullets and times
+// every Update and Draw, for -stress.
+type stressTest struct {
+	n         int
+	rng       *rand.Rand // Placement only; the world's own RNG is left alone
+	times     []time.Duration
+	drawTimes []time.Duration
+	draws     drawCount // The last frame's
+	out       io.Writer
+}
+
+// startStress begins a stress run with n asteroids and n bullets.
+func (g *Game) startStress(n int, out io.Writer) {
+	g.stress = &stressTest{n: n, rng: rand.New(rand.NewSource(1)), out: out}
+	g.world.Invulnerable = true
+	g.screen = screenPlaying
+}
+
+// updateStress tops the field back up to the target load, then runs and
+// times the normal update.
+func (g *Game) updateStress() error {
+	s := g.stress
+	s.fill(g.world)
+	start := time.Now()
+	err := g.update()
+	s.times = append(s.times, time.Since(start))
+	if err != nil {
+		return err
+	}
+	if len(s.times) == stressTicks {
+		s.report()
+		return errQuit
+	}
+	return nil
+}
+
+// fill adds asteroids and bullets at random places in the playfield until
+// there are n of each.
+func (s *stressTest) fill(w *core.World) {
+	t := &w.Config.Tuning
+	for len(w.Asteroids) < s.n {
+		size := t.AsteroidSizes.Pick(s.rng)
+		w.AddAsteroid(s.rng.Float64()*(w.Config.Width-size), size, t.AsteroidSpeed)
+		a := &w.Asteroids[len(w.Asteroids)-1]
+		a.Y = s.rng.Float64() * (w.Config.Height - size)
+		a.PrevY = a.Y
+	}
+	for len(w.Bullets) < s.n {
+		x, y := s.rng.Float64()*w.Config.Width, s.rng.Float64()*w.Config.Height
+		w.AddBullet(x, y, 0, -t.BulletSpeed)
+	}
+}
+
+// recordDraw notes how long a frame took to draw and what it drew.
+func (s *stressTest) recordDraw(d time.Duration) {
+	s.drawTimes = append(s.drawTimes, d)
+	s.draws = frameDraws
+}
+
+// report prints the update and draw time statistics.
+func (s *stressTest) report() {
+	fmt.Fprintf(s.out, "stress: %d asteroids, %d bullets, %d ticks\n", s.n, s.n, len(s.times))
+	fmt.Fprintf(s.out, "update %s\n", timeStats(s.times))
+	if len(s.drawTimes) > 0 {
+		fmt.Fprintf(s.out, "draw   %s over %d frames, %d draw calls for %d shapes\n", timeStats(s.drawTimes), len(s.drawTimes), s.draws.calls, s.draws.shapes)
+	}
+}
+
+// timeStats summarizes durations as their min, average, max and 99th
+// percentile.
+func timeStats(times []time.Duration) string {
+	sorted := slices.Clone(times)
+	slices.Sort(sorted)
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+	}
+	p99 := sorted[(len(sorted)*99+99)/100-1]
+	return fmt.Sprintf("min %v  avg %v  max %v  p99 %v", sorted[0], total/time.Duration(len(sorted)), sorted[len(sorted)-1], p99)
+}
+
+// usageHiding prints the usual command line help, leaving out the named
+// developer flags.
+func usageHiding(hidden ...string) func() {
+	return func() {
+		out := flag.CommandLine.Output()
+		fmt.Fprintf(out, "Usage of %s:\n", os.Args[0])
+		visible := flag.NewFlagSet("", flag.ContinueOnError)
+		visible.SetOutput(out)
+		flag.VisitAll(func(f *flag.Flag) {
+			if !slices.Contains(hidden, f.Name) {
+				visible.Var(f.Value, f.Name, f.Usage)
+			}
+		})
+		visible.PrintDefaults()
+	}
+}
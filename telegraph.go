@@ -0,0 +1,72 @@
+package main
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"example/hello/core"
+)
+
+const (
+	threatSpeedScale = 0.75 // Asteroids faster than this multiple of Tuning.AsteroidSpeed get a path line
+	threatLookahead  = 1.0  // Seconds ahead a path is shown
+	threatLineAlpha  = 0.35 // Opacity of a path a full lookahead away; it fades as the asteroid closes in
+	threatDash       = 4    // Pixel length of each dash, and of the gap after it
+)
+
+// telegraphs reports whether fast asteroids show their path. It is an
+// assist for the gentler modes; Hardcore goes without.
+func telegraphs(cfg core.Config) bool {
+	return cfg.Mode != core.ModeHardcore
+}
+
+// drawThreatLines paints a dotted line from each fast asteroid to where it
+// will cross the ship's rows, if it gets there within threatLookahead.
+// Paths are straight along the asteroid's velocity, diagonal or not. Every
+// dash of every line goes into one batch, so a screen full of fast rocks
+// costs no more draw calls than one.
+func drawThreatLines(screen *ebiten.Image, b *shapeBatch, w *core.World, ox float64, lerp func(prev, cur float64) float64) {
+	b.begin(screen, true)
+	p := w.Player
+	top := lerp(p.PrevY, p.Y)
+	bottom := top + p.Height
+	fast := w.Config.Tuning.AsteroidSpeed * threatSpeedScale
+	for i := range w.Asteroids {
+		a := &w.Asteroids[i]
+		if !a.Active || math.Hypot(a.VX, a.Speed) <= fast {
+			continue
+		}
+		x, y := lerp(a.PrevX, a.X)+a.Width/2, lerp(a.PrevY, a.Y)+a.Height/2
+		var t float64
+		switch {
+		case a.Speed > 0 && y < top:
+			t = (top - y) / a.Speed
+		case a.Speed < 0 && y > bottom:
+			t = (bottom - y) / a.Speed
+		default:
+			continue // Level with the ship already, or never crossing its rows
+		}
+		if t > threatLookahead {
+			continue
+		}
+		addDashes(b, x+ox, y, x+a.VX*t+ox, y+a.Speed*t, threatLineAlpha*t/threatLookahead)
+	}
+	b.flush()
+}
+
+// addDashes adds a dotted line from (x0, y0) to (x1, y1) to the batch.
+func addDashes(b *shapeBatch, x0, y0, x1, y1, alpha float64) {
+	length := math.Hypot(x1-x0, y1-y0)
+	if length == 0 {
+		return
+	}
+	dx, dy := (x1-x0)/length, (y1-y0)/length
+	nx, ny := -dy/2, dx/2 // Half a pixel either side of the line
+	for d := 0.0; d < length; d += 2 * threatDash {
+		e := math.Min(d+threatDash, length)
+		ax, ay, bx, by := x0+dx*d, y0+dy*d, x0+dx*e, y0+dy*e
+		b.addQuad([4][2]float64{{ax + nx, ay + ny}, {ax - nx, ay - ny}, {bx + nx, by + ny}, {bx - nx, by - ny}}, color.NRGBA{255, 102, 77, uint8(255 * alpha)})
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+const (
+	tickerSize     = 4   // Events shown at once
+	tickerLifetime = 3.0 // Seconds an event stays visible
+	tickerFade     = 1.0 // Seconds over which an event fades out at the end
+	tickerMaxLen   = 32  // Longer messages are truncated
+)
+
+type tickerEntry struct {
+	text string
+	at   int // gameTime the event happened
+}
+
+// eventTicker is a ring buffer of recent notable events.
+type eventTicker struct {
+	entries [tickerSize]tickerEntry
+	next    int
+}
+
+// tickerScratch is reused to render each entry so it can be faded.
+var tickerScratch = ebiten.NewImage(tickerMaxLen*6+6, 16)
+
+func (g *Game) pushEvent(text string) {
+	if r := []rune(text); len(r) > tickerMaxLen {
+		text = string(r[:tickerMaxLen-3]) + "..."
+	}
+	t := &g.ticker
+	t.entries[t.next] = tickerEntry{text: text, at: g.world.Time}
+	t.next = (t.next + 1) % tickerSize
+}
+
+// drawTicker lists live events under the HUD, newest on top.
+func (g *Game) drawTicker(screen *ebiten.Image, x, y int) {
+	t := &g.ticker
+	for i := 1; i <= tickerSize; i++ {
+		e := t.entries[(t.next-i+tickerSize)%tickerSize]
+		if e.text == "" {
+			continue
+		}
+		age := float64(g.world.Time-e.at) * g.tickSeconds()
+		if age >= tickerLifetime {
+			continue
+		}
+		alpha := min(1, (tickerLifetime-age)/tickerFade)
+
+		tickerScratch.Clear()
+		ebitenutil.DebugPrint(tickerScratch, e.text)
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(float64(x), float64(y))
+		op.ColorScale.ScaleAlpha(float32(alpha))
+		screen.DrawImage(tickerScratch, op)
+		y += 16
+	}
+}
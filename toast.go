@@ -0,0 +1,74 @@
+package main
+
+import (
+	"image/color"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+const (
+	toastMax      = 4                      // Shown at once; pushing more drops the oldest
+	toastLifetime = 3 * time.Second        // How long a toast stays up
+	toastFade     = 500 * time.Millisecond // Over which it fades out at the end
+	toastMaxLen   = 48                     // Longer messages are truncated
+	toastPadding  = 4                      // Pixels between a toast's text and the edge of its box
+)
+
+// toast is a brief message about the game rather than the run: a
+// screenshot saved, a ship unlocked. Toasts run on the clock, not on game
+// time, so they show and fade on menus and while paused.
+type toast struct {
+	text     string
+	at       time.Time // When it was pushed
+	lifetime time.Duration
+}
+
+// toastScratch is reused to render each toast so it can be faded.
+var toastScratch = ebiten.NewImage(toastMaxLen*6+6, 16)
+
+// pushToast shows text in a toast, under any already showing.
+func (g *Game) pushToast(text string) {
+	if r := []rune(text); len(r) > toastMaxLen {
+		text = string(r[:toastMaxLen-3]) + "..."
+	}
+	now := time.Now()
+	live := g.toasts[:0]
+	for _, t := range g.toasts {
+		if now.Sub(t.at) < t.lifetime {
+			live = append(live, t)
+		}
+	}
+	if len(live) == toastMax {
+		live = append(live[:0], live[1:]...)
+	}
+	g.toasts = append(live, toast{text: text, at: now, lifetime: toastLifetime})
+}
+
+// drawToasts stacks the live toasts in the bottom right corner, newest at
+// the bottom pushing older ones up.
+func (g *Game) drawToasts(screen *ebiten.Image) {
+	hud := g.hud()
+	now := time.Now()
+	line := 0
+	for i := len(g.toasts) - 1; i >= 0; i-- {
+		t := g.toasts[i]
+		left := t.lifetime - now.Sub(t.at)
+		if left <= 0 {
+			continue
+		}
+		alpha := min(1, float64(left)/float64(toastFade))
+		w := textWidth(t.text)
+		x, y := hud.right(w), hud.bottom(line)-line*toastPadding*2
+		ebitenutil.DrawRect(screen, float64(x-toastPadding), float64(y-toastPadding/2), float64(w+2*toastPadding), hudLineHeight+toastPadding, color.NRGBA{0, 0, 0, uint8(160 * alpha)})
+
+		toastScratch.Clear()
+		ebitenutil.DebugPrint(toastScratch, t.text)
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(float64(x), float64(y))
+		op.ColorScale.ScaleAlpha(float32(alpha))
+		screen.DrawImage(toastScratch, op)
+		line++
+	}
+}
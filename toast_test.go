@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestToastsKeepTheNewest(t *testing.T) {
+	g, _ := newTestGame(t)
+	g.toasts = nil
+	for i := 1; i <= toastMax+2; i++ {
+		g.pushToast(fmt.Sprint("toast ", i))
+	}
+	var got []string
+	for _, toast := range g.toasts {
+		got = append(got, toast.text)
+	}
+	want := []string{"toast 3", "toast 4", "toast 5", "toast 6"}
+	if strings.Join(got, ", ") != strings.Join(want, ", ") {
+		t.Errorf("showing %q, want %q", got, want)
+	}
+}
+
+func TestLongToastsAreCut(t *testing.T) {
+	g, _ := newTestGame(t)
+	g.toasts = nil
+	g.pushToast(strings.Repeat("ñ", toastMaxLen))
+	g.pushToast(strings.Repeat("ñ", toastMaxLen+1))
+	if got := []rune(g.toasts[0].text); len(got) != toastMaxLen || got[len(got)-1] != 'ñ' {
+		t.Errorf("a toast at the limit became %q", g.toasts[0].text)
+	}
+	if got := []rune(g.toasts[1].text); len(got) != toastMaxLen || !strings.HasSuffix(string(got), "...") {
+		t.Errorf("a toast over the limit became %q, want it cut to %d runes ending in ...", g.toasts[1].text, toastMaxLen)
+	}
+}
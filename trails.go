@@ -0,0 +1,83 @@
+package main
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"example/hello/core"
+)
+
+const (
+	bulletTrailLength = 4   // Afterimages kept per bullet
+	bulletTrailAlpha  = 0.5 // Opacity of the newest afterimage; older ones fade from it
+)
+
+// bulletTrail is a ring buffer of where one bullet has been.
+type bulletTrail struct {
+	points [bulletTrailLength][2]float64
+	n      int     // Points filled so far
+	next   int     // Where the next point goes
+	id     uint64  // The bullet's ID
+	x, y   float64 // The bullet's position when last seen
+}
+
+func (t *bulletTrail) push(x, y float64) {
+	t.points[t.next] = [2]float64{x, y}
+	t.next = (t.next + 1) % bulletTrailLength
+	t.n = min(t.n+1, bulletTrailLength)
+}
+
+// bulletTrails follows the live bullets from tick to tick. It is purely
+// cosmetic and never touches the world.
+type bulletTrails struct {
+	trails, spare []bulletTrail
+}
+
+// update carries each bullet's trail through a Step, matching trails to
+// bullets by ID. Both are kept in ID order, so one pass pairs them up.
+// Bullets that are gone lose their trails; bullets without an ID, as a
+// spectator's are, get none.
+func (bt *bulletTrails) update(bullets []core.Bullet) {
+	next := bt.spare[:0]
+	j := 0
+	for _, b := range bullets {
+		if !b.Active || b.ID == 0 {
+			continue
+		}
+		for j < len(bt.trails) && bt.trails[j].id < b.ID {
+			j++
+		}
+		t := bulletTrail{id: b.ID}
+		if j < len(bt.trails) && bt.trails[j].id == b.ID {
+			t = bt.trails[j]
+			t.push(b.PrevX, b.PrevY)
+		}
+		t.x, t.y = b.X, b.Y
+		next = append(next, t)
+	}
+	bt.spare, bt.trails = bt.trails, next
+}
+
+func (bt *bulletTrails) clear() {
+	bt.trails = bt.trails[:0]
+}
+
+// draw paints each trail's afterimages, oldest and faintest first, with
+// positions passed through snap, in one batch.
+func (bt *bulletTrails) draw(screen *ebiten.Image, b *shapeBatch, worldWidth, ox float64, snap func(float64) float64) {
+	b.begin(screen, false)
+	defer b.flush()
+	for i := range bt.trails {
+		t := &bt.trails[i]
+		for age := t.n; age >= 1; age-- {
+			p := t.points[(t.next-age+bulletTrailLength)%bulletTrailLength]
+			if math.Abs(p[0]-t.x) > worldWidth/2 {
+				continue // Wrapped across the seam since
+			}
+			a := bulletTrailAlpha * float64(bulletTrailLength+1-age) / float64(bulletTrailLength+1)
+			b.addRect(snap(p[0])+ox, snap(p[1]), core.BulletWidth, core.BulletHeight, color.NRGBA{255, 255, 0, uint8(a * 255)})
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+const (
+	practiceAsteroidSpeed = 60  // Pixels per second
+	tutorialExplainTime   = 5.0 // Seconds the scoring explanation stays up
+)
+
+type tutorialStep int
+
+const (
+	tutorialOff tutorialStep = iota
+	tutorialMove
+	tutorialShoot
+	tutorialExplain
+)
+
+// tutorial walks a profile's first game through the controls. While it
+// runs, normal spawning is held back and the player can't be hit.
+type tutorial struct {
+	step      tutorialStep
+	movedX    bool
+	movedY    bool
+	stepStart int // gameTime the current step began
+	kills     int // g.world.Destroyed when the shooting step began
+}
+
+func (g *Game) startTutorial() {
+	g.tutorial = tutorial{step: tutorialMove}
+	g.world.HoldSpawns = true
+	g.world.Invulnerable = true
+	g.world.GraceUntil = 0 // The tutorial sets its own pace
+	g.trace = nil          // The tutorial steers the world outside the inputs
+}
+
+// updateTutorial advances the tutorial after each tick of the world.
+func (g *Game) updateTutorial() {
+	t := &g.tutorial
+	if t.step == tutorialOff {
+		return
+	}
+	if g.input.justPressed(inputMenu) {
+		g.finishTutorial()
+		return
+	}
+
+	switch t.step {
+	case tutorialMove:
+		t.movedX = t.movedX || g.world.Player.X != g.world.Player.PrevX
+		t.movedY = t.movedY || g.world.Player.Y != g.world.Player.PrevY
+		if t.movedX && t.movedY {
+			g.setTutorialStep(tutorialShoot)
+			t.kills = g.world.Destroyed
+		}
+	case tutorialShoot:
+		if g.world.Destroyed > t.kills {
+			g.setTutorialStep(tutorialExplain)
+			break
+		}
+		// Keep one slow practice asteroid falling toward the player
+		if len(g.world.Asteroids) == 0 {
+			width := 40.0
+			x := g.world.Player.X + g.world.Player.Width/2 - width/2
+			x = max(0, min(x, g.world.Config.Width-width))
+			g.world.AddAsteroid(x, width, practiceAsteroidSpeed)
+		}
+	case tutorialExplain:
+		if g.world.Time-t.stepStart >= g.world.Ticks(tutorialExplainTime) {
+			g.finishTutorial()
+		}
+	}
+}
+
+func (g *Game) setTutorialStep(step tutorialStep) {
+	g.tutorial.step = step
+	g.tutorial.stepStart = g.world.Time
+}
+
+// finishTutorial ends the tutorial for good, whether completed or skipped,
+// and lets normal spawning ramp in.
+func (g *Game) finishTutorial() {
+	g.tutorial.step = tutorialOff
+	g.world.ResumeSpawning()
+	g.world.Invulnerable = false
+	if g.profile != nil {
+		g.profile.TutorialDone = true
+		g.saveErr = g.profile.save()
+	}
+}
+
+func (g *Game) drawTutorial(screen *ebiten.Image) {
+	var lines []string
+	switch g.tutorial.step {
+	case tutorialOff:
+		return
+	case tutorialMove:
+		lines = []string{tr("tutorial.move.1"), tr("tutorial.move.2")}
+	case tutorialShoot:
+		lines = []string{tr("tutorial.shoot.1"), tr("tutorial.shoot.2")}
+	case tutorialExplain:
+		lines = []string{
+			tr("tutorial.explain.1"),
+			tr("tutorial.explain.2"),
+			tr("tutorial.explain.3"),
+		}
+	}
+	y := screenHeight/3 - len(lines)*8
+	for _, line := range lines {
+		drawCentered(screen, line, y)
+		y += 16
+	}
+	drawCentered(screen, tr("tutorial.skip"), y+16)
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"example/hello/core"
+)
+
+const aimIndicatorLength = 24 // Pixels the aim line reaches out from the ship's center
+
+// twinStickInput gathers input for a twin-stick run. WASD or the left
+// stick moves. The arrow keys, the right stick or the mouse with its
+// button held aim, and the gun fires wherever it is aimed. Fire, or the
+// auto-fire assist, shoots the way the ship last aimed.
+func (g *Game) twinStickInput() core.FrameInput {
+	var in core.FrameInput
+	in.MoveX, in.MoveY = g.input.moveX, g.input.moveY
+
+	dx, dy, aiming := g.input.aimX, g.input.aimY, g.input.aiming
+	if !aiming && g.input.pointing {
+		dx, dy, aiming = g.mouseAim(g.input.pointer)
+	}
+	if !aiming && (g.presses.take(actionFire) || g.input.held[inputFire] || g.world.Config.AutoFire) {
+		dx, dy, aiming = g.aimX, g.aimY, true
+	}
+	if aiming {
+		g.aimX, g.aimY = dx, dy
+	}
+	in.AimX, in.AimY, in.Aiming = dx, dy, aiming
+	return in
+}
+
+// mouseAim is the unit direction from the ship to the mouse cursor at
+// screen position c. ok is false with the cursor right on the ship.
+func (g *Game) mouseAim(c image.Point) (dx, dy float64, ok bool) {
+	p := &g.world.Player
+	dx = float64(c.X) + g.camera.x - (p.X + p.Width/2)
+	dy = float64(c.Y) - (p.Y + p.Height/2)
+	mag := math.Hypot(dx, dy)
+	if mag < 1 {
+		return 0, 0, false
+	}
+	return dx / mag, dy / mag, true
+}
+
+// drawAim draws a short line from the ship the way it last aimed.
+func (g *Game) drawAim(screen *ebiten.Image, ox, t float64) {
+	w := g.world
+	if w.Config.Mode != core.ModeTwinStick || w.GameOver {
+		return
+	}
+	p := &w.Player
+	cx := g.lerpPos(p.PrevX, p.X, t) + ox + p.Width/2
+	cy := g.lerpPos(p.PrevY, p.Y, t) + p.Height/2
+	ex, ey := cx+g.aimX*aimIndicatorLength, cy+g.aimY*aimIndicatorLength
+	vector.StrokeLine(screen, float32(cx), float32(cy), float32(ex), float32(ey), 2, color.RGBA{120, 220, 255, 200}, true)
+}
@@ -0,0 +1,50 @@
+package main
+
+import "slices"
+
+// shipUnlock is what a player has to do before flying a ship. Ships
+// without one are available from the start.
+type shipUnlock struct {
+	met  func(Stats) bool
+	hint func() string
+}
+
+var shipUnlocks = map[string]shipUnlock{
+	"arrow": {
+		met:  func(s Stats) bool { return s.BestScore >= 200 },
+		hint: func() string { return trf("unlock.best_score", 200) },
+	},
+	"bastion": {
+		met:  func(s Stats) bool { return s.AsteroidsDestroyed >= 100 },
+		hint: func() string { return trf("unlock.destroyed", 100) },
+	},
+}
+
+// shipUnlocked reports whether the profile may fly the named ship. Before a
+// profile is picked, only the starting ships are.
+func (p *Profile) shipUnlocked(name string) bool {
+	_, locked := shipUnlocks[name]
+	return !locked || p != nil && slices.Contains(p.Unlocked, name)
+}
+
+// unlockShips records every ship whose condition the profile's stats now
+// meet, and returns the ones that weren't unlocked before. Once unlocked,
+// a ship stays unlocked.
+func (p *Profile) unlockShips() []string {
+	var added []string
+	for name, u := range shipUnlocks {
+		if !p.shipUnlocked(name) && u.met(p.Stats) {
+			added = append(added, name)
+		}
+	}
+	slices.Sort(added)
+	p.Unlocked = append(p.Unlocked, added...)
+	return added
+}
+
+// announceUnlocks toasts the ships a run just unlocked.
+func (g *Game) announceUnlocks(names []string) {
+	for _, name := range names {
+		g.pushToast(trf("unlock.new", tr("ship."+name)))
+	}
+}
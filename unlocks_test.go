@@ -0,0 +1,67 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestUnlockShips(t *testing.T) {
+	tests := []struct {
+		name     string
+		unlocked []string // Before
+		stats    Stats
+		want     []string // Newly unlocked
+	}{
+		{name: "new profile"},
+		{name: "just short", stats: Stats{BestScore: 199, AsteroidsDestroyed: 99}},
+		{name: "score", stats: Stats{BestScore: 200}, want: []string{"arrow"}},
+		{name: "kills", stats: Stats{AsteroidsDestroyed: 100}, want: []string{"bastion"}},
+		{name: "both at once", stats: Stats{BestScore: 500, AsteroidsDestroyed: 500}, want: []string{"arrow", "bastion"}},
+		{name: "already unlocked", unlocked: []string{"arrow"}, stats: Stats{BestScore: 500}},
+		{
+			// Stats that drop below a condition don't lock a ship again
+			name: "kept", unlocked: []string{"arrow", "bastion"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Profile{Stats: tt.stats, Unlocked: tt.unlocked}
+			if got := p.unlockShips(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("unlocked %v, want %v", got, tt.want)
+			}
+			for _, ship := range []string{"falcon", "arrow", "bastion"} {
+				want := ship == "falcon"
+				for _, u := range append(tt.unlocked, tt.want...) {
+					want = want || u == ship
+				}
+				if got := p.shipUnlocked(ship); got != want {
+					t.Errorf("%s unlocked: %v, want %v", ship, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestNoProfileFliesStartingShips(t *testing.T) {
+	var p *Profile
+	if !p.shipUnlocked("falcon") || p.shipUnlocked("arrow") {
+		t.Error("without a profile only the starting ships should be unlocked")
+	}
+}
+
+func TestUnlocksPersist(t *testing.T) {
+	p := &Profile{Name: "ann", Stats: Stats{BestScore: 300}}
+	p.unlockShips()
+	path := filepath.Join(t.TempDir(), "ann.json")
+	if err := saveFile(path, profileSchema, p); err != nil {
+		t.Fatal(err)
+	}
+	var loaded Profile
+	if err := loadFile(path, profileSchema, &loaded); err != nil {
+		t.Fatal(err)
+	}
+	if !loaded.shipUnlocked("arrow") {
+		t.Error("the unlock was lost on reloading the profile")
+	}
+}
@@ -0,0 +1,481 @@
+package main
+
+import (
+	"image/color"
+	"math/bits"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+
+	"example/hello/core"
+)
+
+const (
+	versusInputDelay   = 3   // Ticks between reading local input and simulating it
+	versusHashInterval = 60  // Ticks between desync checks
+	versusTargetScore  = 300 // First ship to reach this score wins
+	versusViewScale    = 0.5 // Each player's field is drawn at this size
+	versusStallNotice  = 15  // Updates without the opponent's input before saying so
+	cpuInputDelay      = 1   // Local input delay against the computer; there is no network to hide
+)
+
+// versusMatch is a LAN game between two instances. Both fields start from
+// the host's seed as shared fields, so they get the same asteroids
+// wherever each ship flies, and every instance simulates both ships, in
+// lockstep: tick n only runs once both players' inputs for it have
+// arrived.
+//
+// If the connection drops, the match holds while the joiner reconnects
+// to the host, for up to lanReconnectWindow. Each side then sends again
+// the inputs the other hasn't acknowledged, and play picks up where it
+// stopped.
+//
+// Against the computer there is no connection; the bot flies the second
+// ship from a field with the same seed.
+type versusMatch struct {
+	addr       string
+	connecting <-chan lanResult // Hosting: players connecting, for the whole match. Joining: the current attempt
+	conn       *lanConn
+	greeted    bool          // The other side's hello has arrived on conn
+	lostAt     time.Time     // When the connection dropped; zero while connected
+	stop       chan struct{} // Closed when the match ends, to stop connecting in the background
+	host       bool
+	seed       int64
+	cpu        *core.BotSkill // Set when the opponent is the computer
+	inputDelay int            // Ticks between reading local input and simulating it
+	tuning     core.Tuning
+
+	worlds     [2]*core.World // [0] is the host's ship, [1] the joiner's
+	local      int            // Index of our ship
+	inputs     [2]map[int]core.FrameInput
+	tick       int // Last tick simulated
+	nextInput  int // Next tick to read local input for
+	stalledFor int // Updates spent waiting for the opponent's input
+
+	sent     inputLog // Our inputs the opponent hasn't acknowledged
+	received int      // Last tick up to which we have all the opponent's inputs
+
+	localHashes  map[int]uint64
+	remoteHashes map[int]uint64
+
+	over    bool
+	aborted string // Why the match ended early, if it did
+	winner  int    // Index of the winning ship; -1 for a draw
+
+	views [2]*ebiten.Image
+}
+
+// startVersus hosts a match on addr, or joins the one at addr.
+func (g *Game) startVersus(host bool, addr string) {
+	v := &versusMatch{addr: addr, host: host, inputDelay: versusInputDelay, tuning: g.tuning, stop: make(chan struct{})}
+	if host {
+		v.seed = time.Now().UnixNano()
+		v.connecting = hostLAN(addr, v.stop)
+	} else {
+		v.connecting = joinLAN(addr, time.Time{}, v.stop)
+		v.local = 1
+	}
+	g.versus = v
+	g.screen = screenVersus
+}
+
+// startVersusCPU starts a match against the computer at the given skill.
+func (g *Game) startVersusCPU(skill core.BotSkill) {
+	v := &versusMatch{cpu: &skill, inputDelay: cpuInputDelay, tuning: g.tuning}
+	v.begin(time.Now().UnixNano())
+	g.versus = v
+	g.screen = screenVersus
+}
+
+func (g *Game) leaveVersus() {
+	g.versus.disconnect()
+	g.versus = nil
+	if g.profile != nil {
+		g.screen = screenTitle
+	} else {
+		g.openProfiles()
+	}
+}
+
+// begin sets up both fields once the seed is agreed.
+func (v *versusMatch) begin(seed int64) {
+	cfg := core.Config{
+		TPS:          ebiten.TPS(),
+		Width:        landscapeWidth,
+		Height:       landscapeHeight,
+		MaxAsteroids: core.DefaultMaxAsteroids,
+		MaxBullets:   core.DefaultMaxBullets,
+		SharedField:  true,
+		Tuning:       v.tuning,
+	}
+	for i := range v.worlds {
+		v.worlds[i] = core.NewWorld(cfg, seed)
+		v.inputs[i] = make(map[int]core.FrameInput)
+		// Nobody has input for the first ticks; they pass idle
+		for t := 1; t <= v.inputDelay; t++ {
+			v.inputs[i][t] = core.FrameInput{}
+		}
+	}
+	if v.cpu != nil {
+		for t := 1; t <= v.worlds[1].Ticks(v.cpu.Reaction); t++ {
+			v.inputs[1][t] = core.FrameInput{}
+		}
+	}
+	v.nextInput = v.inputDelay + 1
+	v.sent = inputLog{acked: v.inputDelay}
+	v.received = v.inputDelay
+	v.localHashes = make(map[int]uint64)
+	v.remoteHashes = make(map[int]uint64)
+}
+
+// abort ends the match without a result.
+func (v *versusMatch) abort(reason string) {
+	v.over = true
+	v.aborted = reason
+	v.disconnect()
+}
+
+// disconnect closes the connection and stops any attempt at a new one.
+func (v *versusMatch) disconnect() {
+	if v.conn != nil {
+		v.conn.close()
+	}
+	if v.stop != nil {
+		close(v.stop)
+		v.stop = nil
+	}
+}
+
+func (g *Game) updateVersus() {
+	v := g.versus
+	if g.input.justPressed(inputMenu) {
+		g.leaveVersus()
+		return
+	}
+	if v.over {
+		return
+	}
+	g.presses.record(g.actionJustPressed)
+	v.update(g.frameInput)
+}
+
+// update runs the match for one tick, reading our input from local.
+func (v *versusMatch) update(local func() core.FrameInput) {
+	if v.cpu == nil {
+		v.updateConnection()
+		// Hold the match until both sides are connected and greeted
+		if v.over || !v.greeted {
+			return
+		}
+	}
+
+	// Read local input a few ticks ahead, so it reaches the opponent
+	// before either of us needs it
+	if v.nextInput <= v.tick+v.inputDelay {
+		in := local()
+		v.inputs[v.local][v.nextInput] = in
+		if v.cpu == nil {
+			v.sent.add(in)
+			v.conn.send(lanMessage{Type: "input", Tick: v.nextInput, Input: &in, Ack: v.received})
+		}
+		v.nextInput++
+	}
+	// The bot acts on what it sees now once its reaction time has passed
+	if v.cpu != nil {
+		bot := v.worlds[1]
+		v.inputs[1][v.tick+1+bot.Ticks(v.cpu.Reaction)] = core.BotInput(bot, *v.cpu)
+	}
+
+	next := v.tick + 1
+	host, okHost := v.inputs[0][next]
+	joiner, okJoiner := v.inputs[1][next]
+	if !okHost || !okJoiner {
+		v.stalledFor++
+		return
+	}
+	v.stalledFor = 0
+	delete(v.inputs[0], next)
+	delete(v.inputs[1], next)
+	v.tick = next
+	v.worlds[0].Step(host)
+	v.worlds[1].Step(joiner)
+
+	if v.conn != nil && v.tick%versusHashInterval == 0 {
+		h := v.worlds[0].Hash() ^ bits.RotateLeft64(v.worlds[1].Hash(), 1)
+		v.localHashes[v.tick] = h
+		v.conn.send(lanMessage{Type: "hash", Tick: v.tick, Hash: h, Ack: v.received})
+		v.checkHash(v.tick)
+	}
+	v.checkResult()
+}
+
+// updateConnection takes a new connection if one has arrived, handles
+// the messages waiting on the current one and notices when it drops.
+func (v *versusMatch) updateConnection() {
+	select {
+	case r := <-v.connecting:
+		switch {
+		case r.err == nil:
+			v.attach(r.conn)
+		case v.worlds[0] == nil:
+			v.abort(trf("versus.failed", r.err))
+			return
+		}
+		// Otherwise a rejoin gave up; the reconnect window is about to close
+	default:
+	}
+
+	for drained := false; v.conn != nil && !drained && !v.over; {
+		select {
+		case m, ok := <-v.conn.in:
+			if ok {
+				v.handle(m)
+			} else {
+				v.drop()
+			}
+		default:
+			drained = true
+		}
+	}
+	if !v.over && !v.lostAt.IsZero() && time.Since(v.lostAt) > lanReconnectWindow {
+		v.abort(tr("versus.forfeit"))
+	}
+}
+
+// attach starts using a new connection. A player who reconnects before
+// we noticed the old connection drop replaces it, and the match holds
+// as if it had dropped.
+func (v *versusMatch) attach(c *lanConn) {
+	if v.conn != nil {
+		v.conn.close()
+		if v.worlds[0] != nil && v.lostAt.IsZero() {
+			v.lostAt = time.Now()
+		}
+	}
+	v.conn, v.greeted = c, false
+	c.send(lanMessage{
+		Type:      "hello",
+		Protocol:  lanProtocol,
+		Version:   gameVersion(),
+		Seed:      v.seed,
+		Tuning:    core.TuningVersion,
+		TuningSum: v.tuning.Checksum(),
+		TPS:       ebiten.TPS(),
+		Ack:       v.received,
+	})
+}
+
+// drop lets go of a connection that closed. A match under way holds for
+// the players to reconnect, with the joiner redialing the host.
+func (v *versusMatch) drop() {
+	v.conn.close()
+	v.conn, v.greeted = nil, false
+	switch {
+	case v.worlds[0] == nil && v.host:
+		// Nothing to resume; wait for someone else
+	case v.worlds[0] == nil:
+		v.abort(tr("versus.disconnected"))
+	default:
+		if v.lostAt.IsZero() {
+			v.lostAt = time.Now()
+		}
+		if !v.host {
+			v.connecting = joinLAN(v.addr, v.lostAt.Add(lanReconnectWindow), v.stop)
+		}
+	}
+}
+
+// mismatch is why the other side's hello rules out playing, or empty if
+// it doesn't.
+func (v *versusMatch) mismatch(m lanMessage) string {
+	switch {
+	case m.Protocol != lanProtocol:
+		return trf("versus.mismatch_protocol", m.Protocol, lanProtocol)
+	case m.Version != gameVersion():
+		return trf("versus.mismatch_version", m.Version, gameVersion())
+	case m.Tuning != core.TuningVersion:
+		return trf("versus.mismatch_tuning", m.Tuning, core.TuningVersion)
+	case m.TuningSum != v.tuning.Checksum():
+		return tr("versus.mismatch_tuning_sum")
+	case m.TPS != ebiten.TPS():
+		return trf("versus.mismatch_tps", m.TPS, ebiten.TPS())
+	}
+	return ""
+}
+
+func (v *versusMatch) handle(m lanMessage) {
+	if !v.greeted && m.Type != "hello" {
+		// Every connection starts with a hello; only a game from before
+		// the handshake would skip it
+		v.abort(tr("versus.mismatch"))
+		return
+	}
+	v.sent.ack(m.Ack)
+	remote := 1 - v.local
+	switch m.Type {
+	case "hello":
+		v.greet(m)
+	case "input":
+		// Inputs come in order, and again after a reconnect from the
+		// last one we acknowledged
+		if m.Input != nil && m.Tick == v.received+1 {
+			v.inputs[remote][m.Tick] = *m.Input
+			v.received++
+		}
+	case "hash":
+		if v.remoteHashes == nil {
+			return
+		}
+		v.remoteHashes[m.Tick] = m.Hash
+		v.checkHash(m.Tick)
+	}
+}
+
+// greet checks the other side's hello and starts or resumes the match.
+func (v *versusMatch) greet(m lanMessage) {
+	if reason := v.mismatch(m); reason != "" {
+		v.abort(reason)
+		return
+	}
+	switch {
+	case v.worlds[0] == nil:
+		if !v.host {
+			v.seed = m.Seed
+		}
+		v.begin(v.seed)
+	case m.Seed != v.seed && v.host:
+		// Someone other than our opponent; keep waiting for them
+		v.conn.close()
+		v.conn = nil
+		return
+	case m.Seed != v.seed:
+		v.abort(tr("versus.disconnected"))
+		return
+	default:
+		// Send again whatever the other side missed. Checkpoints sent
+		// around the drop may never be answered, so start those afresh
+		for i, in := range v.sent.pending {
+			v.conn.send(lanMessage{Type: "input", Tick: v.sent.acked + 1 + i, Input: &in, Ack: v.received})
+		}
+		clear(v.localHashes)
+		clear(v.remoteHashes)
+	}
+	v.greeted = true
+	v.lostAt = time.Time{}
+}
+
+// checkHash compares both sides' state at a checkpoint once both are known.
+func (v *versusMatch) checkHash(tick int) {
+	local, ok := v.localHashes[tick]
+	remote, ok2 := v.remoteHashes[tick]
+	if !ok || !ok2 {
+		return
+	}
+	delete(v.localHashes, tick)
+	delete(v.remoteHashes, tick)
+	if local != remote {
+		v.abort(trf("versus.desync", tick))
+	}
+}
+
+// checkResult ends the match when a ship reaches the target score or is
+// the last one flying.
+func (v *versusMatch) checkResult() {
+	a, b := v.worlds[0], v.worlds[1]
+	reached := a.Score >= versusTargetScore || b.Score >= versusTargetScore
+	switch {
+	case reached || a.GameOver && b.GameOver:
+		switch {
+		case a.Score > b.Score:
+			v.winner = 0
+		case b.Score > a.Score:
+			v.winner = 1
+		default:
+			v.winner = -1
+		}
+	case a.GameOver:
+		v.winner = 1
+	case b.GameOver:
+		v.winner = 0
+	default:
+		return
+	}
+	v.over = true
+}
+
+func (g *Game) drawVersus(screen *ebiten.Image) {
+	v := g.versus
+	if v.worlds[0] == nil {
+		switch {
+		case v.aborted != "":
+			drawCentered(screen, v.aborted, screenHeight/2)
+		case v.host:
+			drawCentered(screen, trf("versus.waiting", v.addr), screenHeight/2)
+		default:
+			drawCentered(screen, trf("versus.connecting", v.addr), screenHeight/2)
+		}
+		drawCentered(screen, tr("versus.leave"), screenHeight/2+20)
+		return
+	}
+
+	drawCentered(screen, trf("versus.target", versusTargetScore), 40)
+	// Both fields side by side, shrunk further if the screen is too narrow
+	scale := min(versusViewScale, float64(screenWidth)/2/landscapeWidth)
+	viewW, viewH := landscapeWidth*scale, landscapeHeight*scale
+	mid := float64(screenWidth) / 2
+	top := (float64(screenHeight) - viewH) / 2
+	for i, w := range v.worlds {
+		if v.views[i] == nil {
+			v.views[i] = ebiten.NewImage(landscapeWidth, landscapeHeight)
+		}
+		view := v.views[i]
+		view.Fill(hexColor(backgroundStart))
+		g.drawWorld(view, w, 0, 1)
+
+		// Our field on the left, the opponent's on the right
+		x, label := mid-viewW, tr("versus.you")
+		if i != v.local {
+			x, label = mid, tr("versus.opponent")
+			if v.cpu != nil {
+				label = trf("versus.cpu", tr("skill."+v.cpu.Name))
+			}
+		}
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Scale(scale, scale)
+		op.GeoM.Translate(x, top)
+		screen.DrawImage(view, op)
+		ebitenutil.DebugPrintAt(screen, trf("versus.score", label, w.Score), int(x)+10, int(top)-20)
+	}
+	ebitenutil.DrawRect(screen, mid-1, top, 2, viewH, color.RGBA{200, 200, 200, 255})
+
+	status := ""
+	switch {
+	case v.aborted != "":
+		status = v.aborted
+	case v.over && v.winner == -1:
+		status = tr("versus.draw")
+	case v.over && v.winner == v.local:
+		status = tr("versus.win")
+	case v.over:
+		status = tr("versus.lose")
+	case !v.lostAt.IsZero():
+		left := int((lanReconnectWindow - time.Since(v.lostAt) + time.Second - 1) / time.Second)
+		if v.host {
+			status = trf("versus.reconnect_wait", max(left, 0))
+		} else {
+			status = trf("versus.reconnecting", max(left, 0))
+		}
+	case v.stalledFor > versusStallNotice:
+		status = tr("versus.stalled")
+	case v.worlds[v.local].InGrace():
+		status = getReady(v.worlds[v.local])
+	}
+	bottom := int(top + viewH)
+	if status != "" {
+		drawCentered(screen, status, bottom+20)
+	}
+	if v.over {
+		drawCentered(screen, tr("versus.leave"), bottom+40)
+	}
+}